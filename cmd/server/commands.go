@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"codebase-view-mcp/internal/coverage"
+	"codebase-view-mcp/internal/discovery"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gitdiff"
+	"codebase-view-mcp/internal/impact"
+	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/mutation"
+	"codebase-view-mcp/internal/skeleton"
+	"codebase-view-mcp/internal/testrun"
+)
+
+// runCommand executes a CLI subcommand against the metadata store
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import-coverage":
+		return runImportCoverage(args)
+	case "run-coverage":
+		return runCoverageRunner(args)
+	case "import-test-results":
+		return runImportTestResults(args)
+	case "changes":
+		return runChanges(args)
+	case "impact":
+		return runImpact(args)
+	case "discover":
+		return runDiscover(args)
+	case "mutate":
+		return runMutate(args)
+	case "suggest":
+		return runSuggest(args)
+	default:
+		return fmt.Errorf("unknown command")
+	}
+}
+
+// commonFlags registers the -dir and -metadata flags shared by all subcommands
+func commonFlags(fs *flag.FlagSet) (*string, *string) {
+	baseDir := fs.String("dir", ".", "Base directory of the codebase")
+	metadataPath := fs.String("metadata", "metadata.json", "Path to metadata JSON file")
+	return baseDir, metadataPath
+}
+
+// runImportCoverage imports a go test -coverprofile file into the metadata store
+func runImportCoverage(args []string) error {
+	fs := flag.NewFlagSet("import-coverage", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	profilePath := fs.String("profile", "cover.out", "Path to the cover profile")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	file, err := os.Open(*profilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open profile: %w", err)
+	}
+	defer file.Close()
+
+	result, err := coverage.Import(metadata.NewStore(*metadataPath), absBaseDir, file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d blocks for %d files (mode: %s)\n", result.Blocks, len(result.Files), result.Mode)
+	for _, skipped := range result.Skipped {
+		fmt.Printf("Skipped %s (outside %s)\n", skipped, absBaseDir)
+	}
+
+	return nil
+}
+
+// runCoverageRunner measures per-test coverage by running each test of a package in isolation
+func runCoverageRunner(args []string) error {
+	fs := flag.NewFlagSet("run-coverage", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	pkgDir := fs.String("pkg", ".", "Package directory relative to -dir")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "Number of tests to run concurrently")
+	coverPkg := fs.String("coverpkg", "", "Packages to measure (go test -coverpkg); defaults to the tested package")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	runner := coverage.NewRunner(metadata.NewStore(*metadataPath), absBaseDir)
+	runner.Parallel = *parallel
+	runner.CoverPkg = *coverPkg
+
+	runs, err := runner.RunPackage(context.Background(), filepath.Clean(*pkgDir))
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		switch {
+		case run.Err != nil:
+			fmt.Printf("FAIL   %s: %v\n", run.TestName, run.Err)
+		case run.Cached:
+			fmt.Printf("cached %s\n", run.TestName)
+		default:
+			fmt.Printf("ok     %s (%d files)\n", run.TestName, len(run.Files))
+		}
+	}
+
+	return nil
+}
+
+// runImportTestResults imports go test -json output into the metadata store
+func runImportTestResults(args []string) error {
+	fs := flag.NewFlagSet("import-test-results", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	inputPath := fs.String("input", "-", "Path to go test -json output (- for stdin)")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	var input io.Reader = os.Stdin
+	if *inputPath != "-" {
+		file, err := os.Open(*inputPath)
+		if err != nil {
+			return fmt.Errorf("failed to open input: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := testrun.Import(metadata.NewStore(*metadataPath), absBaseDir, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported results: %d passed, %d failed, %d skipped\n", result.Passed, result.Failed, result.Skipped)
+	for _, unmatched := range result.Unmatched {
+		fmt.Printf("Unmatched %s\n", unmatched)
+	}
+
+	return nil
+}
+
+// runChanges reports changed lines between two git revisions that no test covers
+func runChanges(args []string) error {
+	fs := flag.NewFlagSet("changes", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	base := fs.String("base", "", "Base git revision (required)")
+	head := fs.String("head", "", "Head git revision (default: working tree)")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	report, err := impact.Changes(context.Background(), metadata.NewStore(*metadataPath), files.NewService(absBaseDir), *base, *head)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range report.Files {
+		for _, change := range file.Changes {
+			fmt.Printf("%s:%d-%d  untested: %d  tests: %d\n", file.Path, change.Lines.Start, change.Lines.End, len(change.UntestedLines), len(change.Tests))
+			for _, test := range change.Tests {
+				fmt.Printf("    %s (%s)\n", test.TestName, test.TestFile)
+			}
+			if len(change.UntestedLines) > 0 {
+				fmt.Printf("    untested lines: %v\n", change.UntestedLines)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runImpact prints go test commands for the tests affected by a change
+func runImpact(args []string) error {
+	fs := flag.NewFlagSet("impact", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	changedFiles := fs.String("files", "", "Comma-separated changed files relative to -dir")
+	base := fs.String("base", "", "Take changed lines from git diff against this revision")
+	head := fs.String("head", "", "Head git revision used with -base (default: working tree)")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	var req files.ImpactRequest
+	for _, path := range strings.Split(*changedFiles, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			req.Files = append(req.Files, filepath.ToSlash(filepath.Clean(path)))
+		}
+	}
+
+	if *base != "" {
+		changed, err := gitdiff.ChangedLines(context.Background(), absBaseDir, *base, *head)
+		if err != nil {
+			return err
+		}
+		// Stored coverage describes the working tree
+		if *head != "" {
+			if changed, err = gitdiff.MapToWorkingTree(context.Background(), absBaseDir, *head, changed); err != nil {
+				return err
+			}
+		}
+		for path, lines := range changed {
+			req.Changes = append(req.Changes, files.FileChange{Path: path, Lines: lines})
+		}
+	}
+
+	if len(req.Files) == 0 && len(req.Changes) == 0 {
+		return fmt.Errorf("either -files or -base is required")
+	}
+
+	result, err := impact.Tests(metadata.NewStore(*metadataPath), files.NewService(absBaseDir), req)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range result.Packages {
+		fmt.Println(pkg.Command)
+	}
+
+	return nil
+}
+
+// runDiscover lists Python and JavaScript tests and the references proposed for them
+func runDiscover(args []string) error {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	relPath := fs.String("path", "", "Directory to scan, relative to -dir")
+	apply := fs.Bool("apply", false, "Store proposed test references that do not exist yet")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	result, err := discovery.Scan(files.NewService(absBaseDir), *relPath)
+	if err != nil {
+		return err
+	}
+
+	for _, proposal := range result.Proposals {
+		fmt.Println(proposal.SourceFile)
+		for _, test := range proposal.Tests {
+			fmt.Printf("    %s -> %s (%s:%d-%d)\n", test.FunctionName, test.TestName, test.TestFile, test.LineRange.Start, test.LineRange.End)
+		}
+	}
+
+	if *apply {
+		applied, err := discovery.Apply(metadata.NewStore(*metadataPath), result)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Stored %d new test references\n", applied)
+	}
+
+	return nil
+}
+
+// runMutate mutation-tests a function against the tests mapped to it
+func runMutate(args []string) error {
+	fs := flag.NewFlagSet("mutate", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	file := fs.String("file", "", "Go source file relative to -dir (required)")
+	function := fs.String("function", "", "Function to mutate, Func or Receiver.Method (required)")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "Number of module copies tested concurrently")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Limit for one go test run; slower mutants count as killed")
+	fs.Parse(args)
+
+	if *file == "" || *function == "" {
+		return fmt.Errorf("-file and -function are required")
+	}
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	runner := mutation.NewRunner(metadata.NewStore(*metadataPath), absBaseDir)
+	runner.Parallel = *parallel
+	runner.Timeout = *timeout
+
+	run, err := runner.RunFunction(context.Background(), filepath.ToSlash(filepath.Clean(*file)), *function)
+	if err != nil {
+		return err
+	}
+
+	for _, mutant := range run.Mutants {
+		fmt.Printf("%-8s line %d  %s: %q -> %q", mutant.Status, mutant.Line, mutant.Operator, mutant.Original, mutant.Mutated)
+		if len(mutant.KilledBy) > 0 {
+			fmt.Printf("  (%s)", strings.Join(mutant.KilledBy, ", "))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%s: %d killed, %d survived, %d invalid, score %.0f%%\n", run.Function, run.Killed, run.Survived, run.Invalid, run.Score*100)
+
+	return nil
+}
+
+// runSuggest lists Go functions without test references and the test
+// skeletons generated for them
+func runSuggest(args []string) error {
+	fs := flag.NewFlagSet("suggest", flag.ExitOnError)
+	baseDir, metadataPath := commonFlags(fs)
+	relPath := fs.String("path", "", "Directory to scan, relative to -dir")
+	apply := fs.Bool("apply", false, "Store the generated suggestions")
+	fs.Parse(args)
+
+	absBaseDir, err := filepath.Abs(*baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve base directory: %w", err)
+	}
+
+	store := metadata.NewStore(*metadataPath)
+	result, err := skeleton.Scan(store, absBaseDir, *relPath)
+	if err != nil {
+		return err
+	}
+
+	for _, suggestion := range result.Suggestions {
+		fmt.Printf("%s:%d  %s -> %s (%s)\n", suggestion.SourceFile, suggestion.TargetLines.Start, suggestion.FunctionName, suggestion.SuggestedName, suggestion.Priority)
+	}
+
+	if *apply {
+		applied, err := skeleton.Apply(store, result)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Stored %d suggestions\n", applied)
+	}
+
+	return nil
+}
@@ -6,18 +6,38 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"codebase-view-mcp/internal/api"
 	"codebase-view-mcp/internal/files"
 	"codebase-view-mcp/internal/mcp"
 	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/skeleton"
+	"codebase-view-mcp/internal/testrun"
+	"codebase-view-mcp/internal/watch"
 )
 
 func main() {
+	// Dispatch subcommands (e.g. "import-coverage") before parsing server flags
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Parse command line flags
 	port := flag.String("port", "8080", "Port to run the server on")
 	baseDir := flag.String("dir", ".", "Base directory to serve files from")
 	metadataPath := flag.String("metadata", "metadata.json", "Path to metadata JSON file")
+	allowTestRun := flag.String("allow-test-run", "", "Comma-separated package directories whose tests may be run from the viewer (dir/... includes subdirectories, ./... allows all); empty disables test runs")
+	testTimeout := flag.Duration("test-timeout", 5*time.Minute, "Time limit for a test run started from the viewer")
+	testConcurrency := flag.Int("test-concurrency", 2, "Maximum number of concurrent test runs started from the viewer")
+	allowApplySuggestions := flag.Bool("allow-apply-suggestions", false, "Allow accepted test suggestions to be written to test files in the working tree")
+	maxFileSize := flag.Int64("max-file-size", files.DefaultMaxFileSize, "Maximum number of bytes of a file returned by the file API; larger files are truncated (0 for no limit)")
+	allowSymlinks := flag.Bool("allow-symlinks", false, "Allow symlinks under the base directory to point outside of it")
+	watchFiles := flag.Bool("watch", true, "Watch the base directory and stream file and metadata changes on /api/events")
 	flag.Parse()
 
 	// Resolve absolute path for base directory
@@ -30,6 +50,12 @@ func main() {
 	log.Printf("Base directory: %s", absBaseDir)
 	log.Printf("Metadata file: %s", *metadataPath)
 	log.Printf("Server port: %s", *port)
+	if *allowTestRun != "" {
+		log.Printf("Test runs allowed in: %s", *allowTestRun)
+	}
+	if *allowApplySuggestions {
+		log.Printf("Accepted suggestions are written to the working tree")
+	}
 
 	// Check if base directory exists
 	if _, err := os.Stat(absBaseDir); os.IsNotExist(err) {
@@ -38,20 +64,37 @@ func main() {
 
 	// Initialize services
 	fileService := files.NewService(absBaseDir)
+	fileService.SetMaxFileSize(*maxFileSize)
+	fileService.SetAllowSymlinks(*allowSymlinks)
 	metaStore := metadata.NewStore(*metadataPath)
-	mcpHandler := mcp.NewHandler(metaStore)
+	materializer := skeleton.NewMaterializer(absBaseDir, *allowApplySuggestions)
+	mcpHandler := mcp.NewHandler(metaStore, fileService, materializer)
+	executor := testrun.NewExecutor(absBaseDir, strings.Split(*allowTestRun, ","), *testTimeout, *testConcurrency)
+
+	var watcher *watch.Watcher
+	if *watchFiles {
+		watcher = watch.New(absBaseDir, metaStore)
+		watcher.Start()
+		defer watcher.Close()
+		log.Printf("Watching for changes (%s)", watcher.Mode())
+	}
 
 	// Initialize API handler
-	apiHandler := api.NewHandler(fileService, metaStore, mcpHandler)
+	apiHandler := api.NewHandler(fileService, metaStore, mcpHandler, executor, materializer, watcher)
 
 	// Setup routes
 	router := api.SetupRoutes(apiHandler)
 
-	// Apply middleware
-	handler := api.Logging(api.CORS(router))
+	// Apply middleware; a server that runs tests or writes files only
+	// listens on the loopback interface and answers local pages
+	localOnly := *allowTestRun != "" || *allowApplySuggestions
+	handler := api.Logging(api.CORS(router, localOnly))
 
 	// Start server
 	addr := ":" + *port
+	if localOnly {
+		addr = "127.0.0.1:" + *port
+	}
 	log.Printf("Server listening on http://localhost%s", addr)
 	log.Printf("API available at http://localhost%s/api", addr)
 	log.Printf("MCP endpoint at http://localhost%s/api/mcp", addr)
@@ -4,6 +4,21 @@ export interface FileEntry {
   isDir: boolean;
   size?: number;
   modTime: string;
+  coverage?: CoverageSummary;
+}
+
+export interface CoverageSummary {
+  filesWithMetadata: number;
+  testedFunctions: number;
+  untestedFunctions: number;
+  functionLines: number;
+  declaredLines: number;
+  declaredPercent: number;
+  realCoveredLines: number;
+  realExecutableLines: number;
+  realPercent?: number;
+  openSuggestions: number;
+  unresolvedComments: number;
 }
 
 export interface LineRange {
@@ -11,6 +26,12 @@ export interface LineRange {
   end: number;
 }
 
+export interface DetectedLines {
+  inputLines: LineRange;
+  outputLines: LineRange;
+  assertions?: number[];
+}
+
 export interface Comment {
   id: string;
   line: number;
@@ -20,6 +41,16 @@ export interface Comment {
   updatedAt: string;
   resolved: boolean;
   contextLines?: LineRange;
+  fingerprint?: Fingerprint;
+  stale?: boolean;
+}
+
+export interface Fingerprint {
+  hash: string;
+  before?: string;
+  after?: string;
+  anchor?: string;
+  offset?: number;
 }
 
 export interface TestReference {
@@ -31,12 +62,65 @@ export interface TestReference {
   coveredLines: LineRange;
   inputLines?: LineRange;
   outputLines?: LineRange;
+  coveredLineSet?: number[];
+  coverageHash?: string;
+  origin?: string;
+  cases?: TestCase[];
+  fixtures?: FixtureLink[];
+  fingerprints?: { [field: string]: Fingerprint };
+  stale?: boolean;
+  staleHint?: string;
+  redactions?: Redaction[];
+}
+
+export interface Redaction {
+  path: string;
+  line: number;
+  kind: string;
+}
+
+export interface FixtureLink {
+  path: string;
+  line: number;
+  golden?: boolean;
+}
+
+export interface TestCase {
+  name: string;
+  lineRange: LineRange;
+  inputLines?: LineRange;
+  outputLines?: LineRange;
+}
+
+export interface TestCaseDetail extends TestCase {
+  inputData?: string;
+  expectedOutput?: string;
+  result?: TestResult;
 }
 
 export interface FileMetadata {
   tests?: TestReference[];
   suggestions?: TestSuggestion[];
   comments?: Comment[];
+  coverage?: FileCoverage;
+  testResults?: { [testName: string]: TestResult };
+  coverageRuns?: { [testName: string]: string };
+  mutations?: { [functionName: string]: MutationRun };
+}
+
+export interface FileCoverage {
+  mode: string;
+  blocks: CoverageBlock[];
+  importedAt: string;
+}
+
+export interface CoverageBlock {
+  startLine: number;
+  startCol: number;
+  endLine: number;
+  endCol: number;
+  numStmts: number;
+  count: number;
 }
 
 export interface TestSuggestion {
@@ -47,6 +131,34 @@ export interface TestSuggestion {
   suggestedName: string;
   testSkeleton: string;
   priority: 'high' | 'medium' | 'low';
+  origin?: 'generated';
+  check?: SkeletonCheck;
+}
+
+export interface MaterializeRequest {
+  suggestedName: string;
+  apply: boolean;
+}
+
+export interface MaterializeResponse {
+  sourceFile: string;
+  suggestedName: string;
+  testFile: string;
+  created: boolean;
+  diff: string;
+  applied: boolean;
+}
+
+export interface SkeletonCheck {
+  compiles: boolean;
+  diagnostics?: SkeletonDiagnostic[];
+  checkedAt: string;
+}
+
+export interface SkeletonDiagnostic {
+  line: number;
+  column: number;
+  message: string;
 }
 
 export interface SuggestionsResponse {
@@ -61,8 +173,78 @@ export interface FileContent {
   size: number;
   modTime: string;
   mimeType: string;
+  binary?: boolean; // content is empty; fetch /api/raw instead
+  truncated?: boolean; // content was cut at the size cap
+  startLine?: number; // set for line-range reads
+  endLine?: number;
+  totalLines?: number;
   metadata?: FileMetadata;
   coverageDepth?: CoverageDepth;
+  realCoverage?: LineCoverage;
+  testStatus?: { [testFile: string]: { [testName: string]: TestStatus } };
+  functionCoverage?: FunctionCoverage[];
+  indirectCoverage?: CoverageDepth;
+  mutations?: { [line: number]: LineMutations };
+}
+
+export interface LineMutations {
+  killed: number;
+  survived: number;
+  survivors?: string[];
+}
+
+export type MutantStatus = 'killed' | 'survived' | 'timeout' | 'invalid';
+
+export interface MutantResult {
+  line: number;
+  operator: 'conditional' | 'arithmetic' | 'return';
+  original: string;
+  mutated: string;
+  status: MutantStatus;
+  killedBy?: string[];
+  error?: string;
+}
+
+export interface MutationRun {
+  function: string;
+  lineRange: LineRange;
+  tests: string[];
+  mutants: MutantResult[];
+  killed: number;
+  survived: number;
+  invalid: number;
+  score: number;
+  runAt: string;
+}
+
+export type FunctionCoverageStatus = 'direct' | 'indirect' | 'untested';
+
+export interface ReachingTest {
+  testFile: string;
+  testName: string;
+  depth: number;
+}
+
+export interface FunctionCoverage {
+  name: string;
+  lineRange: LineRange;
+  status: FunctionCoverageStatus;
+  tests: ReachingTest[];
+}
+
+export type TestStatus = 'pass' | 'fail' | 'skip';
+
+export interface TestResult {
+  status: TestStatus;
+  elapsed: number;
+  output?: string;
+  runAt: string;
+}
+
+export interface LineCoverage {
+  coveredLines: number[];
+  uncoveredLines: number[];
+  hits: { [lineNumber: number]: number };
 }
 
 export interface CoverageDepth {
@@ -81,11 +263,81 @@ export interface TestDetail {
   inputLines?: LineRange;
   expectedOutput?: string;
   outputLines?: LineRange;
+  coveredLineSet?: number[];
+  result?: TestResult;
+  cases?: TestCaseDetail[];
+  fixtures?: FixtureDetail[];
+  fuzz?: FuzzCorpus;
+  stale?: boolean;
+  staleHint?: string;
+}
+
+export interface FuzzCorpus {
+  seeds: FuzzEntry[];
+  entries: FuzzEntry[];
+  truncated?: boolean;
+}
+
+export interface FuzzEntry {
+  name?: string;
+  path?: string;
+  line?: number;
+  values: FuzzValue[];
+  error?: string;
+}
+
+export interface FuzzValue {
+  type?: string;
+  value: string;
+}
+
+export interface FixtureDetail {
+  path: string;
+  line: number;
+  golden?: boolean;
+  exists: boolean;
+  size?: number;
+  binary?: boolean;
+  preview?: string;
+  truncated?: boolean;
+  redactions?: Redaction[];
+}
+
+export interface FindResult {
+  kind: 'file' | 'symbol';
+  path: string;
+  name?: string;
+  symbolKind?: string;
+  line?: number;
+  score: number;
+  positions: number[];
+}
+
+export interface FindResponse {
+  results: FindResult[];
+  truncated?: boolean;
+}
+
+export interface SearchMatch {
+  path: string;
+  line: number;
+  column: number;
+  length: number;
+  text: string;
+  before?: string[];
+  after?: string[];
+}
+
+export interface SearchResponse {
+  matches: SearchMatch[];
+  filesSearched: number;
+  truncated?: boolean;
 }
 
 export interface ListFilesResponse {
   path: string;
   files: FileEntry[];
+  coverage?: CoverageSummary;
 }
 
 export interface FileResponse {
@@ -146,4 +398,120 @@ export interface ExportContextResponse {
   tests?: TestDetail[];
   suggestions?: TestSuggestion[];
   formatted: string;
+  redactions?: Redaction[];
+}
+
+export interface TestID {
+  testFile: string;
+  testName: string;
+}
+
+export interface ChangeImpact {
+  lines: LineRange;
+  untestedLines: number[];
+  tests: TestID[];
+}
+
+export interface FileChangeImpact {
+  path: string;
+  changes: ChangeImpact[];
+  untestedLines: number[];
+}
+
+export interface ChangeImpactResponse {
+  base: string;
+  head?: string;
+  files: FileChangeImpact[];
+}
+
+export interface FileChange {
+  path: string;
+  lines: LineRange[];
+}
+
+export interface ImpactRequest {
+  files?: string[];
+  changes?: FileChange[];
+}
+
+export interface PackageTests {
+  package: string;
+  dir: string;
+  tests: string[];
+  runPattern: string;
+  command: string;
+}
+
+export interface ImpactResponse {
+  tests: TestID[];
+  packages: PackageTests[];
+}
+
+export interface DiscoveryRequest {
+  path: string;
+  apply?: boolean;
+}
+
+export interface DiscoveredTest {
+  name: string;
+  lineRange: LineRange;
+}
+
+export interface DiscoveredTestFile {
+  path: string;
+  language: string;
+  tests: DiscoveredTest[];
+}
+
+export interface ProposedTests {
+  sourceFile: string;
+  tests: TestReference[];
+}
+
+export interface DiscoveryResponse {
+  testFiles: DiscoveredTestFile[];
+  proposals: ProposedTests[];
+  applied: number;
+}
+
+export interface GenerateSuggestionsRequest {
+  path: string;
+  apply: boolean;
+}
+
+export interface GenerateSuggestionsResponse {
+  suggestions: TestSuggestion[];
+  applied: number;
+}
+
+export interface RunTestsRequest {
+  tests: TestID[];
+}
+
+export interface TestOutputEvent {
+  package?: string;
+  test?: string;
+  output: string;
+}
+
+export interface TestResultEvent {
+  package: string;
+  test: string;
+  status: TestStatus;
+  elapsed: number;
+}
+
+// Streamed by GET /api/events, as an event named after its type
+export interface ChangeEvent {
+  type: 'created' | 'modified' | 'deleted' | 'renamed' | 'metadata';
+  path: string;
+  oldPath?: string; // renamed: the path before the rename
+  dir?: boolean;
+}
+
+export interface TestResultsImportResponse {
+  passed: number;
+  failed: number;
+  skipped: number;
+  unmatched?: string[];
 }
@@ -0,0 +1,334 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// reportMethods are the testing.TB methods that report a failure
+var reportMethods = map[string]bool{
+	"Error": true, "Errorf": true, "Fatal": true, "Fatalf": true, "Fail": true, "FailNow": true,
+}
+
+// comparisons are calls that compare a result with an expected value
+var comparisons = map[string]bool{
+	"cmp.Diff": true, "cmp.Equal": true, "reflect.DeepEqual": true,
+}
+
+// DetectLines parses a test file and detects the input and output lines of
+// the named test for the function it tests
+func DetectLines(path, testName, functionName string) (*files.DetectedLines, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := FindFunc(file, testName)
+	if fn == nil {
+		return nil, fmt.Errorf("test %s not found in %s", testName, path)
+	}
+
+	return DetectTestLines(fset, fn, functionName), nil
+}
+
+// DetectTestLines locates the assertion sites of a test function and the
+// statements feeding the call under test. Assertions are t.Error*, t.Fatal*
+// and t.Fail* calls (reported with their enclosing if statement), testify
+// assert and require calls, and if statements comparing with cmp.Diff,
+// cmp.Equal or reflect.DeepEqual. Assertions before the first call of
+// functionName check the test setup and are ignored. Inputs are the literal
+// arguments of the call, the statements defining its arguments and
+// receiver, and the input fields of the table it is driven by.
+func DetectTestLines(fset *token.FileSet, fn *ast.FuncDecl, functionName string) *files.DetectedLines {
+	detected := &files.DetectedLines{}
+	if fn.Body == nil {
+		return detected
+	}
+
+	calls := callsUnderTest(fn.Body, functionName)
+	firstCall := token.NoPos
+	if len(calls) > 0 {
+		firstCall = calls[0].Pos()
+	}
+
+	testingVars := testingParams(fn)
+	assertVars := testifyVars(fn.Body)
+
+	lines := make(map[int]bool)
+	addSite := func(node ast.Node) {
+		if firstCall.IsValid() && node.End() < firstCall {
+			return
+		}
+		r := nodeLines(fset, node)
+		detected.OutputLines = mergeLines(detected.OutputLines, r)
+		lines[r.Start] = true
+	}
+
+	var stack []ast.Node
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		recv := identName(sel.X)
+
+		switch {
+		case reportMethods[sel.Sel.Name] && testingVars[recv]:
+			addSite(enclosingIf(stack, call))
+		case recv == "assert" || recv == "require" || assertVars[recv]:
+			addSite(call)
+		case comparisons[recv+"."+sel.Sel.Name]:
+			addSite(enclosingIf(stack, call))
+		}
+		return true
+	})
+
+	for line := range lines {
+		detected.Assertions = append(detected.Assertions, line)
+	}
+	sort.Ints(detected.Assertions)
+
+	for _, call := range calls {
+		detected.InputLines = mergeLines(detected.InputLines, callInputs(fset, fn, call))
+	}
+
+	return detected
+}
+
+// callsUnderTest returns the calls of the function (or method) under test,
+// in source order
+func callsUnderTest(body *ast.BlockStmt, functionName string) []*ast.CallExpr {
+	name := functionName[strings.LastIndex(functionName, ".")+1:]
+	if name == "" {
+		return nil
+	}
+
+	var calls []*ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fun := call.Fun
+		if index, ok := fun.(*ast.IndexExpr); ok {
+			fun = index.X
+		} else if index, ok := fun.(*ast.IndexListExpr); ok {
+			fun = index.X
+		}
+		switch f := fun.(type) {
+		case *ast.Ident:
+			if f.Name == name {
+				calls = append(calls, call)
+			}
+		case *ast.SelectorExpr:
+			if f.Sel.Name == name {
+				calls = append(calls, call)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+// callInputs returns the lines feeding a call: literal arguments, the last
+// statements defining identifier arguments and the receiver before the
+// call, and the input fields of a table the arguments are taken from
+func callInputs(fset *token.FileSet, fn *ast.FuncDecl, call *ast.CallExpr) files.LineRange {
+	var inputs files.LineRange
+
+	exprs := append([]ast.Expr{}, call.Args...)
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		exprs = append(exprs, sel.X)
+	}
+
+	tableVars := rangeValueVars(fn.Body)
+	usesTable := false
+
+	for _, expr := range exprs {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.BasicLit, *ast.CompositeLit:
+			inputs = mergeLines(inputs, nodeLines(fset, e))
+		case *ast.UnaryExpr:
+			if _, ok := e.X.(*ast.CompositeLit); ok {
+				inputs = mergeLines(inputs, nodeLines(fset, e))
+			}
+		case *ast.Ident:
+			if def := lastDefinition(fn.Body, e.Name, call.Pos()); def != nil {
+				inputs = mergeLines(inputs, nodeLines(fset, def))
+			}
+		case *ast.SelectorExpr:
+			if tableVars[identName(e.X)] {
+				usesTable = true
+			}
+		}
+	}
+
+	if usesTable {
+		for _, tc := range ExtractCases(fset, fn) {
+			if tc.InputLines.Start > 0 {
+				inputs = mergeLines(inputs, tc.InputLines)
+			}
+		}
+	}
+
+	return inputs
+}
+
+// lastDefinition returns the last statement before pos that assigns or
+// declares name
+func lastDefinition(body *ast.BlockStmt, name string, pos token.Pos) ast.Node {
+	var def ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil || n.Pos() >= pos {
+			return false
+		}
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range stmt.Lhs {
+				if identName(lhs) == name {
+					def = stmt
+				}
+			}
+		case *ast.ValueSpec:
+			for _, ident := range stmt.Names {
+				if ident.Name == name {
+					def = stmt
+				}
+			}
+		}
+		return true
+	})
+	return def
+}
+
+// rangeValueVars returns the value variables of range loops, through which
+// table-driven tests read their cases
+func rangeValueVars(body *ast.BlockStmt) map[string]bool {
+	vars := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if rangeStmt, ok := n.(*ast.RangeStmt); ok {
+			if name := identName(rangeStmt.Value); name != "" {
+				vars[name] = true
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// testingParams returns the names of the *testing.T, *testing.B, *testing.F
+// and testing.TB parameters of a test and of the function literals in it
+func testingParams(fn *ast.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	collect := func(params *ast.FieldList) {
+		if params == nil {
+			return
+		}
+		for _, field := range params.List {
+			if !isTestingType(field.Type) {
+				continue
+			}
+			for _, ident := range field.Names {
+				names[ident.Name] = true
+			}
+		}
+	}
+
+	collect(fn.Type.Params)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			collect(lit.Type.Params)
+		}
+		return true
+	})
+	return names
+}
+
+// isTestingType reports whether a type expression is *testing.T, *testing.B,
+// *testing.F or testing.TB
+func isTestingType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || identName(sel.X) != "testing" {
+		return false
+	}
+	switch sel.Sel.Name {
+	case "T", "B", "F", "TB":
+		return true
+	}
+	return false
+}
+
+// testifyVars returns the variables holding assert.New or require.New values
+func testifyVars(body *ast.BlockStmt) map[string]bool {
+	vars := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, rhs := range assign.Rhs {
+			call, ok := rhs.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "New" {
+				continue
+			}
+			if pkg := identName(sel.X); pkg == "assert" || pkg == "require" {
+				if name := identName(assign.Lhs[i]); name != "" {
+					vars[name] = true
+				}
+			}
+		}
+		return true
+	})
+	return vars
+}
+
+// enclosingIf returns the if statement whose body or condition directly
+// contains a node, or the statement containing the node otherwise
+func enclosingIf(stack []ast.Node, node ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch s := stack[i].(type) {
+		case *ast.IfStmt:
+			return s
+		case *ast.FuncLit, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			// Stop at the enclosing loop or closure: the node is not guarded by an if
+			return statementIn(stack[i+1:], node)
+		}
+	}
+	return statementIn(stack, node)
+}
+
+// statementIn returns the outermost statement in a stack slice, or node
+func statementIn(stack []ast.Node, node ast.Node) ast.Node {
+	for _, n := range stack {
+		if stmt, ok := n.(ast.Stmt); ok {
+			if _, isBlock := stmt.(*ast.BlockStmt); !isBlock {
+				return stmt
+			}
+		}
+	}
+	return node
+}
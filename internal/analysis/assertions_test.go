@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+const assertionsSource = `package sample
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	input := "a,b"
+	if input == "" {
+		t.Fatal("empty input")
+	}
+	got, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Parse() = %v", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{name: "two", in: "a b", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.in)
+			assert.Equal(t, tt.want, len(got))
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter()
+	c.Add(3)
+	if c.Total() != 3 {
+		t.Error("wrong total")
+	}
+}
+`
+
+func TestDetectTestLines(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample_test.go", assertionsSource, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		test     string
+		function string
+		want     files.DetectedLines
+	}{
+		{
+			name:     "setup assertions are skipped",
+			test:     "TestParse",
+			function: "Parse",
+			want: files.DetectedLines{
+				InputLines:  files.LineRange{Start: 11, End: 11},
+				OutputLines: files.LineRange{Start: 16, End: 21},
+				Assertions:  []int{16, 19},
+			},
+		},
+		{
+			name:     "table-driven with testify",
+			test:     "TestSplit",
+			function: "Split",
+			want: files.DetectedLines{
+				InputLines:  files.LineRange{Start: 30, End: 30},
+				OutputLines: files.LineRange{Start: 35, End: 35},
+				Assertions:  []int{35},
+			},
+		},
+		{
+			name:     "method receiver",
+			test:     "TestCounter",
+			function: "Counter.Add",
+			want: files.DetectedLines{
+				InputLines:  files.LineRange{Start: 41, End: 42},
+				OutputLines: files.LineRange{Start: 43, End: 45},
+				Assertions:  []int{43},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectTestLines(fset, FindFunc(file, tt.test), tt.function)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Fatalf("DetectTestLines() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
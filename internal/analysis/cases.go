@@ -0,0 +1,331 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// TestCases parses a test file and extracts the cases of the named test
+func TestCases(path, testName string) ([]files.TestCase, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := FindFunc(file, testName)
+	if fn == nil {
+		return nil, fmt.Errorf("test %s not found in %s", testName, path)
+	}
+
+	return ExtractCases(fset, fn), nil
+}
+
+// FindFunc returns the top-level function with the given name
+func FindFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return fn
+		}
+	}
+	return nil
+}
+
+// ExtractCases finds the sub-cases of a test function: one case per element
+// of a table (slice, array or map literal) that is ranged over with t.Run,
+// and one case per t.Run call with a literal name outside such loops
+func ExtractCases(fset *token.FileSet, fn *ast.FuncDecl) []files.TestCase {
+	if fn.Body == nil {
+		return nil
+	}
+
+	tables := collectTables(fn.Body)
+	tableRuns := make(map[*ast.CallExpr]bool)
+
+	var cases []files.TestCase
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		rangeStmt, ok := n.(*ast.RangeStmt)
+		if !ok {
+			return true
+		}
+
+		table := rangeTable(rangeStmt.X, tables)
+		if table == nil {
+			return true
+		}
+
+		run := findRunCall(rangeStmt.Body)
+		if run == nil {
+			return true
+		}
+		tableRuns[run] = true
+
+		cases = append(cases, tableCases(fset, table, rangeStmt, run)...)
+		return true
+	})
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || tableRuns[call] || !isRunCall(call) {
+			return true
+		}
+
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+
+		name, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+
+		cases = append(cases, files.TestCase{
+			Name:      name,
+			LineRange: nodeLines(fset, call),
+		})
+		return true
+	})
+
+	return cases
+}
+
+// collectTables maps variable names to the composite literals assigned to them
+func collectTables(body *ast.BlockStmt) map[string]*ast.CompositeLit {
+	tables := make(map[string]*ast.CompositeLit)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range stmt.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || i >= len(stmt.Rhs) {
+					continue
+				}
+				if lit, ok := stmt.Rhs[i].(*ast.CompositeLit); ok && isTableType(lit.Type) {
+					tables[ident.Name] = lit
+				}
+			}
+		case *ast.ValueSpec:
+			for i, ident := range stmt.Names {
+				if i >= len(stmt.Values) {
+					continue
+				}
+				if lit, ok := stmt.Values[i].(*ast.CompositeLit); ok && isTableType(lit.Type) {
+					tables[ident.Name] = lit
+				}
+			}
+		}
+		return true
+	})
+
+	return tables
+}
+
+// isTableType reports whether a composite literal type is a slice, array or map
+func isTableType(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.ArrayType, *ast.MapType:
+		return true
+	}
+	return false
+}
+
+// rangeTable returns the table literal a range statement iterates over
+func rangeTable(x ast.Expr, tables map[string]*ast.CompositeLit) *ast.CompositeLit {
+	switch e := x.(type) {
+	case *ast.Ident:
+		return tables[e.Name]
+	case *ast.CompositeLit:
+		if isTableType(e.Type) {
+			return e
+		}
+	}
+	return nil
+}
+
+// findRunCall returns the first t.Run call in a loop body
+func findRunCall(body *ast.BlockStmt) *ast.CallExpr {
+	var run *ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if run != nil {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok && isRunCall(call) {
+			run = call
+			return false
+		}
+		return true
+	})
+	return run
+}
+
+// isRunCall reports whether a call looks like t.Run(name, func(t *testing.T) {...})
+func isRunCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Run" || len(call.Args) != 2 {
+		return false
+	}
+	_, ok = call.Args[1].(*ast.FuncLit)
+	return ok
+}
+
+// tableCases builds one case per table element
+func tableCases(fset *token.FileSet, table *ast.CompositeLit, rangeStmt *ast.RangeStmt, run *ast.CallExpr) []files.TestCase {
+	keyVar, valueVar := identName(rangeStmt.Key), identName(rangeStmt.Value)
+	nameField, nameFromKey := caseNameSource(run.Args[0], keyVar, valueVar)
+
+	var fieldOrder []string
+	if elemType := tableElemType(table.Type); elemType != nil {
+		for _, field := range elemType.Fields.List {
+			for _, name := range field.Names {
+				fieldOrder = append(fieldOrder, name.Name)
+			}
+		}
+	}
+
+	var cases []files.TestCase
+	for i, elt := range table.Elts {
+		var key ast.Expr
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, elt = kv.Key, kv.Value
+		}
+		if unary, ok := elt.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			elt = unary.X
+		}
+
+		tc := files.TestCase{
+			Name:      fmt.Sprintf("#%02d", i), // go test's name for unnamed subtests
+			LineRange: nodeLines(fset, elt),
+		}
+		if nameFromKey && key != nil {
+			if name, ok := stringValue(key); ok {
+				tc.Name = name
+			}
+		}
+
+		lit, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			cases = append(cases, tc)
+			continue
+		}
+
+		for j, field := range lit.Elts {
+			fieldName, value := "", field
+			if kv, ok := field.(*ast.KeyValueExpr); ok {
+				if ident, ok := kv.Key.(*ast.Ident); ok {
+					fieldName = ident.Name
+				}
+				value = kv.Value
+			} else if j < len(fieldOrder) {
+				fieldName = fieldOrder[j]
+			}
+
+			if fieldName != "" && fieldName == nameField {
+				if name, ok := stringValue(value); ok {
+					tc.Name = name
+				}
+				continue
+			}
+
+			lines := nodeLines(fset, field)
+			if isOutputField(fieldName) {
+				tc.OutputLines = mergeLines(tc.OutputLines, lines)
+			} else {
+				tc.InputLines = mergeLines(tc.InputLines, lines)
+			}
+		}
+
+		cases = append(cases, tc)
+	}
+
+	return cases
+}
+
+// caseNameSource determines which struct field (or the map key) names the cases
+func caseNameSource(nameArg ast.Expr, keyVar, valueVar string) (string, bool) {
+	switch e := nameArg.(type) {
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok && ident.Name == valueVar {
+			return e.Sel.Name, false
+		}
+	case *ast.Ident:
+		if e.Name == keyVar {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// tableElemType returns the struct type of table elements, if declared inline
+func tableElemType(expr ast.Expr) *ast.StructType {
+	var elem ast.Expr
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		elem = t.Elt
+	case *ast.MapType:
+		elem = t.Value
+	}
+	if star, ok := elem.(*ast.StarExpr); ok {
+		elem = star.X
+	}
+	st, _ := elem.(*ast.StructType)
+	return st
+}
+
+// isOutputField reports whether a table field holds an expected result
+func isOutputField(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range []string{"want", "expect", "output", "golden", "err", "result"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return lower == "exp" || lower == "out" || lower == "res"
+}
+
+// identName returns the name of an identifier expression
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// stringValue returns the value of a string literal
+func stringValue(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// nodeLines returns the line range spanned by a node
+func nodeLines(fset *token.FileSet, node ast.Node) files.LineRange {
+	return files.LineRange{
+		Start: fset.Position(node.Pos()).Line,
+		End:   fset.Position(node.End()).Line,
+	}
+}
+
+// mergeLines extends a range to include another one
+func mergeLines(a, b files.LineRange) files.LineRange {
+	if a.Start == 0 {
+		return b
+	}
+	if b.Start < a.Start {
+		a.Start = b.Start
+	}
+	if b.End > a.End {
+		a.End = b.End
+	}
+	return a
+}
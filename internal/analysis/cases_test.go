@@ -0,0 +1,80 @@
+package analysis
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+const casesSource = `package sample
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b int
+		want int
+	}{
+		{
+			name: "positive",
+			a:    1,
+			b:    2,
+			want: 3,
+		},
+		{"negative", -1, -2, -3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a + tt.b; got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("zero", func(t *testing.T) {
+	})
+}
+`
+
+func TestExtractCases(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample_test.go", casesSource, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	cases := ExtractCases(fset, FindFunc(file, "TestAdd"))
+
+	want := []files.TestCase{
+		{
+			Name:        "positive",
+			LineRange:   files.LineRange{Start: 11, End: 16},
+			InputLines:  files.LineRange{Start: 13, End: 14},
+			OutputLines: files.LineRange{Start: 15, End: 15},
+		},
+		{
+			Name:        "negative",
+			LineRange:   files.LineRange{Start: 17, End: 17},
+			InputLines:  files.LineRange{Start: 17, End: 17},
+			OutputLines: files.LineRange{Start: 17, End: 17},
+		},
+		{
+			Name:      "zero",
+			LineRange: files.LineRange{Start: 28, End: 29},
+		},
+	}
+
+	if len(cases) != len(want) {
+		t.Fatalf("cases count = %d, want %d (%+v)", len(cases), len(want), cases)
+	}
+
+	for i := range want {
+		if cases[i] != want[i] {
+			t.Fatalf("case %d = %+v, want %+v", i, cases[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// maxFixtureMatches caps the files a single wildcard reference links to
+const maxFixtureMatches = 20
+
+// fileReaders are the calls whose path argument names a file the test reads
+var fileReaders = map[string]bool{
+	"os.ReadFile": true, "os.Open": true, "os.OpenFile": true, "os.Stat": true,
+	"ioutil.ReadFile": true,
+}
+
+// formatVerb matches the verbs of a fmt.Sprintf format
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// TestFixtures parses a test file and links the named test to the testdata
+// fixtures and golden files it references. testFile is relative to baseDir.
+func TestFixtures(baseDir, testFile, testName string) ([]files.FixtureLink, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(baseDir, filepath.FromSlash(testFile)), nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := FindFunc(file, testName)
+	if fn == nil {
+		return nil, nil
+	}
+
+	return ExtractFixtures(fset, fn, baseDir, path.Dir(filepath.ToSlash(testFile))), nil
+}
+
+// fixtureRef is a path pattern referenced by a test, relative to its package
+type fixtureRef struct {
+	pattern string
+	line    int
+	golden  bool
+}
+
+// ExtractFixtures finds the files a test references: string paths passed to
+// os.ReadFile and friends, filepath.Join("testdata", ...) expressions, string
+// literals under testdata/ or ending in .golden, and the arguments of golden
+// helpers (functions with "golden" in their name, which read from testdata/).
+// Parts of a path computed at run time, such as a table field, become
+// wildcards expanded against the package directory pkgDir (relative to
+// baseDir). Returned paths are relative to baseDir.
+func ExtractFixtures(fset *token.FileSet, fn *ast.FuncDecl, baseDir, pkgDir string) []files.FixtureLink {
+	if fn.Body == nil {
+		return nil
+	}
+
+	var refs []fixtureRef
+	add := func(node ast.Node, pattern string, golden bool) {
+		refs = append(refs, fixtureRef{pattern: pattern, line: fset.Position(node.Pos()).Line, golden: golden})
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			name := callName(node.Fun)
+			switch {
+			case fileReaders[name] && len(node.Args) > 0:
+				if pattern, ok := pathPattern(node.Args[0]); ok {
+					add(node, pattern, false)
+				}
+				return false
+			case name == "filepath.Join" || name == "path.Join":
+				if pattern, ok := pathPattern(node); ok && strings.HasPrefix(pattern, "testdata/") {
+					add(node, pattern, false)
+				}
+				return false
+			case strings.Contains(strings.ToLower(name), "golden"):
+				for _, arg := range node.Args {
+					if pattern, ok := pathPattern(arg); ok {
+						add(node, pattern, true)
+					}
+				}
+				return false
+			}
+		case *ast.BasicLit:
+			if value, ok := stringValue(node); ok && (strings.HasPrefix(value, "testdata/") || strings.HasSuffix(value, ".golden")) {
+				add(node, value, false)
+			}
+		}
+		return true
+	})
+
+	// Resolve specific patterns first so that a file matched by several
+	// references is linked to the most specific one
+	sort.SliceStable(refs, func(i, j int) bool {
+		return len(strings.ReplaceAll(refs[i].pattern, "*", "")) > len(strings.ReplaceAll(refs[j].pattern, "*", ""))
+	})
+
+	var links []files.FixtureLink
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		for _, p := range resolveFixture(baseDir, pkgDir, ref) {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			links = append(links, files.FixtureLink{
+				Path:   p,
+				Line:   ref.line,
+				Golden: ref.golden || strings.HasSuffix(p, ".golden"),
+			})
+		}
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		if links[i].Line != links[j].Line {
+			return links[i].Line < links[j].Line
+		}
+		return links[i].Path < links[j].Path
+	})
+	return links
+}
+
+// resolveFixture expands a referenced path pattern into files under baseDir.
+// Exact paths are kept even when missing, since golden files are often
+// created by the first -update run; wildcards only match existing files.
+func resolveFixture(baseDir, pkgDir string, ref fixtureRef) []string {
+	candidates := []string{ref.pattern}
+	if ref.golden && !strings.HasPrefix(ref.pattern, "testdata/") {
+		// Golden helpers conventionally resolve names under testdata/
+		candidates = []string{"testdata/" + ref.pattern, ref.pattern}
+		if !strings.HasSuffix(ref.pattern, ".golden") {
+			candidates = append(candidates[:1], "testdata/"+ref.pattern+".golden", ref.pattern)
+		}
+	}
+
+	var fallback string
+	for _, candidate := range candidates {
+		rel := path.Join(pkgDir, candidate)
+		if !filepath.IsLocal(rel) || strings.Trim(candidate, "*/") == "" {
+			continue
+		}
+
+		if !strings.Contains(rel, "*") {
+			info, err := os.Stat(filepath.Join(baseDir, filepath.FromSlash(rel)))
+			if err == nil && !info.IsDir() {
+				return []string{rel}
+			}
+			if err != nil && fallback == "" {
+				fallback = rel
+			}
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(baseDir, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		var found []string
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			if relMatch, err := filepath.Rel(baseDir, match); err == nil {
+				found = append(found, filepath.ToSlash(relMatch))
+			}
+			if len(found) == maxFixtureMatches {
+				break
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+
+	if fallback != "" && (ref.golden || strings.HasPrefix(ref.pattern, "testdata/") || strings.HasSuffix(fallback, ".golden")) {
+		return []string{fallback}
+	}
+	return nil
+}
+
+// pathPattern renders a path expression with its run-time parts replaced by
+// "*". It fails when the expression has no literal part.
+func pathPattern(expr ast.Expr) (string, bool) {
+	pattern, literal := renderPath(expr)
+	if !literal {
+		return "", false
+	}
+	return path.Clean(pattern), true
+}
+
+// renderPath renders a path expression and reports whether it contains a
+// string literal
+func renderPath(expr ast.Expr) (string, bool) {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.BasicLit:
+		if value, ok := stringValue(e); ok {
+			return value, true
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			x, xLit := renderPath(e.X)
+			y, yLit := renderPath(e.Y)
+			return collapseWildcards(x + y), xLit || yLit
+		}
+	case *ast.CallExpr:
+		switch callName(e.Fun) {
+		case "filepath.Join", "path.Join":
+			var parts []string
+			literal := false
+			for _, arg := range e.Args {
+				part, ok := renderPath(arg)
+				parts = append(parts, part)
+				literal = literal || ok
+			}
+			return strings.Join(parts, "/"), literal
+		case "fmt.Sprintf":
+			if len(e.Args) > 0 {
+				if format, ok := e.Args[0].(*ast.BasicLit); ok {
+					if value, err := strconv.Unquote(format.Value); err == nil {
+						return collapseWildcards(formatVerb.ReplaceAllString(value, "*")), true
+					}
+				}
+			}
+		}
+	}
+	return "*", false
+}
+
+// collapseWildcards merges adjacent wildcards
+func collapseWildcards(pattern string) string {
+	for strings.Contains(pattern, "**") {
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+	}
+	return pattern
+}
+
+// callName returns the dotted name of a called function
+func callName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		if x := identName(f.X); x != "" {
+			return x + "." + f.Sel.Name
+		}
+		return f.Sel.Name
+	}
+	return ""
+}
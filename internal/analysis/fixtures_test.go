@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+const fixturesSource = `package sample
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"basic", "basic.txt"},
+	}
+	for _, tt := range tests {
+		input, _ := os.ReadFile(filepath.Join("testdata", tt.in))
+		want, _ := os.ReadFile(filepath.Join("testdata", tt.name+".golden"))
+		_, _ = input, want
+	}
+}
+
+func TestConfig(t *testing.T) {
+	data, _ := os.ReadFile("testdata/config.json")
+	assertGolden(t, data, "config")
+	_ = filepath.Join(t.TempDir(), "out.txt")
+}
+`
+
+func TestExtractFixtures(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"basic.txt", "basic.golden", "config.json", "config.golden"} {
+		path := filepath.Join(dir, "sample", "testdata", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample_test.go", fixturesSource, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		test string
+		want []files.FixtureLink
+	}{
+		{
+			name: "table-driven wildcards",
+			test: "TestRender",
+			want: []files.FixtureLink{
+				{Path: "sample/testdata/basic.txt", Line: 17},
+				{Path: "sample/testdata/config.json", Line: 17},
+				{Path: "sample/testdata/basic.golden", Line: 18, Golden: true},
+				{Path: "sample/testdata/config.golden", Line: 18, Golden: true},
+			},
+		},
+		{
+			name: "literal and golden helper",
+			test: "TestConfig",
+			want: []files.FixtureLink{
+				{Path: "sample/testdata/config.json", Line: 24},
+				{Path: "sample/testdata/config.golden", Line: 25, Golden: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractFixtures(fset, FindFunc(file, tt.test), dir, "sample")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ExtractFixtures() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Function describes a top-level function or method declaration
+type Function struct {
+	Name      string // function name, without receiver
+	Receiver  string // receiver type name without pointer, empty for functions
+	StartLine int
+	EndLine   int
+}
+
+// QualifiedName returns "Type.Method" for methods and the plain name otherwise
+func (f Function) QualifiedName() string {
+	if f.Receiver == "" {
+		return f.Name
+	}
+	return f.Receiver + "." + f.Name
+}
+
+// Exported reports whether the function name is exported
+func (f Function) Exported() bool {
+	return ast.IsExported(f.Name)
+}
+
+// ParseFunctions parses a Go source file and returns its function declarations
+func ParseFunctions(path string, src []byte) ([]Function, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	return FileFunctions(fset, file), nil
+}
+
+// FileFunctions returns the function declarations of a parsed file
+func FileFunctions(fset *token.FileSet, file *ast.File) []Function {
+	var functions []Function
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		functions = append(functions, Function{
+			Name:      fn.Name.Name,
+			Receiver:  ReceiverName(fn),
+			StartLine: fset.Position(fn.Pos()).Line,
+			EndLine:   fset.Position(fn.End()).Line,
+		})
+	}
+
+	return functions
+}
+
+// ReceiverName returns the receiver type name of a method without pointer
+// or type parameters, or an empty string for plain functions
+func ReceiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	expr := fn.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// FunctionAt returns the function spanning the given line
+func FunctionAt(functions []Function, line int) (Function, bool) {
+	for _, fn := range functions {
+		if line >= fn.StartLine && line <= fn.EndLine {
+			return fn, true
+		}
+	}
+	return Function{}, false
+}
+
+// IsTestFunc reports whether a declaration is a top-level go test of the given
+// kind ("Test", "Benchmark", "Fuzz" or "Example"), following the rules of go test
+func IsTestFunc(fn *ast.FuncDecl, prefix string) bool {
+	if fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, prefix) {
+		return false
+	}
+
+	rest := fn.Name.Name[len(prefix):]
+	if rest != "" {
+		r, _ := utf8.DecodeRuneInString(rest)
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+
+	if prefix == "Example" {
+		return fn.Type.Params.NumFields() == 0
+	}
+
+	if fn.Name.Name == "TestMain" || fn.Type.Params.NumFields() != 1 {
+		return false
+	}
+
+	star, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	switch prefix {
+	case "Test":
+		return sel.Sel.Name == "T"
+	case "Benchmark":
+		return sel.Sel.Name == "B"
+	case "Fuzz":
+		return sel.Sel.Name == "F"
+	}
+
+	return false
+}
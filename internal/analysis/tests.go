@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TestFunc describes a test function discovered in a _test.go file
+type TestFunc struct {
+	Name      string
+	File      string // path of the test file as passed to DiscoverTests
+	Package   string // package clause of the test file (may end in _test)
+	StartLine int
+	EndLine   int
+	Decl      *ast.FuncDecl
+	Fset      *token.FileSet
+}
+
+// DiscoverTests finds Test functions in the _test.go files of a package directory.
+// Returned file paths are joined onto relDir.
+func DiscoverTests(baseDir, relDir string) ([]TestFunc, error) {
+	return discover(baseDir, relDir, "Test")
+}
+
+// discover collects top-level test functions with the given prefix
+func discover(baseDir, relDir, prefix string) ([]TestFunc, error) {
+	entries, err := os.ReadDir(filepath.Join(baseDir, relDir))
+	if err != nil {
+		return nil, err
+	}
+
+	var tests []TestFunc
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+
+		relPath := filepath.ToSlash(filepath.Join(relDir, entry.Name()))
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filepath.Join(baseDir, relPath), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !IsTestFunc(fn, prefix) {
+				continue
+			}
+
+			tests = append(tests, TestFunc{
+				Name:      fn.Name.Name,
+				File:      relPath,
+				Package:   file.Name.Name,
+				StartLine: fset.Position(fn.Pos()).Line,
+				EndLine:   fset.Position(fn.End()).Line,
+				Decl:      fn,
+				Fset:      fset,
+			})
+		}
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].Name < tests[j].Name
+	})
+
+	return tests, nil
+}
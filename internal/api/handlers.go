@@ -2,29 +2,81 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/callgraph"
+	"codebase-view-mcp/internal/coverage"
+	"codebase-view-mcp/internal/discovery"
 	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/finder"
+	"codebase-view-mcp/internal/fuzz"
+	"codebase-view-mcp/internal/impact"
 	"codebase-view-mcp/internal/mcp"
 	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/redact"
+	"codebase-view-mcp/internal/relocate"
+	"codebase-view-mcp/internal/rollup"
+	"codebase-view-mcp/internal/search"
+	"codebase-view-mcp/internal/skeleton"
+	"codebase-view-mcp/internal/testrun"
+	"codebase-view-mcp/internal/watch"
 )
 
 // Handler handles HTTP requests
 type Handler struct {
-	fileService *files.Service
-	metaStore   *metadata.Store
-	mcpHandler  *mcp.Handler
+	fileService  *files.Service
+	metaStore    *metadata.Store
+	mcpHandler   *mcp.Handler
+	callGraph    *callgraph.Cache
+	executor     *testrun.Executor
+	rollups      *rollup.Cache
+	materializer *skeleton.Materializer
+	search       *search.Index
+	finder       *finder.Index
+	watcher      *watch.Watcher
 }
 
 // NewHandler creates a new HTTP handler
-func NewHandler(fileService *files.Service, metaStore *metadata.Store, mcpHandler *mcp.Handler) *Handler {
-	return &Handler{
-		fileService: fileService,
-		metaStore:   metaStore,
-		mcpHandler:  mcpHandler,
+func NewHandler(fileService *files.Service, metaStore *metadata.Store, mcpHandler *mcp.Handler, executor *testrun.Executor, materializer *skeleton.Materializer, watcher *watch.Watcher) *Handler {
+	h := &Handler{
+		fileService:  fileService,
+		metaStore:    metaStore,
+		mcpHandler:   mcpHandler,
+		callGraph:    callgraph.NewCache(fileService.BaseDir()),
+		executor:     executor,
+		rollups:      rollup.New(metaStore, fileService.BaseDir()),
+		materializer: materializer,
+		search:       search.New(fileService.BaseDir()),
+		finder:       finder.New(fileService.BaseDir()),
+		watcher:      watcher,
 	}
+
+	// Reported changes make the indexes look at the file tree right away,
+	// and file changes have the call graph rebuilt in the background
+	if watcher != nil {
+		watcher.OnChange(func(events []files.ChangeEvent) {
+			h.search.Invalidate()
+			h.finder.Invalidate()
+			for _, event := range events {
+				if event.Type != watch.Metadata {
+					h.callGraph.Invalidate()
+					break
+				}
+			}
+		})
+	}
+	return h
 }
 
 // ListFiles handles GET /api/files
@@ -36,10 +88,23 @@ func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 
 	response, err := h.fileService.ListFiles(path)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		pathError(w, err)
 		return
 	}
 
+	// Attach coverage rollups to the directory and its entries
+	if h.rollups != nil {
+		summaries := h.rollups.Summaries()
+		if summary, ok := summaries[filepath.ToSlash(filepath.Clean(path))]; ok {
+			response.Coverage = &summary
+		}
+		for i := range response.Files {
+			if summary, ok := summaries[filepath.ToSlash(filepath.Clean(response.Files[i].Path))]; ok {
+				response.Files[i].Coverage = &summary
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
@@ -55,28 +120,69 @@ func (h *Handler) GetFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Read file content
-	fileContent, err := h.fileService.ReadFile(path)
+	// Read file content, or only the requested lines
+	var fileContent *files.FileContent
+	var err error
+	query := r.URL.Query()
+	if query.Has("start") || query.Has("end") {
+		start, end := 1, 0
+		if v := query.Get("start"); v != "" {
+			if start, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "start must be a line number", http.StatusBadRequest)
+				return
+			}
+		}
+		if v := query.Get("end"); v != "" {
+			if end, err = strconv.Atoi(v); err != nil {
+				http.Error(w, "end must be a line number", http.StatusBadRequest)
+				return
+			}
+		}
+		fileContent, err = h.fileService.ReadLines(path, start, end)
+	} else {
+		fileContent, err = h.fileService.ReadFile(path)
+	}
+	if errors.Is(err, files.ErrInvalidRange) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		pathError(w, err)
 		return
 	}
 
-	// Attach metadata if available
-	metadata := h.metaStore.GetTestMetadata(path)
-	if metadata != nil {
-		fileContent.Metadata = metadata
+	// Attach metadata if available, with ranges moved to follow file edits
+	fileMeta := relocate.New(h.fileService).ResolveMetadata(path, h.metaStore.GetTestMetadata(path))
+	if fileMeta != nil {
+		fileContent.Metadata = fileMeta
 
 		// Calculate coverage depth: map of line number -> list of test names covering it
-		if len(metadata.Tests) > 0 {
+		if len(fileMeta.Tests) > 0 {
 			coverageDepth := make(map[int][]string)
-			for _, test := range metadata.Tests {
-				for line := test.CoveredLines.Start; line <= test.CoveredLines.End; line++ {
+			testStatus := make(map[string]map[string]string)
+			for _, test := range fileMeta.Tests {
+				for _, line := range metadata.CoveredLines(test) {
 					coverageDepth[line] = append(coverageDepth[line], test.TestName)
 				}
+				if result := h.metaStore.GetTestResult(test.TestFile, test.TestName); result != nil {
+					if testStatus[test.TestFile] == nil {
+						testStatus[test.TestFile] = make(map[string]string)
+					}
+					testStatus[test.TestFile][test.TestName] = result.Status
+				}
 			}
 			fileContent.CoverageDepth = coverageDepth
+			if len(testStatus) > 0 {
+				fileContent.TestStatus = testStatus
+			}
 		}
+
+		fileContent.RealCoverage = coverage.Lines(fileMeta.Coverage)
+		fileContent.Mutations = lineMutations(fileMeta.Mutations)
+	}
+
+	if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
+		fileContent.FunctionCoverage, fileContent.IndirectCoverage = h.functionCoverage(path, fileMeta)
 	}
 
 	response := files.FileResponse{
@@ -90,6 +196,103 @@ func (h *Handler) GetFile(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// pathError replies to a request for a path the file service refused:
+// forbidden outside the base directory, not found otherwise
+func pathError(w http.ResponseWriter, err error) {
+	if errors.Is(err, files.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusNotFound)
+}
+
+// functionCoverage classifies the functions of a Go file as directly,
+// indirectly or not tested, using the static call graph of the module and
+// the functions named by the file's test metadata
+func (h *Handler) functionCoverage(path string, fileMeta *files.FileMetadata) ([]files.FunctionCoverage, map[int][]string) {
+	if h.callGraph == nil {
+		return nil, nil
+	}
+
+	// Nothing is reported while the first graph is being built
+	graph, err := h.callGraph.Graph()
+	if graph == nil || err != nil {
+		return nil, nil
+	}
+
+	named := make(map[string]bool)
+	if fileMeta != nil {
+		for _, test := range fileMeta.Tests {
+			named[test.FunctionName] = true
+		}
+	}
+
+	var functions []files.FunctionCoverage
+	indirect := make(map[int][]string)
+
+	for _, fr := range graph.FileReach(path) {
+		fc := files.FunctionCoverage{
+			Name:      fr.Node.Name,
+			LineRange: files.LineRange{Start: fr.Node.StartLine, End: fr.Node.EndLine},
+			Status:    files.FunctionUntested,
+			Tests:     []files.ReachingTest{},
+		}
+
+		for _, reach := range fr.Tests {
+			fc.Tests = append(fc.Tests, files.ReachingTest{
+				TestFile: reach.Test.TestFile,
+				TestName: reach.Test.TestName,
+				Depth:    reach.Depth,
+			})
+		}
+
+		switch {
+		case named[fr.Node.Name] || (len(fr.Tests) > 0 && fr.Tests[0].Depth == 1):
+			fc.Status = files.FunctionDirect
+		case len(fr.Tests) > 0:
+			fc.Status = files.FunctionIndirect
+			for line := fc.LineRange.Start; line <= fc.LineRange.End; line++ {
+				for _, test := range fc.Tests {
+					indirect[line] = append(indirect[line], test.TestName)
+				}
+			}
+		}
+
+		functions = append(functions, fc)
+	}
+
+	if len(indirect) == 0 {
+		indirect = nil
+	}
+	return functions, indirect
+}
+
+// lineMutations summarizes stored mutation runs per line
+func lineMutations(runs map[string]files.MutationRun) map[int]files.LineMutations {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	result := make(map[int]files.LineMutations)
+	for _, run := range runs {
+		for _, mutant := range run.Mutants {
+			line := result[mutant.Line]
+			switch mutant.Status {
+			case files.MutantKilled, files.MutantTimeout:
+				line.Killed++
+			case files.MutantSurvived:
+				line.Survived++
+				line.Survivors = append(line.Survivors, mutant.Original+" -> "+mutant.Mutated)
+			default:
+				continue
+			}
+			result[mutant.Line] = line
+		}
+	}
+
+	return result
+}
+
 // GetTests handles GET /api/files/{path}/tests
 func (h *Handler) GetTests(w http.ResponseWriter, r *http.Request) {
 	path := r.PathValue("path")
@@ -116,25 +319,29 @@ func (h *Handler) GetTests(w http.ResponseWriter, r *http.Request) {
 
 	// Build detailed test information
 	var testDetails []files.TestDetail
-	for _, testRef := range fileMeta.Tests {
+	for _, testRef := range relocate.New(h.fileService).ResolveTests(path, fileMeta.Tests) {
 		detail := files.TestDetail{
-			FunctionName: testRef.FunctionName,
-			TestFile:     testRef.TestFile,
-			TestName:     testRef.TestName,
-			Comment:      testRef.Comment,
-			LineRange:    testRef.LineRange,
-			CoveredLines: testRef.CoveredLines,
-			InputLines:   testRef.InputLines,
-			OutputLines:  testRef.OutputLines,
+			FunctionName:   testRef.FunctionName,
+			TestFile:       testRef.TestFile,
+			TestName:       testRef.TestName,
+			Comment:        testRef.Comment,
+			LineRange:      testRef.LineRange,
+			CoveredLines:   testRef.CoveredLines,
+			InputLines:     testRef.InputLines,
+			OutputLines:    testRef.OutputLines,
+			CoveredLineSet: testRef.CoveredLineSet,
+			Result:         h.metaStore.GetTestResult(testRef.TestFile, testRef.TestName),
+			Stale:          testRef.Stale,
+			StaleHint:      testRef.StaleHint,
 		}
 
 		// Read test file content
 		testContent, err := h.fileService.ReadFile(testRef.TestFile)
 		if err == nil {
-			detail.Content = testContent.Content
+			detail.Content, detail.Redactions = redact.Text(testRef.TestFile, testContent.Content)
 
 			// Extract input and output data if line ranges are provided
-			lines := strings.Split(testContent.Content, "\n")
+			lines := strings.Split(detail.Content, "\n")
 
 			if testRef.InputLines.Start > 0 && testRef.InputLines.End > 0 {
 				detail.InputData = extractLines(lines, testRef.InputLines.Start, testRef.InputLines.End)
@@ -143,6 +350,20 @@ func (h *Handler) GetTests(w http.ResponseWriter, r *http.Request) {
 			if testRef.OutputLines.Start > 0 && testRef.OutputLines.End > 0 {
 				detail.ExpectedOutput = extractLines(lines, testRef.OutputLines.Start, testRef.OutputLines.End)
 			}
+
+			detail.Cases = h.buildCaseDetails(testRef, lines)
+		}
+
+		detail.Fixtures = h.buildFixtureDetails(testRef.Fixtures)
+
+		// The corpus of a fuzz test is its input data
+		if strings.HasPrefix(testRef.TestName, "Fuzz") && strings.HasSuffix(testRef.TestFile, "_test.go") && filepath.IsLocal(testRef.TestFile) {
+			if corpus, err := fuzz.Load(h.fileService, testRef.TestFile, testRef.TestName); err == nil {
+				detail.Fuzz = corpus
+				if detail.InputData == "" {
+					detail.InputData = fuzz.Format(corpus)
+				}
+			}
 		}
 
 		testDetails = append(testDetails, detail)
@@ -160,6 +381,94 @@ func (h *Handler) GetTests(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// buildFixtureDetails reads the fixtures of a test for previewing
+func (h *Handler) buildFixtureDetails(fixtures []files.FixtureLink) []files.FixtureDetail {
+	var details []files.FixtureDetail
+	for _, fixture := range fixtures {
+		detail := files.FixtureDetail{
+			Path:   fixture.Path,
+			Line:   fixture.Line,
+			Golden: fixture.Golden,
+		}
+
+		// Only the previewed lines are read, however large the fixture
+		if content, err := h.fileService.ReadLines(fixture.Path, 1, fixturePreviewLines); err == nil {
+			detail.Exists = true
+			detail.Size = content.Size
+			detail.Binary = content.Binary
+			if !detail.Binary {
+				// Redact before cutting, so a secret spanning the byte
+				// limit is still recognised
+				text, redactions := redact.Text(fixture.Path, content.Content)
+				detail.Preview, detail.Truncated = previewText(text)
+				detail.Truncated = detail.Truncated || content.Truncated || content.EndLine < content.TotalLines
+				shown := strings.Count(detail.Preview, "\n") + 1
+				for _, redaction := range redactions {
+					if redaction.Line <= shown {
+						detail.Redactions = append(detail.Redactions, redaction)
+					}
+				}
+			}
+		}
+
+		details = append(details, detail)
+	}
+	return details
+}
+
+// Fixture previews show the leading lines of a file, up to a byte limit
+const (
+	fixturePreviewLines = 40
+	fixturePreviewBytes = 4096
+)
+
+// previewText returns the leading lines of a text and whether it was cut
+func previewText(text string) (string, bool) {
+	end := 0
+	for i := 0; i < fixturePreviewLines && end < len(text); i++ {
+		next := strings.IndexByte(text[end:], '\n')
+		if next < 0 {
+			end = len(text)
+			break
+		}
+		end += next + 1
+	}
+	if end > fixturePreviewBytes {
+		end = fixturePreviewBytes
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+	}
+	return text[:end], end < len(text)
+}
+
+// buildCaseDetails extracts the input and expected output of each test case
+func (h *Handler) buildCaseDetails(testRef files.TestReference, lines []string) []files.TestCaseDetail {
+	var cases []files.TestCaseDetail
+	for _, tc := range testRef.Cases {
+		detail := files.TestCaseDetail{
+			Name:        tc.Name,
+			LineRange:   tc.LineRange,
+			InputLines:  tc.InputLines,
+			OutputLines: tc.OutputLines,
+			// go test replaces spaces in subtest names with underscores
+			Result: h.metaStore.GetTestResult(testRef.TestFile, testRef.TestName+"/"+strings.ReplaceAll(tc.Name, " ", "_")),
+		}
+
+		if tc.InputLines.Start > 0 && tc.InputLines.End > 0 {
+			detail.InputData = extractLines(lines, tc.InputLines.Start, tc.InputLines.End)
+		}
+
+		if tc.OutputLines.Start > 0 && tc.OutputLines.End > 0 {
+			detail.ExpectedOutput = extractLines(lines, tc.OutputLines.Start, tc.OutputLines.End)
+		}
+
+		cases = append(cases, detail)
+	}
+
+	return cases
+}
+
 // GetSuggestions handles GET /api/files/{path}/suggestions
 func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
 	path := r.PathValue("path")
@@ -186,6 +495,568 @@ func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// RunTests handles POST /api/tests/run
+// Runs the selected tests and streams go test output as server-sent events:
+// "output" for each line, "result" when a test finishes, then "done" with the
+// recorded totals or "error"
+func (h *Handler) RunTests(w http.ResponseWriter, r *http.Request) {
+	if !h.executor.Enabled() {
+		http.Error(w, "running tests is disabled; start the server with -allow-test-run", http.StatusForbidden)
+		return
+	}
+
+	var req files.RunTestsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Tests) == 0 {
+		http.Error(w, "tests are required", http.StatusBadRequest)
+		return
+	}
+
+	for _, test := range req.Tests {
+		if !filepath.IsLocal(test.TestFile) || !h.executor.Allowed(path.Dir(test.TestFile)) {
+			http.Error(w, "tests in "+path.Dir(test.TestFile)+" are not allowed to run", http.StatusForbidden)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	rc := http.NewResponseController(w)
+
+	send := func(event string, data any) {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+		rc.Flush()
+	}
+
+	results, err := h.executor.Run(r.Context(), req.Tests, func(event testrun.Event) {
+		switch event.Action {
+		case "output", "build-output":
+			send("output", files.TestOutputEvent{Package: event.Package, Test: event.Test, Output: event.Output})
+		case "pass", "fail", "skip":
+			if event.Test != "" {
+				send("result", files.TestResultEvent{Package: event.Package, Test: event.Test, Status: event.Action, Elapsed: event.Elapsed})
+			}
+		}
+	})
+	if errors.Is(err, testrun.ErrBusy) {
+		send("error", map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Record whatever finished, even when the run was cut short
+	response, recordErr := testrun.Record(h.metaStore, h.fileService.BaseDir(), results)
+	switch {
+	case err != nil:
+		send("error", map[string]string{"error": err.Error()})
+	case recordErr != nil:
+		send("error", map[string]string{"error": recordErr.Error()})
+	default:
+		send("done", response)
+	}
+}
+
+// Events handles GET /api/events
+// Streams file changes under the base directory and metadata changes as
+// server-sent events named after the change type. The stream ends when the
+// client falls too far behind; EventSource clients reconnect on their own.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	if h.watcher == nil {
+		http.Error(w, "watching is disabled", http.StatusServiceUnavailable)
+		return
+	}
+
+	events, unsubscribe := h.watcher.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	rc := http.NewResponseController(w)
+
+	// Send the headers now, and a comment now and then to keep proxies from
+	// closing an idle stream
+	fmt.Fprint(w, ": connected\n\n")
+	rc.Flush()
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			rc.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+			rc.Flush()
+		}
+	}
+}
+
+// eventsKeepAlive is how often an idle event stream gets a comment
+const eventsKeepAlive = 30 * time.Second
+
+// ImportCoverage handles POST /api/coverage
+// The request body is the raw output of go test -coverprofile
+func (h *Handler) ImportCoverage(w http.ResponseWriter, r *http.Request) {
+	response, err := coverage.Import(h.metaStore, h.fileService.BaseDir(), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ImportTestResults handles POST /api/test-results
+// The request body is the raw output of go test -json
+func (h *Handler) ImportTestResults(w http.ResponseWriter, r *http.Request) {
+	response, err := testrun.Import(h.metaStore, h.fileService.BaseDir(), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetChangeImpact handles GET /api/changes?base=<rev>&head=<rev>
+// An empty head compares base with the working tree
+func (h *Handler) GetChangeImpact(w http.ResponseWriter, r *http.Request) {
+	base := r.URL.Query().Get("base")
+	if base == "" {
+		http.Error(w, "base is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := impact.Changes(r.Context(), h.metaStore, h.fileService, base, r.URL.Query().Get("head"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Raw handles GET /api/raw/{path}
+// Serves the bytes of a file as they are on disk, with range requests.
+// Binary files, and any file with ?download=true, are sent as attachments.
+func (h *Handler) Raw(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	if path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	file, info, err := h.fileService.Open(path)
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 8192)
+	n, _ := io.ReadFull(file, head)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	// Never let a browser run what it is served from the codebase
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	if files.IsBinary(head[:n]) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name()}))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if r.URL.Query().Get("download") == "true" {
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name()}))
+		}
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// Search handles GET /api/search
+// Finds the lines of the codebase matching a literal or regular expression
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	if h.search == nil {
+		http.Error(w, "search is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	q := search.Query{
+		Pattern:       query.Get("q"),
+		Regex:         query.Get("regex") == "true",
+		CaseSensitive: query.Get("case") == "true",
+		Path:          query.Get("path"),
+		Context:       search.DefaultContext,
+	}
+	if q.Pattern == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	for name, dst := range map[string]*int{"limit": &q.Limit, "context": &q.Context} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				http.Error(w, name+" must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			*dst = n
+		}
+	}
+
+	response, err := h.search.Search(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Find handles GET /api/find
+// Fuzzy-matches file paths and Go symbol names
+func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
+	if h.finder == nil {
+		http.Error(w, "finder is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	q := query.Get("q")
+	if strings.TrimSpace(q) == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	kind := query.Get("kind")
+	if kind != "" && kind != finder.KindFile && kind != finder.KindSymbol {
+		http.Error(w, "kind must be file or symbol", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	response := h.finder.Find(q, kind, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTestLines handles GET /api/tests/lines
+// Returns the input and output lines detected in a Go test for the function it tests
+func (h *Handler) GetTestLines(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	testFile, testName := query.Get("file"), query.Get("test")
+	if testFile == "" || testName == "" {
+		http.Error(w, "file and test are required", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasSuffix(testFile, "_test.go") {
+		http.Error(w, "file must be a Go test file", http.StatusBadRequest)
+		return
+	}
+	testPath, err := h.fileService.Resolve(testFile)
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+
+	detected, err := analysis.DetectLines(testPath, testName, query.Get("function"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(detected); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetFuzzCorpus handles GET /api/tests/fuzz
+// Returns the seed corpus and on-disk corpus of a fuzz test
+func (h *Handler) GetFuzzCorpus(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	testFile, testName := query.Get("file"), query.Get("test")
+	if testFile == "" || testName == "" {
+		http.Error(w, "file and test are required", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasSuffix(testFile, "_test.go") {
+		http.Error(w, "file must be a Go test file", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.fileService.Resolve(testFile); err != nil {
+		pathError(w, err)
+		return
+	}
+
+	corpus, err := fuzz.Load(h.fileService, testFile, testName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(corpus); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetTestImpact handles POST /api/impact
+// Returns the tests affected by the given changed files or line ranges
+func (h *Handler) GetTestImpact(w http.ResponseWriter, r *http.Request) {
+	var req files.ImpactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Files) == 0 && len(req.Changes) == 0 {
+		http.Error(w, "files or changes are required", http.StatusBadRequest)
+		return
+	}
+	paths := append([]string{}, req.Files...)
+	for _, change := range req.Changes {
+		paths = append(paths, change.Path)
+	}
+	for _, changed := range paths {
+		if _, err := h.fileService.Resolve(changed); err != nil {
+			pathError(w, err)
+			return
+		}
+	}
+
+	response, err := impact.Tests(h.metaStore, h.fileService, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// DiscoverTests handles POST /api/discover
+// Scans for Python and JavaScript tests and proposes test references,
+// optionally storing the ones that do not exist yet
+func (h *Handler) DiscoverTests(w http.ResponseWriter, r *http.Request) {
+	var req files.DiscoveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.fileService.Resolve(req.Path); err != nil {
+		pathError(w, err)
+		return
+	}
+
+	response, err := discovery.Scan(h.fileService, req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Apply {
+		applied, err := discovery.Apply(h.metaStore, response)
+		if err != nil {
+			http.Error(w, "failed to save discovered tests", http.StatusInternalServerError)
+			return
+		}
+		response.Applied = applied
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// GenerateSuggestions handles POST /api/suggestions/generate
+func (h *Handler) GenerateSuggestions(w http.ResponseWriter, r *http.Request) {
+	var req files.GenerateSuggestionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.fileService.Resolve(req.Path); err != nil {
+		pathError(w, err)
+		return
+	}
+
+	response, err := skeleton.Scan(h.metaStore, h.fileService.BaseDir(), req.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Apply {
+		applied, err := skeleton.Apply(h.metaStore, response)
+		if err != nil {
+			http.Error(w, "failed to save suggestions", http.StatusInternalServerError)
+			return
+		}
+		response.Applied = applied
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// CheckSuggestions handles POST /api/files/{path}/suggestions/check
+// Type-checks the skeleton of every suggestion for a Go source file and
+// stores the results
+func (h *Handler) CheckSuggestions(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	if path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+	if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+		http.Error(w, "only suggestions for Go source files can be checked", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.fileService.Resolve(path); err != nil {
+		pathError(w, err)
+		return
+	}
+
+	// Copy the stored suggestions before recording results on them
+	suggestions := append([]files.TestSuggestion{}, h.metaStore.GetSuggestions(path)...)
+
+	skeleton.CheckSuggestions(h.fileService.BaseDir(), suggestions)
+	if len(suggestions) > 0 {
+		if err := h.metaStore.AddSuggestions(path, suggestions); err != nil {
+			http.Error(w, "failed to save suggestions", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := files.SuggestionsResponse{
+		SourceFile:  path,
+		Suggestions: suggestions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// MaterializeSuggestion handles POST /api/files/{path}/suggestions/materialize
+// Returns a stored suggestion as a unified diff of its test file, and writes
+// it to the working tree when requested and allowed
+func (h *Handler) MaterializeSuggestion(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	if path == "" {
+		http.Error(w, "path is required", http.StatusBadRequest)
+		return
+	}
+
+	var req files.MaterializeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SuggestedName == "" {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if h.materializer == nil {
+		http.Error(w, "materializing suggestions is not available", http.StatusServiceUnavailable)
+		return
+	}
+	for _, p := range []string{path, strings.TrimSuffix(path, ".go") + "_test.go"} {
+		if _, err := h.fileService.Resolve(p); err != nil {
+			pathError(w, err)
+			return
+		}
+	}
+
+	response, err := h.materializer.MaterializeStored(h.metaStore, path, req.SuggestedName, req.Apply)
+	switch {
+	case errors.Is(err, skeleton.ErrApplyDisabled):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, skeleton.ErrNoSuggestion):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, skeleton.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil && response == nil:
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "failed to remove the applied suggestion", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // HandleMCP handles POST /api/mcp
 func (h *Handler) HandleMCP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -214,7 +1085,7 @@ func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comments := h.metaStore.GetComments(path)
+	comments := relocate.New(h.fileService).ResolveComments(path, h.metaStore.GetComments(path))
 	if comments == nil {
 		comments = []files.Comment{}
 	}
@@ -261,6 +1132,8 @@ func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		ContextLines: req.ContextLines,
 	}
 
+	comment = relocate.New(h.fileService).FingerprintComment(path, comment)
+
 	created, err := h.metaStore.AddComment(path, comment)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -364,18 +1237,22 @@ func (h *Handler) ExportContext(w http.ResponseWriter, r *http.Request) {
 	// Get file content
 	fileContent, err := h.fileService.ReadFile(path)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		pathError(w, err)
 		return
 	}
 
-	// Get comments
-	comments := h.metaStore.GetComments(path)
+	// Get comments and tests at their current position
+	relocator := relocate.New(h.fileService)
+	comments := relocator.ResolveComments(path, h.metaStore.GetComments(path))
 	if comments == nil {
 		comments = []files.Comment{}
 	}
 
+	// Secrets never leave in an export
+	content, redactions := redact.Text(path, fileContent.Content)
+
 	// Build code context blocks
-	lines := strings.Split(fileContent.Content, "\n")
+	lines := strings.Split(content, "\n")
 	var codeBlocks []files.CodeContextBlock
 
 	for _, comment := range comments {
@@ -410,26 +1287,31 @@ func (h *Handler) ExportContext(w http.ResponseWriter, r *http.Request) {
 	response := files.ExportContextResponse{
 		SourceFile:  path,
 		CodeContext: codeBlocks,
+		Redactions:  redactions,
 	}
 
 	// Include tests if requested
 	if req.IncludeTests {
 		testsMeta := h.metaStore.GetTestMetadata(path)
 		if testsMeta != nil {
-			for _, testRef := range testsMeta.Tests {
+			for _, testRef := range relocator.ResolveTests(path, testsMeta.Tests) {
 				detail := files.TestDetail{
-					FunctionName: testRef.FunctionName,
-					TestFile:     testRef.TestFile,
-					TestName:     testRef.TestName,
-					Comment:      testRef.Comment,
-					LineRange:    testRef.LineRange,
-					CoveredLines: testRef.CoveredLines,
+					FunctionName:   testRef.FunctionName,
+					TestFile:       testRef.TestFile,
+					TestName:       testRef.TestName,
+					Comment:        testRef.Comment,
+					LineRange:      testRef.LineRange,
+					CoveredLines:   testRef.CoveredLines,
+					CoveredLineSet: testRef.CoveredLineSet,
+					Stale:          testRef.Stale,
+					StaleHint:      testRef.StaleHint,
 				}
 
 				testContent, err := h.fileService.ReadFile(testRef.TestFile)
 				if err == nil {
-					detail.Content = testContent.Content
-					lines := strings.Split(testContent.Content, "\n")
+					detail.Content, detail.Redactions = redact.Text(testRef.TestFile, testContent.Content)
+					response.Redactions = append(response.Redactions, detail.Redactions...)
+					lines := strings.Split(detail.Content, "\n")
 					if testRef.InputLines.Start > 0 && testRef.InputLines.End > 0 {
 						detail.InputData = extractLines(lines, testRef.InputLines.Start, testRef.InputLines.End)
 					}
@@ -486,6 +1368,9 @@ func buildFormattedExport(data files.ExportContextResponse) string {
 			if test.Comment != "" {
 				b.WriteString(fmt.Sprintf("- **Description:** %s\n", test.Comment))
 			}
+			if test.Stale {
+				b.WriteString(fmt.Sprintf("- **Stale:** %s\n", test.StaleHint))
+			}
 			b.WriteString("\n")
 		}
 	}
@@ -505,6 +1390,14 @@ func buildFormattedExport(data files.ExportContextResponse) string {
 		}
 	}
 
+	if len(data.Redactions) > 0 {
+		b.WriteString("## Redacted Secrets\n\n")
+		for _, r := range data.Redactions {
+			b.WriteString(fmt.Sprintf("- `%s` line %d: %s\n", r.Path, r.Line, r.Kind))
+		}
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
 
@@ -2,14 +2,38 @@ package api
 
 import (
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
-// CORS middleware adds CORS headers for development
-func CORS(next http.Handler) http.Handler {
+// CORS middleware adds CORS headers for development. With localOnly set, as
+// when the server may run tests or write files, only requests addressed to
+// localhost are answered and only localhost origins are allowed; requests
+// from any other origin are refused, since browsers send simple cross-origin
+// POSTs without a preflight. Checking the Host header as well defeats DNS
+// rebinding, where a foreign page's own name resolves to the loopback address.
+func CORS(next http.Handler, localOnly bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := r.Header.Get("Origin")
+		if localOnly {
+			w.Header().Add("Vary", "Origin")
+			if !localHost(r.Host) {
+				http.Error(w, "host not allowed", http.StatusForbidden)
+				return
+			}
+			if origin != "" && !localOrigin(origin) {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			if origin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
@@ -23,6 +47,29 @@ func CORS(next http.Handler) http.Handler {
 	})
 }
 
+// localOrigin reports whether an Origin header names a page served from
+// localhost
+func localOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return localHost(u.Host)
+}
+
+// localHost reports whether a host, with or without a port, is localhost or
+// a loopback address
+func localHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	switch strings.Trim(host, "[]") {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
 // Logging middleware logs all HTTP requests
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -53,3 +100,9 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.statusCode = statusCode
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Unwrap exposes the underlying writer to http.ResponseController (used to
+// flush server-sent events)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -9,6 +9,16 @@ func SetupRoutes(h *Handler) *http.ServeMux {
 	// File operations
 	mux.HandleFunc("GET /api/files", h.ListFiles)
 	mux.HandleFunc("GET /api/files/{path...}", h.GetFileOrTests)
+	mux.HandleFunc("GET /api/raw/{path...}", h.Raw)
+
+	// Code search
+	mux.HandleFunc("GET /api/search", h.Search)
+
+	// Live file and metadata changes
+	mux.HandleFunc("GET /api/events", h.Events)
+
+	// Fuzzy file and symbol finder
+	mux.HandleFunc("GET /api/find", h.Find)
 
 	// Comment operations
 	mux.HandleFunc("GET /api/files/{path}/comments", h.GetComments)
@@ -20,6 +30,35 @@ func SetupRoutes(h *Handler) *http.ServeMux {
 	// Export for AI agents
 	mux.HandleFunc("POST /api/files/{path}/export", h.ExportContext)
 
+	// Coverage import
+	mux.HandleFunc("POST /api/coverage", h.ImportCoverage)
+
+	// Test result import
+	mux.HandleFunc("POST /api/test-results", h.ImportTestResults)
+
+	// Change impact for a git revision range
+	mux.HandleFunc("GET /api/changes", h.GetChangeImpact)
+
+	// Tests affected by a change
+	mux.HandleFunc("POST /api/impact", h.GetTestImpact)
+
+	// Input and output lines detected in a Go test
+	mux.HandleFunc("GET /api/tests/lines", h.GetTestLines)
+
+	// Seed and on-disk corpus of a fuzz test
+	mux.HandleFunc("GET /api/tests/fuzz", h.GetFuzzCorpus)
+
+	// Run tests, streaming output as server-sent events
+	mux.HandleFunc("POST /api/tests/run", h.RunTests)
+
+	// Test discovery for Python and JavaScript projects
+	mux.HandleFunc("POST /api/discover", h.DiscoverTests)
+
+	// Test skeleton suggestions for untested Go functions
+	mux.HandleFunc("POST /api/suggestions/generate", h.GenerateSuggestions)
+	mux.HandleFunc("POST /api/files/{path}/suggestions/check", h.CheckSuggestions)
+	mux.HandleFunc("POST /api/files/{path}/suggestions/materialize", h.MaterializeSuggestion)
+
 	// MCP endpoint
 	mux.HandleFunc("POST /api/mcp", h.HandleMCP)
 
@@ -0,0 +1,157 @@
+package callgraph
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// checkInterval is how often the sources are checked for changes that were
+// not reported through Invalidate
+const checkInterval = 2 * time.Second
+
+// Cache keeps the call graph of a base directory. The graph is built in the
+// background from creation on and rebuilt when a Go source file or go.mod is
+// added, removed or modified; until a rebuild completes, the last built graph
+// is served.
+type Cache struct {
+	baseDir string
+	ready   chan struct{} // closed once the first build completed
+
+	mu       sync.Mutex
+	stamp    string
+	graph    *Graph
+	err      error // of the first build, while no graph was built
+	building bool
+	again    bool      // the sources were reported changed during a build
+	checked  time.Time // when a check for changes was last started
+}
+
+// NewCache creates a call graph cache for baseDir and starts building its
+// graph in the background
+func NewCache(baseDir string) *Cache {
+	c := &Cache{baseDir: baseDir, ready: make(chan struct{})}
+	c.Invalidate()
+	return c
+}
+
+// Graph returns the last built call graph without waiting for a build. It
+// returns a nil graph and error while the first build runs, and checks for
+// changes in the background at most every checkInterval.
+func (c *Cache) Graph() (*Graph, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.checked) >= checkInterval {
+		c.checked = time.Now()
+		c.rebuildLocked()
+	}
+	return c.graph, c.err
+}
+
+// Ready returns a channel closed once the first build completed
+func (c *Cache) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// Invalidate reports that the sources may have changed, so the graph is
+// checked and rebuilt in the background right away
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = time.Now()
+	c.rebuildLocked()
+}
+
+// rebuildLocked starts a background rebuild, or has the running one check
+// the sources again when it is done. The caller holds c.mu.
+func (c *Cache) rebuildLocked() {
+	if c.building {
+		c.again = true
+		return
+	}
+	c.building = true
+	go c.rebuild()
+}
+
+// rebuild builds the graph when the sources changed since the last build,
+// until no more changes were reported meanwhile. A failed build keeps the
+// last graph; its error is only returned while there is none.
+func (c *Cache) rebuild() {
+	for {
+		c.mu.Lock()
+		built := c.stamp
+		c.mu.Unlock()
+
+		stamp, err := sourceStamp(c.baseDir)
+		var graph *Graph
+		if err == nil && stamp != built {
+			graph, err = Load(c.baseDir)
+		}
+
+		c.mu.Lock()
+		switch {
+		case graph != nil:
+			c.graph, c.err, c.stamp = graph, nil, stamp
+		case err != nil:
+			// Sources that fail to load are not retried until they change
+			if stamp != "" {
+				c.stamp = stamp
+			}
+			if c.graph == nil {
+				c.err = err
+			}
+		}
+		if !c.again {
+			c.building = false
+			c.mu.Unlock()
+			break
+		}
+		c.again = false
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-c.ready:
+	default:
+		close(c.ready)
+	}
+}
+
+// sourceStamp hashes the names, sizes and modification times of the Go
+// sources Load compiles, ignored ones included, without reading them
+func sourceStamp(baseDir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != baseDir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(p, ".go") && d.Name() != "go.mod" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,157 @@
+package callgraph
+
+import (
+	"go/ast"
+	"sort"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+)
+
+// Node is a function or method declared in a module under the base directory
+type Node struct {
+	ID        string // types.Func full name, e.g. "example.com/pkg.(*T).Method"
+	File      string // relative to the base directory
+	Name      string // "Receiver.Method" or "Func"
+	StartLine int
+	EndLine   int
+	Test      bool // Test, Benchmark or Fuzz function in a _test.go file
+}
+
+// Reach is a test that reaches a function through the call graph
+type Reach struct {
+	Test  files.TestID
+	Depth int // 1 when the test calls the function itself
+}
+
+// FunctionReach lists the tests reaching one function, nearest first
+type FunctionReach struct {
+	Node  *Node
+	Tests []Reach
+}
+
+// Graph is a static call graph: an edge means a function calls or references
+// another one. Calls through interfaces and function values received as
+// parameters are not followed.
+type Graph struct {
+	nodes   map[string]*Node
+	callees map[string]map[string]bool
+	byFile  map[string][]*Node
+	reach   map[string]map[files.TestID]int
+}
+
+// newGraph creates an empty graph
+func newGraph() *Graph {
+	return &Graph{
+		nodes:   make(map[string]*Node),
+		callees: make(map[string]map[string]bool),
+		byFile:  make(map[string][]*Node),
+		reach:   make(map[string]map[files.TestID]int),
+	}
+}
+
+// addNode records a declared function
+func (g *Graph) addNode(node *Node) {
+	if _, exists := g.nodes[node.ID]; exists {
+		return
+	}
+	g.nodes[node.ID] = node
+	g.byFile[node.File] = append(g.byFile[node.File], node)
+}
+
+// addEdge records that caller calls or references callee
+func (g *Graph) addEdge(caller, callee string) {
+	if caller == callee {
+		return
+	}
+	if g.callees[caller] == nil {
+		g.callees[caller] = make(map[string]bool)
+	}
+	g.callees[caller][callee] = true
+}
+
+// computeReach walks the graph breadth first from every test and keeps the
+// minimal call depth at which each function is reached
+func (g *Graph) computeReach() {
+	for _, test := range g.nodes {
+		if !test.Test {
+			continue
+		}
+
+		id := files.TestID{TestFile: test.File, TestName: test.Name}
+		depth := map[string]int{test.ID: 0}
+		queue := []string{test.ID}
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			for callee := range g.callees[current] {
+				if _, seen := depth[callee]; seen {
+					continue
+				}
+				// Only functions declared in the module are part of the graph
+				if _, ok := g.nodes[callee]; !ok {
+					continue
+				}
+				depth[callee] = depth[current] + 1
+				queue = append(queue, callee)
+			}
+		}
+
+		for node, d := range depth {
+			if d == 0 {
+				continue
+			}
+			if g.reach[node] == nil {
+				g.reach[node] = make(map[files.TestID]int)
+			}
+			g.reach[node][id] = d
+		}
+	}
+}
+
+// FileReach returns the functions declared in a file, ordered by position,
+// with the tests that reach each of them
+func (g *Graph) FileReach(filePath string) []FunctionReach {
+	nodes := append([]*Node{}, g.byFile[filePath]...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].StartLine < nodes[j].StartLine
+	})
+
+	result := make([]FunctionReach, 0, len(nodes))
+	for _, node := range nodes {
+		fr := FunctionReach{Node: node, Tests: []Reach{}}
+		for test, depth := range g.reach[node.ID] {
+			fr.Tests = append(fr.Tests, Reach{Test: test, Depth: depth})
+		}
+		sort.Slice(fr.Tests, func(i, j int) bool {
+			a, b := fr.Tests[i], fr.Tests[j]
+			if a.Depth != b.Depth {
+				return a.Depth < b.Depth
+			}
+			if a.Test.TestFile != b.Test.TestFile {
+				return a.Test.TestFile < b.Test.TestFile
+			}
+			return a.Test.TestName < b.Test.TestName
+		})
+		result = append(result, fr)
+	}
+
+	return result
+}
+
+// qualifiedName names a declaration the way analysis.Function does
+func qualifiedName(fn *ast.FuncDecl) string {
+	return analysis.Function{Name: fn.Name.Name, Receiver: analysis.ReceiverName(fn)}.QualifiedName()
+}
+
+// isTestEntry reports whether a declaration is run by go test on its own
+func isTestEntry(fn *ast.FuncDecl) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz"} {
+		if analysis.IsTestFunc(fn, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package callgraph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var fixture = map[string]string{
+	"go.mod": "module example.com/shop\n\ngo 1.22\n",
+	"cart/cart.go": `package cart
+
+import "example.com/shop/price"
+
+type Cart struct {
+	items []int
+}
+
+func (c *Cart) Add(cents int) {
+	c.items = append(c.items, cents)
+}
+
+func (c *Cart) Total() int {
+	return price.Sum(c.items)
+}
+
+func unused() {}
+`,
+	"price/price.go": `package price
+
+func Sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total = add(total, v)
+	}
+	return total
+}
+
+func add(a, b int) int {
+	return a + b
+}
+`,
+	"cart/cart_test.go": `package cart_test
+
+import (
+	"testing"
+
+	"example.com/shop/cart"
+)
+
+func TestTotal(t *testing.T) {
+	var c cart.Cart
+	c.Add(2)
+	if got := c.Total(); got != 2 {
+		t.Fatalf("Total() = %d, want 2", got)
+	}
+}
+`,
+}
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, fixture)
+
+	graph, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	depths := func(file string) map[string]int {
+		result := make(map[string]int)
+		for _, fr := range graph.FileReach(file) {
+			result[fr.Node.Name] = -1
+			for _, reach := range fr.Tests {
+				if reach.Test.TestName == "TestTotal" && reach.Test.TestFile == "cart/cart_test.go" {
+					result[fr.Node.Name] = reach.Depth
+				}
+			}
+		}
+		return result
+	}
+
+	t.Run("methods", func(t *testing.T) {
+		got := depths("cart/cart.go")
+		want := map[string]int{"Cart.Add": 1, "Cart.Total": 1, "unused": -1}
+		for name, depth := range want {
+			if got[name] != depth {
+				t.Fatalf("depth of %s = %d, want %d", name, got[name], depth)
+			}
+		}
+	})
+
+	t.Run("transitive", func(t *testing.T) {
+		got := depths("price/price.go")
+		if got["Sum"] != 2 || got["add"] != 3 {
+			t.Fatalf("depths = %v, want Sum 2, add 3", got)
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		dir := t.TempDir()
+		sources := map[string]string{
+			"go.mod":      "module example.com/gen\n\ngo 1.22\n",
+			".gitignore":  "gen.go\n",
+			"run.go":      "package gen\n\nfunc Run() int {\n\treturn helper()\n}\n\nfunc add() int {\n\treturn 1\n}\n",
+			"gen.go":      "package gen\n\nfunc helper() int {\n\treturn add()\n}\n",
+			"run_test.go": "package gen\n\nimport \"testing\"\n\nfunc TestRun(t *testing.T) {\n\tRun()\n}\n",
+		}
+		writeFiles(t, dir, sources)
+
+		graph, err := Load(dir)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		// Ignored files are compiled, so calls through them are followed,
+		// but their functions are not reported
+		if got := graph.FileReach("gen.go"); len(got) != 0 {
+			t.Fatalf("FileReach(gen.go) = %v, want none", got)
+		}
+		depth := -1
+		for _, fr := range graph.FileReach("run.go") {
+			if fr.Node.Name == "add" && len(fr.Tests) > 0 {
+				depth = fr.Tests[0].Depth
+			}
+		}
+		if depth != 3 {
+			t.Fatalf("depth of add = %d, want 3", depth)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		cache := NewCache(dir)
+		<-cache.Ready()
+		first, err := cache.Graph()
+		if err != nil {
+			t.Fatalf("Graph() error = %v", err)
+		}
+		second, _ := cache.Graph()
+		if first != second {
+			t.Fatal("Graph() rebuilt without source changes")
+		}
+
+		source := filepath.Join(dir, "price", "price.go")
+		if err := os.WriteFile(source, []byte(fixture["price/price.go"]+"\nfunc Max(a, b int) int {\n\treturn max(a, b)\n}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cache.Invalidate()
+
+		// The last graph is served until the rebuild completes
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			graph, err := cache.Graph()
+			if err != nil {
+				t.Fatalf("Graph() error = %v", err)
+			}
+			if graph != first {
+				names := make(map[string]bool)
+				for _, fr := range graph.FileReach("price/price.go") {
+					names[fr.Node.Name] = true
+				}
+				if !names["Max"] {
+					t.Fatalf("rebuilt graph lacks Max: %v", names)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Graph() was not rebuilt after Invalidate()")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
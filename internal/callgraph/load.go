@@ -0,0 +1,266 @@
+package callgraph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// pkgFiles holds the parsed files of one package directory, split the way
+// go test compiles them
+type pkgFiles struct {
+	importPath string
+	prod       []*ast.File
+	tests      []*ast.File // _test.go files of the same package
+	xtests     []*ast.File // _test.go files of the external _test package
+}
+
+// loader type-checks module packages from source. Packages outside the
+// modules under the base directory are imported through the source importer.
+type loader struct {
+	baseDir  string
+	fset     *token.FileSet
+	packages map[string]*pkgFiles
+	checked  map[string]*types.Package
+	loading  map[string]bool
+	external types.ImporterFrom
+}
+
+// Load parses and type-checks every Go package under baseDir, test files
+// included, and returns the static call graph of the module functions.
+// Ignored files are compiled like any other, as go build does, but their
+// functions are not reported.
+func Load(baseDir string) (*Graph, error) {
+	resolver, err := gomod.NewResolver(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	l := &loader{
+		baseDir:  baseDir,
+		fset:     fset,
+		packages: make(map[string]*pkgFiles),
+		checked:  make(map[string]*types.Package),
+		loading:  make(map[string]bool),
+		external: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+	}
+
+	if err := l.parse(resolver); err != nil {
+		return nil, err
+	}
+
+	g := newGraph()
+	for _, pkg := range l.packages {
+		l.addPackage(g, pkg)
+	}
+	g.computeReach()
+
+	ignored := ignore.For(baseDir)
+	for file := range g.byFile {
+		if ignored.Ignored(file, false) {
+			delete(g.byFile, file)
+		}
+	}
+
+	return g, nil
+}
+
+// parse walks baseDir and parses the Go files of every package that belongs
+// to a module, honouring build constraints for the current platform
+func (l *loader) parse(resolver *gomod.Resolver) error {
+	return filepath.WalkDir(l.baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != l.baseDir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(d.Name(), ".go") {
+			return nil
+		}
+
+		dir := filepath.Dir(p)
+		if match, err := build.Default.MatchFile(dir, d.Name()); err != nil || !match {
+			return nil
+		}
+
+		relDir, err := filepath.Rel(l.baseDir, dir)
+		if err != nil {
+			return nil
+		}
+		importPath, ok := resolver.PackagePath(filepath.ToSlash(relDir))
+		if !ok {
+			return nil
+		}
+
+		file, err := parser.ParseFile(l.fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+
+		pkg := l.packages[importPath]
+		if pkg == nil {
+			pkg = &pkgFiles{importPath: importPath}
+			l.packages[importPath] = pkg
+		}
+
+		switch {
+		case !strings.HasSuffix(d.Name(), "_test.go"):
+			pkg.prod = append(pkg.prod, file)
+		case strings.HasSuffix(file.Name.Name, "_test"):
+			pkg.xtests = append(pkg.xtests, file)
+		default:
+			pkg.tests = append(pkg.tests, file)
+		}
+
+		return nil
+	})
+}
+
+// Import implements types.Importer
+func (l *loader) Import(path string) (*types.Package, error) {
+	return l.ImportFrom(path, l.baseDir, 0)
+}
+
+// ImportFrom implements types.ImporterFrom. Module packages are checked
+// without their test files, as other packages see them.
+func (l *loader) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	pkg, ok := l.packages[path]
+	if !ok {
+		return l.external.ImportFrom(path, dir, mode)
+	}
+
+	if checked, ok := l.checked[path]; ok {
+		return checked, nil
+	}
+	if l.loading[path] {
+		return nil, fmt.Errorf("import cycle through %s", path)
+	}
+
+	l.loading[path] = true
+	defer delete(l.loading, path)
+
+	checked, _ := l.check(path, pkg.prod, nil, l)
+	l.checked[path] = checked
+	return checked, nil
+}
+
+// check type-checks a set of files as one package. Type errors are ignored
+// so that a partially broken package still yields call edges.
+func (l *loader) check(path string, parsed []*ast.File, info *types.Info, imp types.Importer) (*types.Package, error) {
+	conf := types.Config{
+		Importer: imp,
+		Error:    func(error) {},
+	}
+	return conf.Check(path, l.fset, parsed, info)
+}
+
+// addPackage checks a package together with its tests and adds the
+// declared functions and their call edges to the graph
+func (l *loader) addPackage(g *Graph, pkg *pkgFiles) {
+	info := newInfo()
+	parsed := append(append([]*ast.File{}, pkg.prod...), pkg.tests...)
+
+	var withTests *types.Package
+	if len(parsed) > 0 {
+		withTests, _ = l.check(pkg.importPath, parsed, info, l)
+		l.addFiles(g, parsed, info)
+	}
+
+	if len(pkg.xtests) == 0 {
+		return
+	}
+
+	// The external test package sees the package under test with its
+	// internal test files, which may export helpers for it
+	xinfo := newInfo()
+	l.check(pkg.importPath+"_test", pkg.xtests, xinfo, importerFunc(func(path string) (*types.Package, error) {
+		if path == pkg.importPath && withTests != nil {
+			return withTests, nil
+		}
+		return l.Import(path)
+	}))
+	l.addFiles(g, pkg.xtests, xinfo)
+}
+
+// addFiles records the function declarations of checked files and the
+// module functions each one calls or references
+func (l *loader) addFiles(g *Graph, parsed []*ast.File, info *types.Info) {
+	for _, file := range parsed {
+		filename := l.fset.Position(file.Pos()).Filename
+		rel, err := filepath.Rel(l.baseDir, filename)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		isTestFile := strings.HasSuffix(rel, "_test.go")
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Name.Name == "init" {
+				continue
+			}
+			obj, ok := info.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+
+			node := &Node{
+				ID:        obj.FullName(),
+				File:      rel,
+				Name:      qualifiedName(fn),
+				StartLine: l.fset.Position(fn.Pos()).Line,
+				EndLine:   l.fset.Position(fn.End()).Line,
+				Test:      isTestFile && isTestEntry(fn),
+			}
+			g.addNode(node)
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				id, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				if callee, ok := info.Uses[id].(*types.Func); ok {
+					g.addEdge(node.ID, callee.Origin().FullName())
+				}
+				return true
+			})
+		}
+	}
+}
+
+// newInfo returns the type information recorded for call graph construction
+func newInfo() *types.Info {
+	return &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+}
+
+// importerFunc adapts a function to types.Importer
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
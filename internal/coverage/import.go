@@ -0,0 +1,58 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// Import parses a Go cover profile and stores per-file coverage for every
+// file that lives under baseDir
+func Import(store *metadata.Store, baseDir string, r io.Reader) (*files.CoverageImportResponse, error) {
+	profile, err := ParseProfile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := gomod.NewResolver(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	coverage := make(map[string]*files.FileCoverage)
+	response := &files.CoverageImportResponse{
+		Mode:  profile.Mode,
+		Files: []string{},
+	}
+
+	for name, blocks := range profile.Blocks {
+		relPath, ok := resolver.RelativePath(name)
+		if !ok {
+			response.Skipped = append(response.Skipped, name)
+			continue
+		}
+
+		coverage[relPath] = &files.FileCoverage{
+			Mode:       profile.Mode,
+			Blocks:     blocks,
+			ImportedAt: now,
+		}
+		response.Files = append(response.Files, relPath)
+		response.Blocks += len(blocks)
+	}
+
+	if err := store.ImportCoverage(coverage); err != nil {
+		return nil, fmt.Errorf("failed to store coverage: %w", err)
+	}
+
+	sort.Strings(response.Files)
+	sort.Strings(response.Skipped)
+
+	return response, nil
+}
@@ -0,0 +1,192 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// Profile is a parsed Go cover profile
+type Profile struct {
+	Mode   string
+	Blocks map[string][]files.CoverageBlock // key: file name as written in the profile
+}
+
+// ParseProfile parses the output of go test -coverprofile.
+// Blocks reported more than once (e.g. by several packages) are merged.
+func ParseProfile(r io.Reader) (*Profile, error) {
+	profile := &Profile{
+		Blocks: make(map[string][]files.CoverageBlock),
+	}
+
+	type blockKey struct {
+		file                                 string
+		startLine, startCol, endLine, endCol int
+	}
+	seen := make(map[blockKey]int) // key -> index in profile.Blocks[file]
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "mode:") {
+			// Concatenated profiles repeat the mode line; the first one wins
+			if profile.Mode == "" {
+				profile.Mode = strings.TrimSpace(strings.TrimPrefix(line, "mode:"))
+			}
+			continue
+		}
+
+		if profile.Mode == "" {
+			return nil, fmt.Errorf("line %d: missing mode line", lineNum)
+		}
+
+		name, block, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		key := blockKey{name, block.StartLine, block.StartCol, block.EndLine, block.EndCol}
+		if idx, ok := seen[key]; ok {
+			existing := &profile.Blocks[name][idx]
+			if profile.Mode == "set" {
+				if block.Count > 0 {
+					existing.Count = 1
+				}
+			} else {
+				existing.Count += block.Count
+			}
+			continue
+		}
+
+		seen[key] = len(profile.Blocks[name])
+		profile.Blocks[name] = append(profile.Blocks[name], block)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	if profile.Mode == "" {
+		return nil, fmt.Errorf("empty cover profile")
+	}
+
+	for name := range profile.Blocks {
+		blocks := profile.Blocks[name]
+		sort.Slice(blocks, func(i, j int) bool {
+			if blocks[i].StartLine != blocks[j].StartLine {
+				return blocks[i].StartLine < blocks[j].StartLine
+			}
+			return blocks[i].StartCol < blocks[j].StartCol
+		})
+	}
+
+	return profile, nil
+}
+
+// parseBlock parses a "name.go:line.col,line.col numStmts count" profile line
+func parseBlock(line string) (string, files.CoverageBlock, error) {
+	var block files.CoverageBlock
+
+	colon := strings.LastIndexByte(line, ':')
+	if colon < 0 {
+		return "", block, fmt.Errorf("malformed block %q", line)
+	}
+	name := line[:colon]
+
+	fields := strings.Fields(line[colon+1:])
+	if len(fields) != 3 {
+		return "", block, fmt.Errorf("malformed block %q", line)
+	}
+
+	start, end, ok := strings.Cut(fields[0], ",")
+	if !ok {
+		return "", block, fmt.Errorf("malformed position %q", fields[0])
+	}
+
+	var err error
+	if block.StartLine, block.StartCol, err = parsePosition(start); err != nil {
+		return "", block, err
+	}
+	if block.EndLine, block.EndCol, err = parsePosition(end); err != nil {
+		return "", block, err
+	}
+	if block.NumStmts, err = strconv.Atoi(fields[1]); err != nil {
+		return "", block, fmt.Errorf("invalid statement count %q", fields[1])
+	}
+	if block.Count, err = strconv.Atoi(fields[2]); err != nil {
+		return "", block, fmt.Errorf("invalid hit count %q", fields[2])
+	}
+
+	return name, block, nil
+}
+
+// parsePosition parses a "line.col" position
+func parsePosition(pos string) (int, int, error) {
+	lineStr, colStr, ok := strings.Cut(pos, ".")
+	if !ok {
+		return 0, 0, fmt.Errorf("malformed position %q", pos)
+	}
+
+	line, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed position %q", pos)
+	}
+
+	col, err := strconv.Atoi(colStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed position %q", pos)
+	}
+
+	return line, col, nil
+}
+
+// Lines computes per-line coverage from a file's blocks. A line's hit count
+// is the highest count of any block spanning it.
+func Lines(coverage *files.FileCoverage) *files.LineCoverage {
+	if coverage == nil {
+		return nil
+	}
+
+	hits := make(map[int]int)
+	for _, block := range coverage.Blocks {
+		if block.NumStmts == 0 {
+			continue
+		}
+		for line := block.StartLine; line <= block.EndLine; line++ {
+			if count, ok := hits[line]; !ok || block.Count > count {
+				hits[line] = block.Count
+			}
+		}
+	}
+
+	result := &files.LineCoverage{
+		CoveredLines:   []int{},
+		UncoveredLines: []int{},
+		Hits:           hits,
+	}
+
+	for line, count := range hits {
+		if count > 0 {
+			result.CoveredLines = append(result.CoveredLines, line)
+		} else {
+			result.UncoveredLines = append(result.UncoveredLines, line)
+		}
+	}
+	sort.Ints(result.CoveredLines)
+	sort.Ints(result.UncoveredLines)
+
+	return result
+}
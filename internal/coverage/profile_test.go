@@ -0,0 +1,73 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func TestParseProfile(t *testing.T) {
+	t.Run("parses and merges blocks", func(t *testing.T) {
+		input := `mode: count
+example.com/m/a.go:3.14,5.2 2 1
+example.com/m/a.go:7.10,9.2 1 0
+mode: count
+example.com/m/a.go:3.14,5.2 2 4
+`
+		profile, err := ParseProfile(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+
+		if profile.Mode != "count" {
+			t.Fatalf("mode = %q, want %q", profile.Mode, "count")
+		}
+
+		blocks := profile.Blocks["example.com/m/a.go"]
+		if len(blocks) != 2 {
+			t.Fatalf("blocks count = %d, want %d", len(blocks), 2)
+		}
+
+		want := files.CoverageBlock{StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmts: 2, Count: 5}
+		if blocks[0] != want {
+			t.Fatalf("first block = %+v, want %+v", blocks[0], want)
+		}
+	})
+
+	t.Run("rejects blocks before mode line", func(t *testing.T) {
+		if _, err := ParseProfile(strings.NewReader("a.go:1.1,2.2 1 1\n")); err == nil {
+			t.Fatal("expected error for missing mode line")
+		}
+	})
+
+	t.Run("rejects malformed blocks", func(t *testing.T) {
+		if _, err := ParseProfile(strings.NewReader("mode: set\na.go:1.1 1 1\n")); err == nil {
+			t.Fatal("expected error for malformed block")
+		}
+	})
+}
+
+func TestLines(t *testing.T) {
+	coverage := &files.FileCoverage{
+		Mode: "count",
+		Blocks: []files.CoverageBlock{
+			{StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmts: 2, Count: 3},
+			{StartLine: 5, StartCol: 2, EndLine: 6, EndCol: 3, NumStmts: 1, Count: 0},
+		},
+	}
+
+	lines := Lines(coverage)
+
+	if got := lines.Hits[5]; got != 3 {
+		t.Fatalf("hits[5] = %d, want %d", got, 3)
+	}
+
+	if len(lines.CoveredLines) != 3 || lines.CoveredLines[0] != 3 {
+		t.Fatalf("coveredLines = %v, want [3 4 5]", lines.CoveredLines)
+	}
+
+	if len(lines.UncoveredLines) != 1 || lines.UncoveredLines[0] != 6 {
+		t.Fatalf("uncoveredLines = %v, want [6]", lines.UncoveredLines)
+	}
+}
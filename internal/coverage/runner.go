@@ -0,0 +1,304 @@
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// Runner measures which source lines each test covers by running every
+// test of a package on its own with -coverprofile
+type Runner struct {
+	store    *metadata.Store
+	baseDir  string
+	Parallel int    // number of concurrent go test processes
+	CoverPkg string // -coverpkg pattern; defaults to the package under test
+}
+
+// TestRun reports the outcome of one isolated test run
+type TestRun struct {
+	TestName string
+	TestFile string
+	Cached   bool
+	Files    map[string]int // source file -> number of covered lines
+	Err      error
+}
+
+// NewRunner creates a coverage runner for the codebase under baseDir
+func NewRunner(store *metadata.Store, baseDir string) *Runner {
+	return &Runner{
+		store:    store,
+		baseDir:  baseDir,
+		Parallel: runtime.NumCPU(),
+	}
+}
+
+// RunPackage runs every test in the package directory relDir in isolation and
+// stores the covered lines of each test. Tests whose stored measurements were
+// taken against identical module sources are skipped.
+func (r *Runner) RunPackage(ctx context.Context, relDir string) ([]TestRun, error) {
+	resolver, err := gomod.NewResolver(r.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	module, ok := resolver.ModuleFor(relDir)
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside a Go module", relDir)
+	}
+	pkgPath, _ := resolver.PackagePath(relDir)
+
+	tests, err := analysis.DiscoverTests(r.baseDir, relDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover tests: %w", err)
+	}
+
+	hash, err := hashModule(filepath.Join(r.baseDir, module.Dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash module sources: %w", err)
+	}
+
+	coverPkg := r.CoverPkg
+	if coverPkg == "" {
+		coverPkg = pkgPath
+	}
+
+	runs := make([]TestRun, len(tests))
+	lines := make([]map[string][]int, len(tests))
+
+	parallel := r.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, test := range tests {
+		runs[i] = TestRun{TestName: test.Name, TestFile: test.File}
+
+		if r.isCached(test, hash) {
+			runs[i].Cached = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, test analysis.TestFunc) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			lines[i], runs[i].Err = r.runTest(ctx, resolver, module, pkgPath, coverPkg, test.Name)
+		}(i, test)
+	}
+	wg.Wait()
+
+	var ran []metadata.TestReference
+	measured := make(map[string][]metadata.TestReference)
+	functions := make(map[string][]analysis.Function)
+
+	for i, test := range tests {
+		if runs[i].Cached || runs[i].Err != nil {
+			continue
+		}
+
+		ran = append(ran, metadata.TestReference{TestFile: test.File, TestName: test.Name, CoverageHash: hash})
+		runs[i].Files = make(map[string]int)
+		cases := analysis.ExtractCases(test.Fset, test.Decl)
+		fixtures := analysis.ExtractFixtures(test.Fset, test.Decl, r.baseDir, filepath.ToSlash(filepath.Dir(test.File)))
+
+		for sourceFile, covered := range lines[i] {
+			if _, ok := functions[sourceFile]; !ok {
+				src, err := os.ReadFile(filepath.Join(r.baseDir, sourceFile))
+				if err == nil {
+					functions[sourceFile], _ = analysis.ParseFunctions(sourceFile, src)
+				}
+			}
+
+			measured[sourceFile] = append(measured[sourceFile], metadata.TestReference{
+				FunctionName:   guessFunction(functions[sourceFile], covered, test.Name),
+				TestFile:       test.File,
+				TestName:       test.Name,
+				Comment:        "Measured by isolated coverage run",
+				LineRange:      files.LineRange{Start: test.StartLine, End: test.EndLine},
+				CoveredLines:   files.LineRange{Start: covered[0], End: covered[len(covered)-1]},
+				CoveredLineSet: covered,
+				CoverageHash:   hash,
+				Origin:         metadata.OriginCoverageRun,
+				Cases:          cases,
+				Fixtures:       fixtures,
+			})
+			runs[i].Files[sourceFile] = len(covered)
+		}
+	}
+
+	if err := r.store.ApplyMeasuredCoverage(ran, measured); err != nil {
+		return runs, fmt.Errorf("failed to store coverage: %w", err)
+	}
+
+	return runs, nil
+}
+
+// isCached reports whether the test was already measured against the same
+// sources, including runs that covered no lines
+func (r *Runner) isCached(test analysis.TestFunc, hash string) bool {
+	if r.store.CoverageRunHash(test.File, test.Name) == hash {
+		return true
+	}
+	for _, ref := range r.store.FindTestReferences(test.File, test.Name) {
+		if ref.CoverageHash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// runTest runs a single test with -coverprofile and returns the covered lines
+// per source file relative to the base directory
+func (r *Runner) runTest(ctx context.Context, resolver *gomod.Resolver, module gomod.Module, pkgPath, coverPkg, testName string) (map[string][]int, error) {
+	profileFile, err := os.CreateTemp("", "cover-*.out")
+	if err != nil {
+		return nil, err
+	}
+	profilePath := profileFile.Name()
+	profileFile.Close()
+	defer os.Remove(profilePath)
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-count=1",
+		"-run", "^"+testName+"$",
+		"-covermode=set",
+		"-coverprofile="+profilePath,
+		"-coverpkg="+coverPkg,
+		pkgPath,
+	)
+	cmd.Dir = filepath.Join(r.baseDir, module.Dir)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	runErr := cmd.Run()
+
+	// A failing test still writes its profile; only give up when there is none
+	data, err := os.ReadFile(profilePath)
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		if runErr != nil {
+			return nil, fmt.Errorf("go test failed: %v\n%s", runErr, strings.TrimSpace(output.String()))
+		}
+		return nil, fmt.Errorf("go test produced no cover profile")
+	}
+
+	profile, err := ParseProfile(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]int)
+	for name, blocks := range profile.Blocks {
+		relPath, ok := resolver.RelativePath(name)
+		if !ok {
+			continue
+		}
+
+		covered := Lines(&files.FileCoverage{Mode: profile.Mode, Blocks: blocks}).CoveredLines
+		if len(covered) > 0 {
+			result[relPath] = covered
+		}
+	}
+
+	return result, nil
+}
+
+// guessFunction picks the source function a test most likely targets: a
+// covered function whose name the test name starts with, otherwise the
+// function with the most covered lines
+func guessFunction(functions []analysis.Function, covered []int, testName string) string {
+	counts := make(map[string]int)
+	for _, line := range covered {
+		if fn, ok := analysis.FunctionAt(functions, line); ok {
+			counts[fn.QualifiedName()]++
+		}
+	}
+
+	subject := strings.TrimPrefix(testName, "Test")
+	best, bestMatch, bestCount := "", 0, 0
+	for _, fn := range functions {
+		name := fn.QualifiedName()
+		count := counts[name]
+		if count == 0 {
+			continue
+		}
+
+		match := 0
+		for _, candidate := range []string{fn.Receiver + fn.Name, fn.Name} {
+			if strings.HasPrefix(subject, candidate) && len(candidate) > match {
+				match = len(candidate)
+			}
+		}
+
+		if match > bestMatch || (match == bestMatch && count > bestCount) {
+			best, bestMatch, bestCount = name, match, count
+		}
+	}
+
+	if best == "" {
+		return testName
+	}
+
+	return best
+}
+
+// hashModule hashes the Go sources and module files of a module directory
+func hashModule(moduleDir string) (string, error) {
+	var paths []string
+	err := filepath.WalkDir(moduleDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != moduleDir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(p, ".go") || d.Name() == "go.mod" || d.Name() == "go.sum" {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+
+		rel, _ := filepath.Rel(moduleDir, p)
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/metadata"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunnerRunPackage(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"go.mod":        "module example.com/calc\n\ngo 1.22\n",
+		"calc.go":       "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n",
+		"calc_test.go":  "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"Add\")\n\t}\n}\n",
+		"other_test.go": "package calc\n\nimport \"testing\"\n\nfunc TestSub(t *testing.T) {\n\tif Sub(3, 1) != 2 {\n\t\tt.Fatal(\"Sub\")\n\t}\n}\n",
+		"none_test.go":  "package calc\n\nimport \"testing\"\n\nfunc TestNothing(t *testing.T) {}\n",
+	}
+	writeFiles(t, dir, sources)
+
+	hash, err := hashModule(dir)
+	if err != nil {
+		t.Fatalf("hashModule() error = %v", err)
+	}
+
+	// TestSub was measured against these very sources
+	store := metadata.NewStore("")
+	cached := metadata.TestReference{FunctionName: "Sub", TestFile: "other_test.go", TestName: "TestSub", CoveredLineSet: []int{8}, CoverageHash: hash, Origin: metadata.OriginCoverageRun}
+	if err := store.SetTestMetadata("calc.go", []metadata.TestReference{cached}); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := NewRunner(store, dir).RunPackage(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("RunPackage() error = %v", err)
+	}
+
+	got := make(map[string]TestRun)
+	for _, run := range runs {
+		got[run.TestName] = run
+	}
+	if run := got["TestSub"]; !run.Cached || run.Files != nil {
+		t.Fatalf("TestSub run = %+v, want cached", run)
+	}
+	if run := got["TestNothing"]; run.Cached || run.Err != nil || len(run.Files) != 0 {
+		t.Fatalf("TestNothing run = %+v, want measured without lines", run)
+	}
+	if run := got["TestAdd"]; run.Cached || run.Err != nil || run.Files["calc.go"] == 0 {
+		t.Fatalf("TestAdd run = %+v, want measured lines of calc.go", run)
+	}
+
+	refs := make(map[string]metadata.TestReference)
+	for _, test := range store.GetTestMetadata("calc.go").Tests {
+		refs[test.TestName] = test
+	}
+	if !reflect.DeepEqual(refs["TestSub"], cached) {
+		t.Fatalf("TestSub = %+v, want the cached reference unchanged", refs["TestSub"])
+	}
+	if add := refs["TestAdd"]; add.FunctionName != "Add" || !reflect.DeepEqual(add.CoveredLineSet, []int{4, 5}) || add.CoverageHash != hash {
+		t.Fatalf("TestAdd = %+v, want Add lines [4 5] measured against %s", add, hash)
+	}
+
+	// Nothing changed, so a second run measures nothing, not even the test
+	// that covered no lines
+	runs, err = NewRunner(store, dir).RunPackage(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("RunPackage() error = %v", err)
+	}
+	for _, run := range runs {
+		if !run.Cached {
+			t.Fatalf("second run of %s = %+v, want cached", run.TestName, run)
+		}
+	}
+}
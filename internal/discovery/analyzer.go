@@ -0,0 +1,70 @@
+package discovery
+
+import (
+	"path"
+	"strings"
+)
+
+// Test is a test located in a test file
+type Test struct {
+	Name      string   // pytest node id style for Python, "describe > it" for JavaScript
+	StartLine int      // 1-indexed
+	EndLine   int      // 1-indexed, inclusive
+	Calls     []string // identifiers called in the test body
+}
+
+// Import is a module imported by a test file
+type Import struct {
+	Module  string            // module specifier as written
+	Names   []string          // identifiers bound by the import
+	Aliases map[string]string // imported name of renamed Names, by local name
+	// Submodules maps the Names that may be modules of their own, such as
+	// mod in Python's "from pkg import mod", to that module; they expose
+	// every function of it when they name no function of Module
+	Submodules map[string]string
+}
+
+// SourceFunc is a function declared in a source file
+type SourceFunc struct {
+	Name      string
+	StartLine int
+	EndLine   int
+}
+
+// Analyzer locates tests and functions for one language
+type Analyzer interface {
+	// Language returns a short language name
+	Language() string
+	// Extensions returns the file extensions handled by the analyzer
+	Extensions() []string
+	// IsTestFile reports whether a path follows the framework's test file naming
+	IsTestFile(filePath string) bool
+	// ParseTests returns the tests and imports of a test file
+	ParseTests(src []byte) ([]Test, []Import)
+	// ParseFunctions returns the functions declared in a source file
+	ParseFunctions(src []byte) []SourceFunc
+	// ImportCandidates returns the paths, relative to the base directory,
+	// that an import of module from testFile may refer to
+	ImportCandidates(testFile, module string) []string
+}
+
+// analyzers maps file extensions to their analyzer
+var analyzers = make(map[string]Analyzer)
+
+// Register makes an analyzer available for its file extensions
+func Register(a Analyzer) {
+	for _, ext := range a.Extensions() {
+		analyzers[ext] = a
+	}
+}
+
+// ForFile returns the analyzer registered for a file's extension
+func ForFile(filePath string) (Analyzer, bool) {
+	a, ok := analyzers[strings.ToLower(path.Ext(filePath))]
+	return a, ok
+}
+
+func init() {
+	Register(&Python{})
+	Register(&JavaScript{})
+}
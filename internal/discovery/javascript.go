@@ -0,0 +1,250 @@
+package discovery
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// JavaScript discovers Jest and Vitest tests: *.test.* and *.spec.* files
+// containing describe/it/test blocks
+type JavaScript struct{}
+
+var (
+	jsBlockRe    = regexp.MustCompile(`\b(describe|it|test)(?:\.(?:only|skip|concurrent|todo))?\s*\(\s*(?:'((?:\\.|[^'\\])*)'|"((?:\\.|[^"\\])*)"|` + "`" + `((?:\\.|[^` + "`" + `\\])*)` + "`" + `)`)
+	jsImportRe   = regexp.MustCompile(`^\s*import\s+(.+?)\s+from\s+['"]([^'"]+)['"]`)
+	jsOpenRe     = regexp.MustCompile(`^\s*(?:import\s+(?:type\s+)?(?:[\w$]+\s*,\s*)?|(?:const|let|var)\s+)\{[^}]*$`)
+	jsRequireRe  = regexp.MustCompile(`(?:const|let|var)\s+(.+?)\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)`)
+	jsFunctionRe = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)\s*[(<]`)
+	jsArrowRe    = regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`)
+	jsCallRe     = regexp.MustCompile(`\b([A-Za-z_$][\w$]*)\s*\(`)
+)
+
+// Language implements Analyzer
+func (j *JavaScript) Language() string {
+	return "javascript"
+}
+
+// Extensions implements Analyzer
+func (j *JavaScript) Extensions() []string {
+	return []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts"}
+}
+
+// IsTestFile implements Analyzer
+func (j *JavaScript) IsTestFile(filePath string) bool {
+	base := path.Base(filePath)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	return strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec")
+}
+
+// ParseTests implements Analyzer
+func (j *JavaScript) ParseTests(src []byte) ([]Test, []Import) {
+	lines := strings.Split(string(src), "\n")
+
+	type scope struct {
+		name string
+		end  int
+	}
+	var describes []scope
+	var tests []Test
+
+	for i, line := range lines {
+		lineNum := i + 1
+		for len(describes) > 0 && describes[len(describes)-1].end < lineNum {
+			describes = describes[:len(describes)-1]
+		}
+
+		m := jsBlockRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		title := m[2] + m[3] + m[4]
+		end := braceBlockEnd(lines, i)
+		if m[1] == "describe" {
+			describes = append(describes, scope{name: title, end: end})
+			continue
+		}
+
+		names := make([]string, 0, len(describes)+1)
+		for _, d := range describes {
+			names = append(names, d.name)
+		}
+		names = append(names, title)
+
+		tests = append(tests, Test{
+			Name:      strings.Join(names, " > "),
+			StartLine: lineNum,
+			EndLine:   end,
+			Calls:     jsCalls(lines[i:end]),
+		})
+	}
+
+	return tests, jsImports(lines)
+}
+
+// ParseFunctions implements Analyzer
+func (j *JavaScript) ParseFunctions(src []byte) []SourceFunc {
+	lines := strings.Split(string(src), "\n")
+
+	var functions []SourceFunc
+	for i, line := range lines {
+		m := jsFunctionRe.FindStringSubmatch(line)
+		if m == nil {
+			m = jsArrowRe.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+		functions = append(functions, SourceFunc{
+			Name:      m[1],
+			StartLine: i + 1,
+			EndLine:   braceBlockEnd(lines, i),
+		})
+	}
+
+	return functions
+}
+
+// ImportCandidates implements Analyzer. Only relative imports are resolved.
+func (j *JavaScript) ImportCandidates(testFile, module string) []string {
+	if !strings.HasPrefix(module, ".") {
+		return nil
+	}
+
+	modPath := path.Join(path.Dir(testFile), module)
+	if path.Ext(modPath) != "" {
+		if _, ok := ForFile(modPath); ok {
+			return []string{modPath}
+		}
+	}
+
+	var candidates []string
+	for _, ext := range []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".mts", ".cts"} {
+		candidates = append(candidates, modPath+ext)
+	}
+	for _, ext := range []string{".ts", ".tsx", ".js", ".jsx"} {
+		candidates = append(candidates, path.Join(modPath, "index"+ext))
+	}
+	return candidates
+}
+
+// braceBlockEnd returns the 1-indexed line closing the first brace block
+// opened at or after line index start. Strings and line comments are skipped.
+func braceBlockEnd(lines []string, start int) int {
+	depth, opened := 0, false
+
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		var quote byte
+		for k := 0; k < len(line); k++ {
+			c := line[k]
+			if quote != 0 {
+				if c == '\\' {
+					k++
+				} else if c == quote {
+					quote = 0
+				}
+				continue
+			}
+
+			switch c {
+			case '\'', '"', '`':
+				quote = c
+			case '/':
+				if k+1 < len(line) && line[k+1] == '/' {
+					k = len(line)
+				}
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+				if opened && depth == 0 {
+					return i + 1
+				}
+			}
+		}
+
+		// A statement without a block (e.g. it.todo('x')) ends on its own line
+		if !opened && strings.HasSuffix(strings.TrimSpace(line), ";") {
+			return i + 1
+		}
+	}
+
+	return start + 1
+}
+
+// jsCalls returns the identifiers called in a block of lines
+func jsCalls(lines []string) []string {
+	seen := make(map[string]bool)
+	var calls []string
+	for _, line := range lines {
+		for _, m := range jsCallRe.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				calls = append(calls, m[1])
+			}
+		}
+	}
+	return calls
+}
+
+// jsImports returns the ES module imports and CommonJS requires of a file,
+// following brace lists split over several lines
+func jsImports(lines []string) []Import {
+	var imports []Import
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if jsOpenRe.MatchString(line) {
+			for !strings.Contains(line, "}") && i+1 < len(lines) {
+				i++
+				line += " " + lines[i]
+			}
+		}
+
+		m := jsImportRe.FindStringSubmatch(line)
+		if m == nil {
+			m = jsRequireRe.FindStringSubmatch(line)
+		}
+		if m != nil {
+			names, aliases := jsBindings(m[1])
+			imports = append(imports, Import{Module: m[2], Names: names, Aliases: aliases})
+		}
+	}
+	return imports
+}
+
+// jsBindings extracts the local names bound by an import clause such as
+// "def, { a, b as c }" or "* as ns", or by a destructured require such as
+// "{ a, b: c }", and the imported name of those that were renamed
+func jsBindings(clause string) ([]string, map[string]string) {
+	clause = strings.NewReplacer("{", ",", "}", ",").Replace(clause)
+
+	var names []string
+	var aliases map[string]string
+	for _, part := range strings.Split(clause, ",") {
+		part = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(part), "type "))
+		if part == "" || strings.HasPrefix(part, "*") {
+			continue
+		}
+		imported, local, renamed := strings.Cut(part, " as ")
+		if !renamed {
+			imported, local, renamed = strings.Cut(part, ":")
+		}
+		imported, local = strings.TrimSpace(imported), strings.TrimSpace(local)
+		if !renamed {
+			names = append(names, imported)
+			continue
+		}
+		if local == "" {
+			continue
+		}
+		if aliases == nil {
+			aliases = make(map[string]string)
+		}
+		names = append(names, local)
+		aliases[local] = imported
+	}
+	return names, aliases
+}
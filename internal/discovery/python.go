@@ -0,0 +1,227 @@
+package discovery
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Python discovers pytest tests: test_*.py and *_test.py files containing
+// test_* functions, optionally grouped in Test* classes
+type Python struct{}
+
+var (
+	pyDefRe        = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)\s*\(`)
+	pyClassRe      = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+	pyFromImportRe = regexp.MustCompile(`^\s*from\s+([\w.]+)\s+import\s+(.+)$`)
+	pyImportRe     = regexp.MustCompile(`^\s*import\s+([\w.]+)`)
+	pyCallRe       = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\(`)
+)
+
+// Language implements Analyzer
+func (p *Python) Language() string {
+	return "python"
+}
+
+// Extensions implements Analyzer
+func (p *Python) Extensions() []string {
+	return []string{".py"}
+}
+
+// IsTestFile implements Analyzer
+func (p *Python) IsTestFile(filePath string) bool {
+	base := path.Base(filePath)
+	return strings.HasSuffix(base, ".py") && (strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py"))
+}
+
+// ParseTests implements Analyzer
+func (p *Python) ParseTests(src []byte) ([]Test, []Import) {
+	lines := strings.Split(string(src), "\n")
+
+	var tests []Test
+	class, classIndent := "", -1
+
+	for i, line := range lines {
+		if m := pyClassRe.FindStringSubmatch(line); m != nil {
+			if len(m[1]) == 0 && strings.HasPrefix(m[2], "Test") {
+				class, classIndent = m[2], 0
+			} else if len(m[1]) <= classIndent {
+				class, classIndent = "", -1
+			}
+			continue
+		}
+
+		m := pyDefRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		indent, name := len(m[1]), m[2]
+		if indent == 0 {
+			class, classIndent = "", -1
+		}
+		if !strings.HasPrefix(name, "test") {
+			continue
+		}
+
+		testName := name
+		switch {
+		case indent == 0:
+		case class != "" && indent > classIndent:
+			testName = class + "::" + name
+		default:
+			continue
+		}
+
+		end := pyBlockEnd(lines, i, indent)
+		tests = append(tests, Test{
+			Name:      testName,
+			StartLine: i + 1,
+			EndLine:   end,
+			Calls:     pyCalls(lines[i+1 : end]),
+		})
+	}
+
+	return tests, pyImports(lines)
+}
+
+// ParseFunctions implements Analyzer
+func (p *Python) ParseFunctions(src []byte) []SourceFunc {
+	lines := strings.Split(string(src), "\n")
+
+	var functions []SourceFunc
+	for i, line := range lines {
+		m := pyDefRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		functions = append(functions, SourceFunc{
+			Name:      m[2],
+			StartLine: i + 1,
+			EndLine:   pyBlockEnd(lines, i, len(m[1])),
+		})
+	}
+
+	return functions
+}
+
+// ImportCandidates implements Analyzer
+func (p *Python) ImportCandidates(testFile, module string) []string {
+	if strings.HasPrefix(module, ".") {
+		dir := path.Dir(testFile)
+		rest := strings.TrimLeft(module, ".")
+		for i := 1; i < len(module)-len(rest); i++ {
+			dir = path.Dir(dir)
+		}
+		modPath := path.Join(dir, strings.ReplaceAll(rest, ".", "/"))
+		return []string{modPath + ".py", path.Join(modPath, "__init__.py")}
+	}
+
+	modPath := strings.ReplaceAll(module, ".", "/")
+	var candidates []string
+	for _, root := range []string{"", "src", path.Dir(testFile)} {
+		candidates = append(candidates,
+			path.Join(root, modPath+".py"),
+			path.Join(root, modPath, "__init__.py"),
+		)
+	}
+	return candidates
+}
+
+// pyBlockEnd returns the 1-indexed last line of the block opened at line
+// index start with the given indentation
+func pyBlockEnd(lines []string, start, indent int) int {
+	end := start + 1
+	for j := start + 1; j < len(lines); j++ {
+		trimmed := strings.TrimSpace(lines[j])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if len(lines[j])-len(strings.TrimLeft(lines[j], " \t")) <= indent {
+			break
+		}
+		end = j + 1
+	}
+	return end
+}
+
+// pyCalls returns the identifiers called in a block of lines
+func pyCalls(lines []string) []string {
+	seen := make(map[string]bool)
+	var calls []string
+	for _, line := range lines {
+		for _, m := range pyCallRe.FindAllStringSubmatch(line, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				calls = append(calls, m[1])
+			}
+		}
+	}
+	return calls
+}
+
+// pyImports returns the from/import statements of a file, following
+// parenthesized multi-line import lists. Every name imported with from may
+// be a submodule of the package imported from.
+func pyImports(lines []string) []Import {
+	var imports []Import
+	for i := 0; i < len(lines); i++ {
+		if m := pyFromImportRe.FindStringSubmatch(lines[i]); m != nil {
+			names := m[2]
+			if strings.HasPrefix(strings.TrimSpace(names), "(") {
+				for !strings.Contains(names, ")") && i+1 < len(lines) {
+					i++
+					names += " " + lines[i]
+				}
+			}
+			names, _, _ = strings.Cut(names, "#")
+
+			imp := Import{Module: m[1], Submodules: make(map[string]string)}
+			star := false
+			for _, part := range strings.Split(strings.Trim(strings.TrimSpace(names), "()"), ",") {
+				fields := strings.Fields(strings.Trim(strings.TrimSpace(part), "()"))
+				if len(fields) == 0 {
+					continue
+				}
+				name, local := fields[0], fields[0]
+				if len(fields) == 3 && fields[1] == "as" {
+					local = fields[2]
+				}
+				if name == "*" {
+					star = true
+					continue
+				}
+
+				imp.Names = append(imp.Names, local)
+				if local != name {
+					if imp.Aliases == nil {
+						imp.Aliases = make(map[string]string)
+					}
+					imp.Aliases[local] = name
+				}
+				imp.Submodules[local] = pySubmodule(m[1], name)
+			}
+			if star {
+				// Every function of the module is bound; an import without
+				// names exposes them all
+				imp.Names, imp.Aliases, imp.Submodules = nil, nil, nil
+			}
+			imports = append(imports, imp)
+			continue
+		}
+
+		if m := pyImportRe.FindStringSubmatch(lines[i]); m != nil {
+			imports = append(imports, Import{Module: m[1]})
+		}
+	}
+	return imports
+}
+
+// pySubmodule returns the name of the module imported by "from module
+// import name" when name is a submodule
+func pySubmodule(module, name string) string {
+	if strings.HasSuffix(module, ".") {
+		return module + name
+	}
+	return module + "." + name
+}
@@ -0,0 +1,273 @@
+package discovery
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// OriginDiscovery marks test references proposed by a discovery analyzer
+const OriginDiscovery = "discovery"
+
+// skipDirs are directories never scanned for tests
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"__pycache__":  true,
+	"venv":         true,
+	"dist":         true,
+	"build":        true,
+	"coverage":     true,
+}
+
+// importedFunc is a source function reachable through a test file's imports
+type importedFunc struct {
+	sourceFile string
+	fn         SourceFunc
+}
+
+// Scan walks relDir under the base directory of fileService, locates the
+// test files of every registered analyzer and proposes test references
+// against the source files they import. Files are read through fileService,
+// so imports reaching outside the base directory or onto the deny-list are
+// not followed.
+func Scan(fileService *files.Service, relDir string) (*files.DiscoveryResponse, error) {
+	baseDir := fileService.BaseDir()
+	response := &files.DiscoveryResponse{
+		TestFiles: []files.DiscoveredTestFile{},
+		Proposals: []files.ProposedTests{},
+	}
+
+	root := filepath.Join(baseDir, relDir)
+	proposals := make(map[string][]files.TestReference)
+	sourceFuncs := make(map[string][]SourceFunc)
+
+	ignored := ignore.For(baseDir)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()] || ignored.IgnoredPath(p, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignored.IgnoredPath(p, false) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		analyzer, ok := ForFile(rel)
+		if !ok || !analyzer.IsTestFile(rel) {
+			return nil
+		}
+
+		content, err := fileService.ReadFile(rel)
+		if err != nil || content.Binary {
+			return nil
+		}
+
+		tests, imports := analyzer.ParseTests([]byte(content.Content))
+		testFile := files.DiscoveredTestFile{
+			Path:     rel,
+			Language: analyzer.Language(),
+			Tests:    []files.DiscoveredTest{},
+		}
+		for _, test := range tests {
+			testFile.Tests = append(testFile.Tests, files.DiscoveredTest{
+				Name:      test.Name,
+				LineRange: files.LineRange{Start: test.StartLine, End: test.EndLine},
+			})
+		}
+		response.TestFiles = append(response.TestFiles, testFile)
+
+		available := resolveImports(fileService, rel, analyzer, imports, sourceFuncs)
+		for _, test := range tests {
+			target, ok := pickTarget(test, available)
+			if !ok {
+				continue
+			}
+			proposals[target.sourceFile] = append(proposals[target.sourceFile], files.TestReference{
+				FunctionName: target.fn.Name,
+				TestFile:     rel,
+				TestName:     test.Name,
+				Comment:      "Discovered by the " + analyzer.Language() + " analyzer",
+				LineRange:    files.LineRange{Start: test.StartLine, End: test.EndLine},
+				CoveredLines: files.LineRange{Start: target.fn.StartLine, End: target.fn.EndLine},
+				Origin:       OriginDiscovery,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for sourceFile, tests := range proposals {
+		response.Proposals = append(response.Proposals, files.ProposedTests{
+			SourceFile: sourceFile,
+			Tests:      tests,
+		})
+	}
+	sort.Slice(response.Proposals, func(i, j int) bool {
+		return response.Proposals[i].SourceFile < response.Proposals[j].SourceFile
+	})
+
+	return response, nil
+}
+
+// resolveImports maps the names a test file can call to imported source functions
+func resolveImports(fileService *files.Service, testFile string, analyzer Analyzer, imports []Import, cache map[string][]SourceFunc) map[string]importedFunc {
+	available := make(map[string]importedFunc)
+
+	for i := 0; i < len(imports); i++ {
+		imp := imports[i]
+		sourceFile := ""
+		for _, candidate := range analyzer.ImportCandidates(testFile, imp.Module) {
+			// Relative imports may climb out of the base directory
+			if !filepath.IsLocal(filepath.FromSlash(candidate)) {
+				continue
+			}
+			fullPath, err := fileService.Resolve(candidate)
+			if err != nil {
+				continue
+			}
+			if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
+				sourceFile = path.Clean(candidate)
+				break
+			}
+		}
+		if sourceFile == "" {
+			// A package without a file of its own can still have submodules
+			for _, name := range imp.Names {
+				imports = append(imports, submoduleImports(imp, name)...)
+			}
+			continue
+		}
+
+		functions, ok := cache[sourceFile]
+		if !ok {
+			if content, err := fileService.ReadFile(sourceFile); err == nil && !content.Binary {
+				if sourceAnalyzer, ok := ForFile(sourceFile); ok {
+					functions = sourceAnalyzer.ParseFunctions([]byte(content.Content))
+				}
+			}
+			cache[sourceFile] = functions
+		}
+
+		// Named imports expose only those names, under their local name;
+		// module imports expose every function through attribute access
+		// (module.func)
+		locals := make(map[string][]string)
+		for _, name := range imp.Names {
+			imported := name
+			if alias, ok := imp.Aliases[name]; ok {
+				imported = alias
+			}
+			locals[imported] = append(locals[imported], name)
+		}
+		for _, fn := range functions {
+			names := locals[fn.Name]
+			if len(imp.Names) == 0 {
+				names = []string{fn.Name}
+			}
+			for _, name := range names {
+				if _, exists := available[name]; !exists {
+					available[name] = importedFunc{sourceFile: sourceFile, fn: fn}
+				}
+			}
+			delete(locals, fn.Name)
+		}
+
+		// Names that are no function may be submodules, imported as a whole
+		for _, name := range imp.Names {
+			imported := name
+			if alias, ok := imp.Aliases[name]; ok {
+				imported = alias
+			}
+			if _, unresolved := locals[imported]; unresolved {
+				imports = append(imports, submoduleImports(imp, name)...)
+			}
+		}
+	}
+
+	return available
+}
+
+// submoduleImports returns the module import of a name when it may be a
+// submodule of the module it is imported from
+func submoduleImports(imp Import, name string) []Import {
+	if submodule, ok := imp.Submodules[name]; ok {
+		return []Import{{Module: submodule}}
+	}
+	return nil
+}
+
+// pickTarget chooses the imported function a test exercises: a called
+// function named in the test name if any, otherwise the first one called
+func pickTarget(test Test, available map[string]importedFunc) (importedFunc, bool) {
+	normalizedTest := normalizeName(test.Name)
+
+	var first *importedFunc
+	for _, call := range test.Calls {
+		target, ok := available[call]
+		if !ok {
+			continue
+		}
+		if strings.Contains(normalizedTest, normalizeName(call)) {
+			return target, true
+		}
+		if first == nil {
+			first = &target
+		}
+	}
+
+	if first == nil {
+		return importedFunc{}, false
+	}
+	return *first, true
+}
+
+// normalizeName lowercases a name and drops separators for fuzzy comparison
+func normalizeName(name string) string {
+	return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// Apply stores proposed test references that do not exist yet and returns
+// how many were added. Existing references for the same test are kept.
+func Apply(store *metadata.Store, response *files.DiscoveryResponse) (int, error) {
+	applied := 0
+	for _, proposal := range response.Proposals {
+		var fresh []files.TestReference
+		for _, test := range proposal.Tests {
+			if _, exists := store.FindTestReferences(test.TestFile, test.TestName)[proposal.SourceFile]; !exists {
+				fresh = append(fresh, test)
+			}
+		}
+		if len(fresh) == 0 {
+			continue
+		}
+
+		if err := store.AddTestMetadata(proposal.SourceFile, fresh); err != nil {
+			return applied, err
+		}
+		applied += len(fresh)
+	}
+
+	return applied, nil
+}
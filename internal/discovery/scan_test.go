@@ -0,0 +1,235 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+const pythonSource = `def add(a, b):
+    return a + b
+
+
+def sub(a, b):
+    return a - b
+`
+
+const pythonTests = `from calc import (
+    add,
+    sub,
+)
+
+
+def test_add():
+    assert add(1, 2) == 3
+
+
+class TestSub:
+    def helper(self):
+        return 1
+
+    def test_sub(self):
+        assert sub(3, add(1, 1)) == 1
+`
+
+const jsSource = `export function slugify(text) {
+  return text.toLowerCase().replace(/ /g, '-');
+}
+
+export const titleCase = (text) => {
+  return text.toUpperCase();
+};
+`
+
+const jsTests = `import { slugify, titleCase } from './text';
+
+describe('text', () => {
+  it('slugifies "words"', () => {
+    expect(slugify('A B')).toBe('a-b');
+  });
+
+  test.skip('title case', () => {
+    expect(titleCase('a')).toBe('A');
+  });
+});
+`
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseTests(t *testing.T) {
+	t.Run("python", func(t *testing.T) {
+		tests, imports := (&Python{}).ParseTests([]byte(pythonTests))
+		if len(tests) != 2 {
+			t.Fatalf("len(tests) = %d, want 2", len(tests))
+		}
+		if tests[0].Name != "test_add" || tests[0].StartLine != 7 || tests[0].EndLine != 8 {
+			t.Fatalf("tests[0] = %+v, want test_add 7-8", tests[0])
+		}
+		if tests[1].Name != "TestSub::test_sub" || tests[1].StartLine != 15 || tests[1].EndLine != 16 {
+			t.Fatalf("tests[1] = %+v, want TestSub::test_sub 15-16", tests[1])
+		}
+		if len(imports) != 1 || imports[0].Module != "calc" || len(imports[0].Names) != 2 {
+			t.Fatalf("imports = %+v, want calc with add, sub", imports)
+		}
+	})
+
+	t.Run("javascript", func(t *testing.T) {
+		tests, imports := (&JavaScript{}).ParseTests([]byte(jsTests))
+		if len(tests) != 2 {
+			t.Fatalf("len(tests) = %d, want 2", len(tests))
+		}
+		if tests[0].Name != `text > slugifies "words"` || tests[0].StartLine != 4 || tests[0].EndLine != 6 {
+			t.Fatalf("tests[0] = %+v, want text > slugifies 4-6", tests[0])
+		}
+		if tests[1].Name != "text > title case" || tests[1].StartLine != 8 || tests[1].EndLine != 10 {
+			t.Fatalf("tests[1] = %+v, want text > title case 8-10", tests[1])
+		}
+		if len(imports) != 1 || imports[0].Module != "./text" || len(imports[0].Names) != 2 {
+			t.Fatalf("imports = %+v, want ./text with slugify, titleCase", imports)
+		}
+	})
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"calc.py":                     pythonSource,
+		"tests/test_calc.py":          pythonTests,
+		"web/text.ts":                 jsSource,
+		"web/text.test.ts":            jsTests,
+		"node_modules/lib/a.test.js":  jsTests,
+		"web/.cache/ignored.test.ts":  jsTests,
+		"tests/__pycache__/test_x.py": pythonTests,
+	})
+
+	response, err := Scan(files.NewService(dir), "")
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(response.TestFiles) != 2 {
+		t.Fatalf("len(TestFiles) = %d, want 2: %+v", len(response.TestFiles), response.TestFiles)
+	}
+	if len(response.Proposals) != 2 {
+		t.Fatalf("len(Proposals) = %d, want 2", len(response.Proposals))
+	}
+
+	py := response.Proposals[0]
+	if py.SourceFile != "calc.py" || len(py.Tests) != 2 {
+		t.Fatalf("Proposals[0] = %+v, want 2 tests for calc.py", py)
+	}
+	if py.Tests[1].TestName != "TestSub::test_sub" || py.Tests[1].FunctionName != "sub" {
+		t.Fatalf("python test = %+v, want TestSub::test_sub -> sub", py.Tests[1])
+	}
+	if py.Tests[1].CoveredLines.Start != 5 || py.Tests[1].CoveredLines.End != 6 {
+		t.Fatalf("CoveredLines = %+v, want 5-6", py.Tests[1].CoveredLines)
+	}
+
+	js := response.Proposals[1]
+	if js.SourceFile != "web/text.ts" || len(js.Tests) != 2 {
+		t.Fatalf("Proposals[1] = %+v, want 2 tests for web/text.ts", js)
+	}
+	if js.Tests[1].FunctionName != "titleCase" || js.Tests[1].CoveredLines.End != 7 {
+		t.Fatalf("javascript test = %+v, want titleCase ending on line 7", js.Tests[1])
+	}
+	if js.Tests[0].Origin != OriginDiscovery {
+		t.Fatalf("Origin = %q, want %q", js.Tests[0].Origin, OriginDiscovery)
+	}
+}
+
+func TestScanImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]string // test name -> function name
+		wantSrc string
+	}{
+		{
+			name: "javascript multi-line and aliased imports",
+			files: map[string]string{
+				"math.js": "export function add(a, b) {\n  return a + b;\n}\n\nexport function sub(a, b) {\n  return a - b;\n}\n\nexport function mul(a, b) {\n  return a * b;\n}\n",
+				"math.test.js": "import {\n  add,\n  sub as minus,\n} from './math';\nimport { mul } from './math';\n\n" +
+					"test('adds', () => {\n  expect(add(1, 2)).toBe(3);\n});\n\n" +
+					"test('subtracts', () => {\n  expect(minus(3, 1)).toBe(2);\n});\n\n" +
+					"test('multiplies', () => {\n  expect(mul(2, 3)).toBe(6);\n});\n",
+			},
+			want:    map[string]string{"adds": "add", "subtracts": "sub", "multiplies": "mul"},
+			wantSrc: "math.js",
+		},
+		{
+			name: "python submodule import",
+			files: map[string]string{
+				"pkg/__init__.py":    "",
+				"pkg/calc.py":        pythonSource,
+				"tests/test_calc.py": "from pkg import calc\n\n\ndef test_add():\n    assert calc.add(1, 2) == 3\n",
+			},
+			want:    map[string]string{"test_add": "add"},
+			wantSrc: "pkg/calc.py",
+		},
+		{
+			name: "python star import",
+			files: map[string]string{
+				"calc.py":            pythonSource,
+				"tests/test_calc.py": "from calc import *\n\n\ndef test_sub():\n    assert sub(3, 1) == 2\n",
+			},
+			want:    map[string]string{"test_sub": "sub"},
+			wantSrc: "calc.py",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			response, err := Scan(files.NewService(dir), "")
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if len(response.Proposals) != 1 || response.Proposals[0].SourceFile != tt.wantSrc {
+				t.Fatalf("Proposals = %+v, want one for %s", response.Proposals, tt.wantSrc)
+			}
+
+			got := make(map[string]string)
+			for _, test := range response.Proposals[0].Tests {
+				got[test.TestName] = test.FunctionName
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("tests = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanStaysInBaseDir(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"outside/text.js":       jsSource,
+		"base/web/text.test.js": "import { slugify } from '../../outside/text';\n\ntest('slugifies', () => {\n  expect(slugify('A')).toBe('a');\n});\n",
+	})
+
+	response, err := Scan(files.NewService(filepath.Join(root, "base")), "")
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(response.TestFiles) != 1 {
+		t.Fatalf("TestFiles = %+v, want the test file", response.TestFiles)
+	}
+	if len(response.Proposals) != 0 {
+		t.Fatalf("Proposals = %+v, want none for a source outside the base directory", response.Proposals)
+	}
+}
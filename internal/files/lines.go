@@ -0,0 +1,100 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"time"
+	"unicode/utf8"
+)
+
+// sniffLen is the number of leading bytes inspected to tell binary files
+// from text
+const sniffLen = 8192
+
+// maxLineIndexes caps the number of files whose line offsets are cached
+const maxLineIndexes = 64
+
+// lineIndex holds the byte offset at which every line of a file starts
+type lineIndex struct {
+	modTime time.Time
+	size    int64
+	offsets []int64
+}
+
+// IsBinary reports whether content looks binary: it has a NUL byte or is
+// not valid UTF-8 within its first 8KB
+func IsBinary(content []byte) bool {
+	head := content[:min(len(content), sniffLen)]
+	if bytes.IndexByte(head, 0) >= 0 {
+		return true
+	}
+	// The head, or a read cut short, may end inside a multi-byte rune
+	for i := len(head) - 1; i >= 0 && i >= len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				head = head[:i]
+			}
+			break
+		}
+	}
+	return !utf8.Valid(head)
+}
+
+// lineOffsets returns the line start offsets of an open file, from the cache
+// when the file did not change since they were computed
+func (s *Service) lineOffsets(file *os.File, info os.FileInfo) ([]int64, error) {
+	key := file.Name()
+
+	s.linesMu.Lock()
+	cached, ok := s.lines[key]
+	s.linesMu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.offsets, nil
+	}
+
+	offsets, err := scanLines(io.NewSectionReader(file, 0, info.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.linesMu.Lock()
+	defer s.linesMu.Unlock()
+	if len(s.lines) >= maxLineIndexes {
+		// Evict an arbitrary entry; line indexes are cheap to rebuild
+		for k := range s.lines {
+			delete(s.lines, k)
+			break
+		}
+	}
+	s.lines[key] = &lineIndex{modTime: info.ModTime(), size: info.Size(), offsets: offsets}
+	return offsets, nil
+}
+
+// scanLines streams a file and records the offset of every line start. A
+// final newline does not start another line.
+func scanLines(r io.Reader) ([]int64, error) {
+	var offsets []int64
+	reader := bufio.NewReaderSize(r, 64<<10)
+	var pos int64
+	atLineStart := true
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			if atLineStart {
+				offsets = append(offsets, pos)
+			}
+			pos += int64(len(chunk))
+			atLineStart = chunk[len(chunk)-1] == '\n'
+		}
+		switch err {
+		case nil, bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			return offsets, nil
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want bool
+	}{
+		{name: "empty", in: nil, want: false},
+		{name: "text", in: []byte("package main\n"), want: false},
+		{name: "nul byte", in: []byte("a\x00b"), want: true},
+		{name: "invalid utf-8", in: []byte{0xff, 0xfe, 'a'}, want: true},
+		{name: "rune cut at the end", in: []byte("héllo")[:2], want: false},
+		{name: "rune cut at the sniff limit", in: []byte(strings.Repeat("a", sniffLen-1) + "é"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.in); got != tt.want {
+				t.Fatalf("IsBinary(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanLines(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []int64
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "final newline", in: "a\nbc\n", want: []int64{0, 2}},
+		{name: "no final newline", in: "a\nbc", want: []int64{0, 2}},
+		{name: "blank lines", in: "\n\nx", want: []int64{0, 1, 2}},
+		{name: "long line", in: strings.Repeat("x", 100<<10) + "\ny", want: []int64{0, 100<<10 + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scanLines(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("scanLines() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("scanLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceSizeCap(t *testing.T) {
+	baseDir := t.TempDir()
+	content := "first line\nsecond line\nthird line\n"
+	if err := os.WriteFile(filepath.Join(baseDir, "big.txt"), []byte(content), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	s := NewService(baseDir)
+	s.SetMaxFileSize(int64(len("first line\nsecond li")))
+
+	file, err := s.ReadFile("big.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if file.Content != "first line\n" || !file.Truncated {
+		t.Fatalf("ReadFile() = %q truncated %v, want %q truncated true", file.Content, file.Truncated, "first line\n")
+	}
+
+	lines, err := s.ReadLines("big.txt", 2, 3)
+	if err != nil {
+		t.Fatalf("ReadLines() error = %v", err)
+	}
+	if lines.Content != "second line\n" || !lines.Truncated || lines.EndLine != 2 {
+		t.Fatalf("ReadLines() = %q truncated %v end %d, want %q truncated true end 2", lines.Content, lines.Truncated, lines.EndLine, "second line\n")
+	}
+}
+
+func TestServiceReadLinesEmpty(t *testing.T) {
+	baseDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(baseDir, "empty.txt"), nil, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	s := NewService(baseDir)
+
+	lines, err := s.ReadLines("empty.txt", 1, 0)
+	if err != nil {
+		t.Fatalf("ReadLines() error = %v", err)
+	}
+	if lines.Content != "" || lines.TotalLines != 0 || lines.EndLine != 0 {
+		t.Fatalf("ReadLines() = %q end %d total %d, want no lines", lines.Content, lines.EndLine, lines.TotalLines)
+	}
+
+	if _, err := s.ReadLines("empty.txt", 2, 0); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("ReadLines(2) error = %v, want %v", err, ErrInvalidRange)
+	}
+}
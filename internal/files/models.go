@@ -9,18 +9,40 @@ type FileEntry struct {
 	IsDir   bool      `json:"isDir"`
 	Size    int64     `json:"size,omitempty"`
 	ModTime time.Time `json:"modTime"`
+
+	Coverage *CoverageSummary `json:"coverage,omitempty"` // rollup of the metadata of the file or directory
 }
 
 // FileContent represents file content with metadata
 type FileContent struct {
-	Path          string           `json:"path"`
-	Name          string           `json:"name"`
-	Content       string           `json:"content"`
-	Size          int64            `json:"size"`
-	ModTime       time.Time        `json:"modTime"`
-	MimeType      string           `json:"mimeType"`
-	Metadata      *FileMetadata    `json:"metadata,omitempty"`
-	CoverageDepth map[int][]string `json:"coverageDepth,omitempty"` // line number -> list of test names
+	Path          string                       `json:"path"`
+	Name          string                       `json:"name"`
+	Content       string                       `json:"content"`
+	Size          int64                        `json:"size"`
+	ModTime       time.Time                    `json:"modTime"`
+	MimeType      string                       `json:"mimeType"`
+	Binary        bool                         `json:"binary,omitempty"`     // content is left empty; use /api/raw
+	Truncated     bool                         `json:"truncated,omitempty"`  // content was cut at the size cap
+	StartLine     int                          `json:"startLine,omitempty"`  // first line of a line-range read
+	EndLine       int                          `json:"endLine,omitempty"`    // last line of a line-range read
+	TotalLines    int                          `json:"totalLines,omitempty"` // lines in the file, for line-range reads
+	Metadata      *FileMetadata                `json:"metadata,omitempty"`
+	CoverageDepth map[int][]string             `json:"coverageDepth,omitempty"` // line number -> list of test names
+	RealCoverage  *LineCoverage                `json:"realCoverage,omitempty"`  // measured by go test -coverprofile
+	TestStatus    map[string]map[string]string `json:"testStatus,omitempty"`    // test file -> test name -> last recorded status
+
+	// Go source files: tests reaching each function through the static call graph
+	FunctionCoverage []FunctionCoverage `json:"functionCoverage,omitempty"`
+	IndirectCoverage map[int][]string   `json:"indirectCoverage,omitempty"` // line number -> tests reaching it only through other functions
+
+	Mutations map[int]LineMutations `json:"mutations,omitempty"` // line number -> mutation testing outcome
+}
+
+// LineCoverage summarizes measured per-line coverage for a file
+type LineCoverage struct {
+	CoveredLines   []int       `json:"coveredLines"`
+	UncoveredLines []int       `json:"uncoveredLines"`
+	Hits           map[int]int `json:"hits"` // line number -> execution count
 }
 
 // Comment represents an inline comment on a specific line of code
@@ -34,6 +56,19 @@ type Comment struct {
 	Resolved  bool      `json:"resolved"`
 	// ContextLines stores surrounding lines for AI agent context
 	ContextLines LineRange `json:"contextLines,omitempty"`
+	// Fingerprint of the commented line, used to follow it across edits
+	Fingerprint *Fingerprint `json:"fingerprint,omitempty"`
+	Stale       bool         `json:"stale,omitempty"` // computed on read
+}
+
+// Fingerprint identifies the content of a line range so that the range can
+// be relocated after the file is edited
+type Fingerprint struct {
+	Hash   string `json:"hash"`             // hash of the trimmed lines in the range
+	Before string `json:"before,omitempty"` // hash of the line preceding the range
+	After  string `json:"after,omitempty"`  // hash of the line following the range
+	Anchor string `json:"anchor,omitempty"` // enclosing Go function (Type.Method for methods)
+	Offset int    `json:"offset,omitempty"` // range start relative to the anchor's first line
 }
 
 // FileMetadata contains test-related metadata for a file
@@ -41,6 +76,42 @@ type FileMetadata struct {
 	Tests       []TestReference  `json:"tests,omitempty"`
 	Suggestions []TestSuggestion `json:"suggestions,omitempty"`
 	Comments    []Comment        `json:"comments,omitempty"`
+	Coverage    *FileCoverage    `json:"coverage,omitempty"`
+	// TestResults holds the last recorded result of each test declared in
+	// this file, keyed by test name (only set on test files)
+	TestResults map[string]TestResult `json:"testResults,omitempty"`
+	// CoverageRuns holds the module hash each test declared in this file was
+	// last measured against by an isolated coverage run, keyed by test name,
+	// whether or not it covered any lines (only set on test files)
+	CoverageRuns map[string]string `json:"coverageRuns,omitempty"`
+	// Mutations holds the last mutation testing run of each function,
+	// keyed by qualified function name
+	Mutations map[string]MutationRun `json:"mutations,omitempty"`
+}
+
+// TestResult is the outcome of the last recorded run of a test
+type TestResult struct {
+	Status  string    `json:"status"`           // pass, fail or skip
+	Elapsed float64   `json:"elapsed"`          // seconds
+	Output  string    `json:"output,omitempty"` // test output, kept for failures only
+	RunAt   time.Time `json:"runAt"`
+}
+
+// FileCoverage holds coverage imported from a Go cover profile
+type FileCoverage struct {
+	Mode       string          `json:"mode"`
+	Blocks     []CoverageBlock `json:"blocks"`
+	ImportedAt time.Time       `json:"importedAt"`
+}
+
+// CoverageBlock is a single statement block from a Go cover profile
+type CoverageBlock struct {
+	StartLine int `json:"startLine"`
+	StartCol  int `json:"startCol"`
+	EndLine   int `json:"endLine"`
+	EndCol    int `json:"endCol"`
+	NumStmts  int `json:"numStmts"`
+	Count     int `json:"count"`
 }
 
 // TestReference links a source file to its tests
@@ -53,6 +124,101 @@ type TestReference struct {
 	CoveredLines LineRange `json:"coveredLines"`
 	InputLines   LineRange `json:"inputLines,omitempty"`
 	OutputLines  LineRange `json:"outputLines,omitempty"`
+	// CoveredLineSet holds source lines measured by an isolated coverage run;
+	// when present it takes precedence over CoveredLines
+	CoveredLineSet []int  `json:"coveredLineSet,omitempty"`
+	CoverageHash   string `json:"coverageHash,omitempty"` // hash of the sources CoveredLineSet was measured against
+	Origin         string `json:"origin,omitempty"`       // "coverage-run" for references created by the runner
+	// Cases lists the table-driven cases or t.Run subtests of the test
+	Cases []TestCase `json:"cases,omitempty"`
+	// Fixtures lists the testdata and golden files the test reads
+	Fixtures []FixtureLink `json:"fixtures,omitempty"`
+	// Fingerprints of the referenced ranges at submit time, keyed by field
+	// name (lineRange, coveredLines, inputLines, outputLines)
+	Fingerprints map[string]Fingerprint `json:"fingerprints,omitempty"`
+	Stale        bool                   `json:"stale,omitempty"`     // computed on read
+	StaleHint    string                 `json:"staleHint,omitempty"` // computed on read
+}
+
+// TestCase is a single table entry or subtest of a test function
+type TestCase struct {
+	Name        string    `json:"name"`
+	LineRange   LineRange `json:"lineRange"`
+	InputLines  LineRange `json:"inputLines,omitempty"`
+	OutputLines LineRange `json:"outputLines,omitempty"`
+}
+
+// FixtureLink is a file a test reads, such as a testdata fixture or a
+// golden file
+type FixtureLink struct {
+	Path   string `json:"path"`             // relative to the base directory
+	Line   int    `json:"line"`             // line of the test file referencing it
+	Golden bool   `json:"golden,omitempty"` // compared against the test's output
+}
+
+// FuzzCorpus is the corpus a fuzz test runs its target with
+type FuzzCorpus struct {
+	Seeds     []FuzzEntry `json:"seeds"`               // f.Add calls
+	Entries   []FuzzEntry `json:"entries"`             // files in testdata/fuzz/<test>
+	Truncated bool        `json:"truncated,omitempty"` // more entries exist on disk
+}
+
+// FuzzEntry is one input of a fuzz corpus
+type FuzzEntry struct {
+	Name   string      `json:"name,omitempty"` // corpus file name
+	Path   string      `json:"path,omitempty"` // corpus file, relative to the base directory
+	Line   int         `json:"line,omitempty"` // line of the f.Add call
+	Values []FuzzValue `json:"values"`
+	Error  string      `json:"error,omitempty"` // the corpus file could not be decoded
+}
+
+// FuzzValue is a typed argument of a fuzz input, rendered as Go source
+type FuzzValue struct {
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// FindResponse for GET /api/find
+type FindResponse struct {
+	Results   []FindResult `json:"results"`
+	Truncated bool         `json:"truncated,omitempty"` // more results matched than the limit
+}
+
+// FindResult is a file or Go symbol matching a fuzzy query
+type FindResult struct {
+	Kind       string `json:"kind"` // "file" or "symbol"
+	Path       string `json:"path"`
+	Name       string `json:"name,omitempty"`       // symbol name; Recv.Method for methods
+	SymbolKind string `json:"symbolKind,omitempty"` // func, method, type, const or var
+	Line       int    `json:"line,omitempty"`       // line of the symbol's declaration
+	Score      int    `json:"score"`
+	Positions  []int  `json:"positions"` // matched character indexes in the path or name
+}
+
+// SearchResponse for GET /api/search
+type SearchResponse struct {
+	Matches       []SearchMatch `json:"matches"`
+	FilesSearched int           `json:"filesSearched"`       // files the index could not rule out
+	Truncated     bool          `json:"truncated,omitempty"` // the limit was reached
+}
+
+// SearchMatch is a line matching a search
+type SearchMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Column int      `json:"column"` // 1-based, in characters
+	Length int      `json:"length"` // in characters
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"` // context lines before the match
+	After  []string `json:"after,omitempty"`  // context lines after the match
+}
+
+// DetectedLines are the input and output lines of a test found by analyzing
+// its source
+type DetectedLines struct {
+	InputLines  LineRange `json:"inputLines"`
+	OutputLines LineRange `json:"outputLines"`
+	Assertions  []int     `json:"assertions,omitempty"` // first line of each assertion site
 }
 
 // LineRange specifies a range of lines
@@ -63,8 +229,9 @@ type LineRange struct {
 
 // ListFilesResponse for GET /api/files
 type ListFilesResponse struct {
-	Path  string      `json:"path"`
-	Files []FileEntry `json:"files"`
+	Path     string           `json:"path"`
+	Files    []FileEntry      `json:"files"`
+	Coverage *CoverageSummary `json:"coverage,omitempty"` // rollup of the listed directory
 }
 
 // FileResponse for GET /api/files/{path}
@@ -74,17 +241,50 @@ type FileResponse struct {
 
 // TestDetail contains full test information
 type TestDetail struct {
-	FunctionName   string    `json:"functionName"`
-	TestFile       string    `json:"testFile"`
-	TestName       string    `json:"testName"`
-	Comment        string    `json:"comment,omitempty"`
-	Content        string    `json:"content"`
-	LineRange      LineRange `json:"lineRange"`
-	CoveredLines   LineRange `json:"coveredLines"`
-	InputData      string    `json:"inputData,omitempty"`
-	InputLines     LineRange `json:"inputLines,omitempty"`
-	ExpectedOutput string    `json:"expectedOutput,omitempty"`
-	OutputLines    LineRange `json:"outputLines,omitempty"`
+	FunctionName   string           `json:"functionName"`
+	TestFile       string           `json:"testFile"`
+	TestName       string           `json:"testName"`
+	Comment        string           `json:"comment,omitempty"`
+	Content        string           `json:"content"`
+	LineRange      LineRange        `json:"lineRange"`
+	CoveredLines   LineRange        `json:"coveredLines"`
+	InputData      string           `json:"inputData,omitempty"`
+	InputLines     LineRange        `json:"inputLines,omitempty"`
+	ExpectedOutput string           `json:"expectedOutput,omitempty"`
+	OutputLines    LineRange        `json:"outputLines,omitempty"`
+	CoveredLineSet []int            `json:"coveredLineSet,omitempty"`
+	Result         *TestResult      `json:"result,omitempty"`
+	Cases          []TestCaseDetail `json:"cases,omitempty"`
+	Fixtures       []FixtureDetail  `json:"fixtures,omitempty"`
+	Fuzz           *FuzzCorpus      `json:"fuzz,omitempty"` // corpus of a Fuzz test
+	Stale          bool             `json:"stale,omitempty"`
+	StaleHint      string           `json:"staleHint,omitempty"`
+	Redactions     []Redaction      `json:"redactions,omitempty"` // secrets replaced in the test content
+}
+
+// TestCaseDetail contains full information about a single test case
+type TestCaseDetail struct {
+	Name           string      `json:"name"`
+	LineRange      LineRange   `json:"lineRange"`
+	InputData      string      `json:"inputData,omitempty"`
+	InputLines     LineRange   `json:"inputLines,omitempty"`
+	ExpectedOutput string      `json:"expectedOutput,omitempty"`
+	OutputLines    LineRange   `json:"outputLines,omitempty"`
+	Result         *TestResult `json:"result,omitempty"`
+}
+
+// FixtureDetail is a fixture link with a preview of the file's content
+type FixtureDetail struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Golden    bool   `json:"golden,omitempty"`
+	Exists    bool   `json:"exists"`
+	Size      int64  `json:"size,omitempty"`
+	Binary    bool   `json:"binary,omitempty"`
+	Preview   string `json:"preview,omitempty"`   // leading text of the file
+	Truncated bool   `json:"truncated,omitempty"` // the preview is shorter than the file
+
+	Redactions []Redaction `json:"redactions,omitempty"` // secrets replaced in the preview
 }
 
 // TestsResponse for GET /api/files/{path}/tests
@@ -101,7 +301,41 @@ type TestSuggestion struct {
 	Reason        string    `json:"reason"`
 	SuggestedName string    `json:"suggestedName"`
 	TestSkeleton  string    `json:"testSkeleton"`
-	Priority      string    `json:"priority"` // high, medium, low
+	Priority      string    `json:"priority"`         // high, medium, low
+	Origin        string    `json:"origin,omitempty"` // "generated" for suggestions created by the skeleton scanner
+	// Check is the result of type-checking TestSkeleton as a _test.go file
+	// of the source file's package; nil until checked
+	Check *SkeletonCheck `json:"check,omitempty"`
+}
+
+// MaterializeRequest for POST /api/files/{path}/suggestions/materialize
+type MaterializeRequest struct {
+	SuggestedName string `json:"suggestedName"`
+	Apply         bool   `json:"apply"` // write the patch to the working tree
+}
+
+// MaterializeResponse is a suggestion inserted into its test file
+type MaterializeResponse struct {
+	SourceFile    string `json:"sourceFile"`
+	SuggestedName string `json:"suggestedName"`
+	TestFile      string `json:"testFile"`
+	Created       bool   `json:"created"` // the test file does not exist yet
+	Diff          string `json:"diff"`    // unified diff against the current test file
+	Applied       bool   `json:"applied"`
+}
+
+// SkeletonCheck is the result of type-checking a test skeleton
+type SkeletonCheck struct {
+	Compiles    bool                 `json:"compiles"`
+	Diagnostics []SkeletonDiagnostic `json:"diagnostics,omitempty"`
+	CheckedAt   time.Time            `json:"checkedAt"`
+}
+
+// SkeletonDiagnostic is a parse or type error in a test skeleton
+type SkeletonDiagnostic struct {
+	Line    int    `json:"line"` // 1-based line in the skeleton text, 0 when outside it
+	Column  int    `json:"column"`
+	Message string `json:"message"`
 }
 
 // SuggestionsResponse for GET /api/files/{path}/suggestions
@@ -141,7 +375,8 @@ type ExportContextResponse struct {
 	CodeContext []CodeContextBlock `json:"codeContext"`
 	Tests       []TestDetail       `json:"tests,omitempty"`
 	Suggestions []TestSuggestion   `json:"suggestions,omitempty"`
-	Formatted   string             `json:"formatted"` // ready-to-copy format
+	Formatted   string             `json:"formatted"`            // ready-to-copy format
+	Redactions  []Redaction        `json:"redactions,omitempty"` // secrets replaced in the source and test content
 }
 
 // CodeContextBlock represents a code section with its comments
@@ -150,3 +385,232 @@ type CodeContextBlock struct {
 	Code      string    `json:"code"`
 	Comments  []Comment `json:"comments"`
 }
+
+// CoverageImportResponse for POST /api/coverage
+type CoverageImportResponse struct {
+	Mode    string   `json:"mode"`
+	Files   []string `json:"files"`
+	Blocks  int      `json:"blocks"`
+	Skipped []string `json:"skipped,omitempty"` // profile entries outside the base directory
+}
+
+// TestResultsImportResponse for POST /api/test-results
+type TestResultsImportResponse struct {
+	Passed    int      `json:"passed"`
+	Failed    int      `json:"failed"`
+	Skipped   int      `json:"skipped"`
+	Unmatched []string `json:"unmatched,omitempty"` // tests whose source file could not be located
+}
+
+// TestID identifies a test function
+type TestID struct {
+	TestFile string `json:"testFile"`
+	TestName string `json:"testName"`
+}
+
+// ChangeImpactResponse for GET /api/changes
+type ChangeImpactResponse struct {
+	Base  string             `json:"base"`
+	Head  string             `json:"head,omitempty"` // empty means the working tree
+	Files []FileChangeImpact `json:"files"`
+}
+
+// FileChangeImpact lists the changed ranges of a file and how they are tested
+type FileChangeImpact struct {
+	Path          string         `json:"path"`
+	Changes       []ChangeImpact `json:"changes"`
+	UntestedLines []int          `json:"untestedLines"`
+}
+
+// ChangeImpact describes a single changed line range
+type ChangeImpact struct {
+	Lines         LineRange `json:"lines"`
+	UntestedLines []int     `json:"untestedLines"`
+	Tests         []TestID  `json:"tests"` // tests covering at least one changed line
+}
+
+// ImpactRequest for POST /api/impact
+type ImpactRequest struct {
+	Files   []string     `json:"files,omitempty"`   // files changed as a whole
+	Changes []FileChange `json:"changes,omitempty"` // specific changed line ranges
+}
+
+// FileChange lists changed line ranges of a file
+type FileChange struct {
+	Path  string      `json:"path"`
+	Lines []LineRange `json:"lines"`
+}
+
+// ImpactResponse lists the tests affected by a change
+type ImpactResponse struct {
+	Tests    []TestID       `json:"tests"`
+	Packages []PackageTests `json:"packages"`
+}
+
+// PackageTests groups affected tests by Go package with a ready-to-run command
+type PackageTests struct {
+	Package    string   `json:"package"` // import path
+	Dir        string   `json:"dir"`     // package directory relative to the base directory
+	Tests      []string `json:"tests"`
+	RunPattern string   `json:"runPattern"` // value for go test -run
+	Command    string   `json:"command"`    // go test invocation, run from the module root
+}
+
+// DiscoveryRequest for POST /api/discover
+type DiscoveryRequest struct {
+	Path  string `json:"path"`  // directory to scan, relative to the base directory
+	Apply bool   `json:"apply"` // store proposals that do not exist yet
+}
+
+// DiscoveryResponse lists discovered test files and proposed test references
+type DiscoveryResponse struct {
+	TestFiles []DiscoveredTestFile `json:"testFiles"`
+	Proposals []ProposedTests      `json:"proposals"`
+	Applied   int                  `json:"applied"`
+}
+
+// DiscoveredTestFile is a test file located by a discovery analyzer
+type DiscoveredTestFile struct {
+	Path     string           `json:"path"`
+	Language string           `json:"language"`
+	Tests    []DiscoveredTest `json:"tests"`
+}
+
+// DiscoveredTest is a test located in a test file
+type DiscoveredTest struct {
+	Name      string    `json:"name"`
+	LineRange LineRange `json:"lineRange"`
+}
+
+// ProposedTests groups proposed test references by source file
+type ProposedTests struct {
+	SourceFile string          `json:"sourceFile"`
+	Tests      []TestReference `json:"tests"`
+}
+
+// GenerateSuggestionsRequest for POST /api/suggestions/generate
+type GenerateSuggestionsRequest struct {
+	Path  string `json:"path"`  // directory to scan, relative to the base directory
+	Apply bool   `json:"apply"` // store the generated suggestions
+}
+
+// GenerateSuggestionsResponse lists a suggestion for every Go function
+// without test references
+type GenerateSuggestionsResponse struct {
+	Suggestions []TestSuggestion `json:"suggestions"`
+	Applied     int              `json:"applied"`
+}
+
+// Function coverage statuses
+const (
+	FunctionDirect   = "direct"   // a test calls the function itself or names it in its metadata
+	FunctionIndirect = "indirect" // tests reach the function only through other functions
+	FunctionUntested = "untested"
+)
+
+// FunctionCoverage reports how the tests reach one function
+type FunctionCoverage struct {
+	Name      string         `json:"name"` // "Receiver.Method" or "Func"
+	LineRange LineRange      `json:"lineRange"`
+	Status    string         `json:"status"`
+	Tests     []ReachingTest `json:"tests"`
+}
+
+// ReachingTest is a test reaching a function through the call graph
+type ReachingTest struct {
+	TestFile string `json:"testFile"`
+	TestName string `json:"testName"`
+	Depth    int    `json:"depth"` // 1 when the test calls the function itself
+}
+
+// Mutant statuses
+const (
+	MutantKilled   = "killed"   // a mapped test failed
+	MutantSurvived = "survived" // every mapped test still passed
+	MutantTimeout  = "timeout"  // the tests did not finish; counted as killed
+	MutantInvalid  = "invalid"  // the mutated code does not compile
+)
+
+// MutationRun is the outcome of mutation testing one function
+type MutationRun struct {
+	Function  string         `json:"function"`
+	LineRange LineRange      `json:"lineRange"`
+	Tests     []string       `json:"tests"` // tests run against every mutant
+	Mutants   []MutantResult `json:"mutants"`
+	Killed    int            `json:"killed"`
+	Survived  int            `json:"survived"`
+	Invalid   int            `json:"invalid"`
+	Score     float64        `json:"score"` // killed / (killed + survived)
+	RunAt     time.Time      `json:"runAt"`
+}
+
+// MutantResult is the outcome of one mutant
+type MutantResult struct {
+	Line     int      `json:"line"`
+	Operator string   `json:"operator"` // conditional, arithmetic or return
+	Original string   `json:"original"`
+	Mutated  string   `json:"mutated"`
+	Status   string   `json:"status"`
+	KilledBy []string `json:"killedBy,omitempty"` // failing tests
+	Error    string   `json:"error,omitempty"`    // why an invalid mutant could not be tested
+}
+
+// LineMutations summarizes the mutants of one line
+type LineMutations struct {
+	Killed    int      `json:"killed"`
+	Survived  int      `json:"survived"`
+	Survivors []string `json:"survivors,omitempty"` // "original -> mutated" of surviving mutants
+}
+
+// RunTestsRequest for POST /api/tests/run
+type RunTestsRequest struct {
+	Tests []TestID `json:"tests"`
+}
+
+// TestOutputEvent is streamed for every line of go test output
+type TestOutputEvent struct {
+	Package string `json:"package,omitempty"`
+	Test    string `json:"test,omitempty"`
+	Output  string `json:"output"`
+}
+
+// TestResultEvent is streamed when a test finishes
+type TestResultEvent struct {
+	Package string  `json:"package"`
+	Test    string  `json:"test"`
+	Status  string  `json:"status"` // pass, fail or skip
+	Elapsed float64 `json:"elapsed"`
+}
+
+// Redaction is a secret replaced before content was handed out. The secret
+// itself is never reported.
+type Redaction struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"` // e.g. private-key, aws-access-key, high-entropy
+}
+
+// ChangeEvent is streamed when a file under the base directory or its
+// stored metadata changes
+type ChangeEvent struct {
+	Type    string `json:"type"`              // created, modified, deleted, renamed or metadata
+	Path    string `json:"path"`              // slash-separated, relative to the base directory
+	OldPath string `json:"oldPath,omitempty"` // renamed: the path before the rename
+	Dir     bool   `json:"dir,omitempty"`
+}
+
+// CoverageSummary aggregates test metadata for a file or every file below a
+// directory. Function and line counts cover source files with metadata only.
+type CoverageSummary struct {
+	FilesWithMetadata   int      `json:"filesWithMetadata"`
+	TestedFunctions     int      `json:"testedFunctions"`
+	UntestedFunctions   int      `json:"untestedFunctions"`
+	FunctionLines       int      `json:"functionLines"`       // lines inside function declarations
+	DeclaredLines       int      `json:"declaredLines"`       // function lines covered by test metadata
+	DeclaredPercent     float64  `json:"declaredPercent"`     // DeclaredLines / FunctionLines
+	RealCoveredLines    int      `json:"realCoveredLines"`    // from imported cover profiles
+	RealExecutableLines int      `json:"realExecutableLines"` // lines inside profiled statement blocks
+	RealPercent         *float64 `json:"realPercent,omitempty"`
+	OpenSuggestions     int      `json:"openSuggestions"`
+	UnresolvedComments  int      `json:"unresolvedComments"`
+}
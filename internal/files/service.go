@@ -1,31 +1,82 @@
 package files
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"mime"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"codebase-view-mcp/internal/ignore"
+)
+
+// DefaultMaxFileSize is the default cap on the content returned for a file
+const DefaultMaxFileSize = 2 << 20
+
+// Errors of the file service
+var (
+	// ErrInvalidRange is returned for line ranges that do not fit a file
+	ErrInvalidRange = errors.New("invalid line range")
+	// ErrForbidden is returned for paths outside the base directory
+	ErrForbidden = errors.New("path is outside the base directory")
 )
 
 // Service handles file system operations
 type Service struct {
-	baseDir string
+	baseDir       string
+	realBaseDir   string // baseDir with its symlinks resolved
+	maxFileSize   int64
+	allowSymlinks bool
+
+	linesMu sync.Mutex
+	lines   map[string]*lineIndex // by resolved path
 }
 
 // NewService creates a new file service
 func NewService(baseDir string) *Service {
+	realBaseDir, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		realBaseDir = baseDir
+	}
+
 	return &Service{
-		baseDir: baseDir,
+		baseDir:     baseDir,
+		realBaseDir: realBaseDir,
+		maxFileSize: DefaultMaxFileSize,
+		lines:       make(map[string]*lineIndex),
 	}
 }
 
+// SetMaxFileSize sets the cap on the content returned for a file; 0 removes
+// it. It must be called before the service is used.
+func (s *Service) SetMaxFileSize(n int64) {
+	s.maxFileSize = n
+}
+
+// SetAllowSymlinks lets paths follow symlinks to targets outside the base
+// directory. It must be called before the service is used.
+func (s *Service) SetAllowSymlinks(allow bool) {
+	s.allowSymlinks = allow
+}
+
+// BaseDir returns the absolute directory the service serves files from
+func (s *Service) BaseDir() string {
+	return s.baseDir
+}
+
 // ListFiles lists files and directories in the specified path
 func (s *Service) ListFiles(path string) (*ListFilesResponse, error) {
 	// Resolve the full path
-	fullPath := s.resolvePath(path)
+	fullPath, err := s.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if path exists and is a directory
 	info, err := os.Stat(fullPath)
@@ -51,8 +102,8 @@ func (s *Service) ListFiles(path string) (*ListFilesResponse, error) {
 			continue
 		}
 
-		// Skip hidden files (starting with .)
-		if strings.HasPrefix(entry.Name(), ".") {
+		// Skip hidden and ignored files
+		if ignore.For(s.baseDir).IgnoredPath(filepath.Join(fullPath, entry.Name()), entry.IsDir()) {
 			continue
 		}
 
@@ -79,33 +130,135 @@ func (s *Service) ListFiles(path string) (*ListFilesResponse, error) {
 	}, nil
 }
 
-// ReadFile reads the content of a file
+// ReadFile reads the content of a file. Binary files are returned without
+// content; text files larger than the size cap are cut at the last line
+// that fits and flagged as truncated.
 func (s *Service) ReadFile(path string) (*FileContent, error) {
-	// Resolve the full path
-	fullPath := s.resolvePath(path)
+	file, info, err := s.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-	// Check if file exists
-	info, err := os.Stat(fullPath)
+	fileContent := s.describe(path, info)
+
+	limit := info.Size()
+	if s.maxFileSize > 0 && limit > s.maxFileSize {
+		limit = s.maxFileSize
+	}
+	content, err := io.ReadAll(io.LimitReader(file, limit))
 	if err != nil {
-		return nil, fmt.Errorf("file not found: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	if info.IsDir() {
-		return nil, fmt.Errorf("path is a directory, not a file")
+	if IsBinary(content) {
+		fileContent.Binary = true
+		return fileContent, nil
 	}
 
-	// Read file content
-	file, err := os.Open(fullPath)
+	if int64(len(content)) < info.Size() {
+		content = cutAtLine(content)
+		fileContent.Truncated = true
+	}
+	fileContent.Content = string(content)
+	return fileContent, nil
+}
+
+// ReadLines reads lines start through end (1-based, inclusive) of a text
+// file, seeking through a cached index of line offsets instead of reading
+// the lines before start. An end of 0 or past the last line reads to the end
+// of the file, and an empty file reads as no lines from line 1. The size cap
+// applies to the returned lines.
+func (s *Service) ReadLines(path string, start, end int) (*FileContent, error) {
+	if start < 1 || (end != 0 && end < start) {
+		return nil, fmt.Errorf("%w: start must be at least 1 and end at least start", ErrInvalidRange)
+	}
+
+	file, info, err := s.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	content, err := io.ReadAll(file)
+	fileContent := s.describe(path, info)
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if IsBinary(head[:n]) {
+		fileContent.Binary = true
+		return fileContent, nil
+	}
+
+	offsets, err := s.lineOffsets(file, info)
 	if err != nil {
+		return nil, fmt.Errorf("failed to index lines: %w", err)
+	}
+	total := len(offsets)
+	if total == 0 && start == 1 {
+		// An empty file has no lines, but reading it from the top is valid
+		fileContent.StartLine = start
+		return fileContent, nil
+	}
+	if start > total {
+		return nil, fmt.Errorf("%w: start line %d is past the end of the file (%d lines)", ErrInvalidRange, start, total)
+	}
+	if end == 0 || end > total {
+		end = total
+	}
+
+	from, to := offsets[start-1], info.Size()
+	if end < total {
+		to = offsets[end]
+	}
+	limit := to - from
+	if s.maxFileSize > 0 && limit > s.maxFileSize {
+		limit = s.maxFileSize
+	}
+
+	content := make([]byte, limit)
+	if _, err := file.ReadAt(content, from); err != nil && err != io.EOF {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	if limit < to-from {
+		content = cutAtLine(content)
+		fileContent.Truncated = true
+		end = start + max(bytes.Count(content, []byte("\n"))-1, 0)
+	}
+
+	fileContent.Content = string(content)
+	fileContent.StartLine = start
+	fileContent.EndLine = end
+	fileContent.TotalLines = total
+	return fileContent, nil
+}
+
+// Open opens a regular file for reading
+func (s *Service) Open(path string) (*os.File, os.FileInfo, error) {
+	fullPath, err := s.Resolve(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file not found: %w", err)
+	}
+	if info.IsDir() {
+		return nil, nil, fmt.Errorf("path is a directory, not a file")
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, info, nil
+}
 
+// describe returns the content-less description of a file
+func (s *Service) describe(path string, info os.FileInfo) *FileContent {
 	// Determine MIME type
 	mimeType := mime.TypeByExtension(filepath.Ext(path))
 	if mimeType == "" {
@@ -115,27 +268,86 @@ func (s *Service) ReadFile(path string) (*FileContent, error) {
 	return &FileContent{
 		Path:     path,
 		Name:     filepath.Base(path),
-		Content:  string(content),
 		Size:     info.Size(),
 		ModTime:  info.ModTime(),
 		MimeType: mimeType,
-	}, nil
+	}
+}
+
+// cutAtLine drops the partial last line of a truncated read. A single line
+// longer than the cap is kept cut.
+func cutAtLine(content []byte) []byte {
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[:i+1]
+	}
+	// Do not cut a multi-byte rune in half
+	for len(content) > 0 && !utf8.RuneStart(content[len(content)-1]) {
+		content = content[:len(content)-1]
+	}
+	if len(content) > 0 && !utf8.FullRune(content[len(content)-1:]) {
+		content = content[:len(content)-1]
+	}
+	return content
 }
 
-// resolvePath resolves a relative path to an absolute path within baseDir
-func (s *Service) resolvePath(path string) string {
+// Resolve returns the absolute path of a path relative to the base
+// directory. Absolute paths, paths climbing out of the base directory, paths
+// reaching outside of it through a symlink and paths on the deny-list of the
+// ignore configuration are refused with ErrForbidden; symlinks may leave the
+// base directory only when SetAllowSymlinks is on.
+func (s *Service) Resolve(path string) (string, error) {
 	if path == "" || path == "." {
-		return s.baseDir
+		return s.baseDir, nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(path)) {
+		return "", fmt.Errorf("%w: %s", ErrForbidden, path)
+	}
+
+	denied := ignore.For(s.baseDir)
+	if denied.Denied(path) {
+		return "", fmt.Errorf("%w: %s is on the deny-list", ErrForbidden, path)
 	}
 
-	// Clean the path to prevent directory traversal
-	cleanPath := filepath.Clean(path)
+	fullPath := filepath.Join(s.baseDir, filepath.FromSlash(path))
+	realPath, err := evalSymlinks(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	rel, err := filepath.Rel(s.realBaseDir, realPath)
+	inside := err == nil && (rel == "." || filepath.IsLocal(rel))
+	switch {
+	case !inside && !s.allowSymlinks:
+		return "", fmt.Errorf("%w: %s", ErrForbidden, path)
+	case inside && denied.Denied(rel):
+		// A symlink to a denied file
+		return "", fmt.Errorf("%w: %s is on the deny-list", ErrForbidden, path)
+	}
+	return fullPath, nil
+}
+
+// evalSymlinks resolves the symlinks of a path that may not exist yet: the
+// missing part is appended to its nearest existing ancestor, and a dangling
+// symlink resolves to where it points
+func evalSymlinks(path string) (string, error) {
+	realPath, err := filepath.EvalSymlinks(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return realPath, err
+	}
 
-	// If it's already absolute, use it directly (for development)
-	if filepath.IsAbs(cleanPath) {
-		return cleanPath
+	if target, err := os.Readlink(path); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		return evalSymlinks(target)
 	}
 
-	// Otherwise, join with baseDir
-	return filepath.Join(s.baseDir, cleanPath)
+	dir := filepath.Dir(path)
+	if dir == path {
+		return path, nil
+	}
+	realDir, err := evalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(realDir, filepath.Base(path)), nil
 }
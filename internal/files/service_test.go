@@ -0,0 +1,70 @@
+package files
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServiceResolve(t *testing.T) {
+	root := t.TempDir()
+	baseDir := filepath.Join(root, "base")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{filepath.Join(baseDir, "pkg"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	for _, file := range []string{filepath.Join(baseDir, "pkg", "a.go"), filepath.Join(outside, "secret.txt")} {
+		if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	links := map[string]string{
+		"inside":   filepath.Join(baseDir, "pkg"),
+		"escape":   outside,
+		"dangling": filepath.Join(outside, "missing.txt"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(baseDir, name)); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		allowSymlinks bool
+		forbidden     bool
+	}{
+		{name: "base directory", path: "."},
+		{name: "file", path: "pkg/a.go"},
+		{name: "missing file", path: "pkg/missing.go"},
+		{name: "dot dot inside", path: "pkg/../pkg/a.go"},
+		{name: "absolute", path: filepath.Join(outside, "secret.txt"), forbidden: true},
+		{name: "climbing out", path: "../outside/secret.txt", forbidden: true},
+		{name: "climbing out below", path: "pkg/../../outside/secret.txt", forbidden: true},
+		{name: "symlink inside", path: "inside/a.go"},
+		{name: "symlink outside", path: "escape/secret.txt", forbidden: true},
+		{name: "missing file behind a symlink outside", path: "escape/new.txt", forbidden: true},
+		{name: "dangling symlink outside", path: "dangling", forbidden: true},
+		{name: "symlink outside allowed", path: "escape/secret.txt", allowSymlinks: true},
+		{name: "climbing out with symlinks allowed", path: "../outside/secret.txt", allowSymlinks: true, forbidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(baseDir)
+			s.SetAllowSymlinks(tt.allowSymlinks)
+
+			_, err := s.Resolve(tt.path)
+			if got := errors.Is(err, ErrForbidden); got != tt.forbidden {
+				t.Fatalf("Resolve(%q) error = %v, want forbidden %v", tt.path, err, tt.forbidden)
+			}
+			if !tt.forbidden && err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.path, err)
+			}
+		})
+	}
+}
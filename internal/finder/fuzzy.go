@@ -0,0 +1,163 @@
+package finder
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scores of a fuzzy match, in the spirit of fzf: every matched character
+// scores, characters at word starts and in runs score more, and gaps cost
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 10 // after "/", "_", "-", "." or a space, or at the start
+	bonusCamel       = 8  // an upper-case letter following a lower-case one
+	bonusConsecutive = 8
+	bonusCase        = 1  // the query character has the same case
+	bonusBaseName    = 24 // every match lies in the last path element
+	penaltyGapStart  = 3
+	penaltyGap       = 1
+)
+
+// match fuzzy-matches a query against a target: the query's characters must
+// appear in the target in order, ignoring case. It returns the score and the
+// matched character positions of the best alignment found.
+func match(query, target string) (int, []int, bool) {
+	q := []rune(query)
+	t := []rune(target)
+	if len(q) == 0 || len(q) > len(t) {
+		return 0, nil, false
+	}
+
+	// Find the first alignment, then walk back from its end to the latest
+	// possible start, which gives the tightest window ending there
+	end := -1
+	for i, j := 0, 0; i < len(t); i++ {
+		if fold(t[i]) == fold(q[j]) {
+			j++
+			if j == len(q) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	start := end
+	for i, j := end, len(q)-1; i >= 0; i-- {
+		if fold(t[i]) == fold(q[j]) {
+			j--
+			if j < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// Within the window, prefer word starts: align each query character
+	// greedily but jump to a later word start when one still leaves room for
+	// the rest of the query and the character does not continue a run
+	positions := make([]int, 0, len(q))
+	for i, j := start, 0; j < len(q) && i <= end; i++ {
+		if fold(t[i]) != fold(q[j]) {
+			continue
+		}
+		continues := j > 0 && positions[j-1] == i-1
+		if !continues && !isWordStart(t, i) {
+			if k := nextWordStart(t, q[j], i+1, end); k >= 0 && fits(t, q[j+1:], k+1, end) {
+				i = k
+			}
+		}
+		positions = append(positions, i)
+		j++
+	}
+	if len(positions) != len(q) {
+		return 0, nil, false
+	}
+
+	return score(q, t, positions), positions, true
+}
+
+// score rates an alignment of the query in the target
+func score(q, t []rune, positions []int) int {
+	total := 0
+	for j, i := range positions {
+		total += scoreMatch
+		if isBoundary(t, i) {
+			total += bonusBoundary
+		} else if i > 0 && unicode.IsLower(t[i-1]) && unicode.IsUpper(t[i]) {
+			total += bonusCamel
+		}
+		if q[j] == t[i] {
+			total += bonusCase
+		}
+		if j > 0 {
+			if gap := i - positions[j-1] - 1; gap == 0 {
+				total += bonusConsecutive
+			} else {
+				total -= penaltyGapStart + penaltyGap*min(gap, 20)
+			}
+		}
+	}
+
+	if lastSlash := lastIndexRune(t, '/'); positions[0] > lastSlash {
+		total += bonusBaseName
+	}
+	// Among equal matches, shorter targets are closer to what was typed
+	total -= len(t) / 16
+	return total
+}
+
+// nextWordStart returns the first word start in t[from:to] equal to c
+func nextWordStart(t []rune, c rune, from, to int) int {
+	for i := from; i <= to; i++ {
+		if fold(t[i]) == fold(c) && isWordStart(t, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fits reports whether q can still be aligned in t[from:to]
+func fits(t, q []rune, from, to int) bool {
+	j := 0
+	for i := from; i <= to && j < len(q); i++ {
+		if fold(t[i]) == fold(q[j]) {
+			j++
+		}
+	}
+	return j == len(q)
+}
+
+// isBoundary reports whether position i follows a separator or starts t
+func isBoundary(t []rune, i int) bool {
+	return i == 0 || strings.ContainsRune("/_-. ", t[i-1])
+}
+
+// isWordStart reports whether position i starts a word, including the
+// upper-case start of a camel-case word
+func isWordStart(t []rune, i int) bool {
+	return isBoundary(t, i) || unicode.IsLower(t[i-1]) && unicode.IsUpper(t[i])
+}
+
+// lastIndexRune returns the index of the last r in t, or -1
+func lastIndexRune(t []rune, r rune) int {
+	for i := len(t) - 1; i >= 0; i-- {
+		if t[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// fold maps a rune to lower case
+func fold(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}
+	return unicode.ToLower(r)
+}
@@ -0,0 +1,245 @@
+package finder
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// Limits of a find
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// refreshInterval is how long the index is trusted before the file tree is
+// checked for changes again
+const refreshInterval = 2 * time.Second
+
+// recencyBonus is the score a file modified just now gets on top of its
+// match score; it halves with every day of age
+const recencyBonus = 24
+
+// Kinds of results
+const (
+	KindFile   = "file"
+	KindSymbol = "symbol"
+)
+
+// entry is an indexed file and the Go symbols it declares
+type entry struct {
+	modTime time.Time
+	size    int64
+	symbols []symbol
+}
+
+// symbol is a top-level Go declaration
+type symbol struct {
+	name string // Recv.Method for methods
+	kind string // func, method, type, const or var
+	line int
+}
+
+// Index holds the paths under a base directory and the symbols of its Go
+// files. Only files whose size or modification time changed are parsed
+// again when the index is refreshed.
+type Index struct {
+	baseDir string
+
+	mu        sync.Mutex
+	files     map[string]*entry // by slash-separated path relative to baseDir
+	refreshed time.Time
+	stale     bool // a change was reported since the last refresh
+}
+
+// New creates an empty index of baseDir; it is filled by the first find
+func New(baseDir string) *Index {
+	return &Index{baseDir: baseDir, files: make(map[string]*entry)}
+}
+
+// Invalidate makes the next find check the file tree for changes without
+// waiting for the refresh interval
+func (x *Index) Invalidate() {
+	x.mu.Lock()
+	x.stale = true
+	x.mu.Unlock()
+}
+
+// Find fuzzy-matches a query against file paths and Go symbol names. kind
+// restricts the results to KindFile or KindSymbol when not empty. Results
+// are ranked by match score plus a bonus for recently modified files.
+func (x *Index) Find(query, kind string, limit int) *files.FindResponse {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+
+	query = strings.Join(strings.Fields(query), "")
+	now := time.Now()
+	var results []files.FindResult
+	for path, e := range x.files {
+		recency := recencyScore(now.Sub(e.modTime))
+
+		if kind == "" || kind == KindFile {
+			if score, positions, ok := match(query, path); ok {
+				results = append(results, files.FindResult{
+					Kind:      KindFile,
+					Path:      path,
+					Score:     score + recency,
+					Positions: positions,
+				})
+			}
+		}
+
+		if kind == "" || kind == KindSymbol {
+			for _, sym := range e.symbols {
+				if score, positions, ok := match(query, sym.name); ok {
+					results = append(results, files.FindResult{
+						Kind:       KindSymbol,
+						Path:       path,
+						Name:       sym.name,
+						SymbolKind: sym.kind,
+						Line:       sym.line,
+						Score:      score + recency,
+						Positions:  positions,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Path)+len(a.Name) != len(b.Path)+len(b.Name) {
+			return len(a.Path)+len(a.Name) < len(b.Path)+len(b.Name)
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+
+	response := &files.FindResponse{Results: []files.FindResult{}}
+	if len(results) > limit {
+		results, response.Truncated = results[:limit], true
+	}
+	response.Results = append(response.Results, results...)
+	return response
+}
+
+// recencyScore rates how recently a file was modified
+func recencyScore(age time.Duration) int {
+	days := max(age.Hours()/24, 0)
+	score := float64(recencyBonus)
+	for ; days >= 1 && score >= 1; days-- {
+		score /= 2
+	}
+	return int(score)
+}
+
+// refresh brings the index in sync with the file tree when it is due.
+// The caller holds x.mu.
+func (x *Index) refresh() {
+	if !x.stale && !x.refreshed.IsZero() && time.Since(x.refreshed) < refreshInterval {
+		return
+	}
+
+	seen := make(map[string]bool, len(x.files))
+	ignore.WalkFiles(x.baseDir, func(rel, p string, info fs.FileInfo) {
+		seen[rel] = true
+
+		if e, ok := x.files[rel]; ok && e.modTime.Equal(info.ModTime()) && e.size == info.Size() {
+			return
+		}
+
+		e := &entry{modTime: info.ModTime(), size: info.Size()}
+		if strings.HasSuffix(rel, ".go") {
+			e.symbols = parseSymbols(p)
+		}
+		x.files[rel] = e
+	})
+
+	for rel := range x.files {
+		if !seen[rel] {
+			delete(x.files, rel)
+		}
+	}
+
+	x.refreshed = time.Now()
+	x.stale = false
+}
+
+// parseSymbols returns the top-level declarations of a Go file
+func parseSymbols(path string) []symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	var symbols []symbol
+	add := func(name *ast.Ident, kind string) {
+		if name.Name != "_" {
+			symbols = append(symbols, symbol{name: name.Name, kind: kind, line: fset.Position(name.Pos()).Line})
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				add(d.Name, "func")
+				continue
+			}
+			if recv := receiverName(d.Recv.List[0].Type); recv != "" {
+				symbols = append(symbols, symbol{
+					name: recv + "." + d.Name.Name,
+					kind: "method",
+					line: fset.Position(d.Name.Pos()).Line,
+				})
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, "type")
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						add(name, d.Tok.String())
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverName returns the type name of a method receiver
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
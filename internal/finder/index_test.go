@@ -0,0 +1,101 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, target string
+		positions     []int
+	}{
+		{"hand", "internal/api/handlers.go", []int{13, 14, 15, 16}},
+		{"fsvc", "internal/files/service.go", []int{9, 15, 18, 20}},
+		{"lf", "Service.ListFiles", []int{8, 12}},
+		{"xyz", "internal/files/service.go", nil},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := match(tt.query, tt.target)
+		if ok != (tt.positions != nil) || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("match(%q, %q) = %v, %v, want %v", tt.query, tt.target, positions, ok, tt.positions)
+		}
+	}
+
+	// Word starts and the base name outrank scattered matches
+	better, _, _ := match("svc", "internal/files/svc.go")
+	worse, _, _ := match("svc", "internal/files/service_test.go")
+	if better <= worse {
+		t.Errorf("score of svc.go = %d, want more than service_test.go (%d)", better, worse)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, modTime time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	write("files/service.go", "package files\n\ntype Service struct{}\n\nfunc (s *Service) ListFiles() {}\n\nconst maxSize = 1\n", old)
+	write("files/models.go", "package files\n\ntype FileEntry struct{}\n", old)
+	write("node_modules/list/index.js", "", old)
+	write("certs/server.pem", "", old)
+
+	x := New(dir)
+
+	t.Run("symbols", func(t *testing.T) {
+		got := x.Find("listfiles", KindSymbol, 0)
+		if len(got.Results) != 1 {
+			t.Fatalf("Find() = %+v, want one symbol", got.Results)
+		}
+		if r := got.Results[0]; r.Name != "Service.ListFiles" || r.SymbolKind != "method" || r.Line != 5 || r.Path != "files/service.go" {
+			t.Fatalf("result = %+v", r)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		got := x.Find("files/s", KindFile, 0)
+		if len(got.Results) != 2 || got.Results[0].Path != "files/service.go" {
+			t.Fatalf("Find() = %+v, want files/service.go before files/models.go", got.Results)
+		}
+	})
+
+	t.Run("denied files", func(t *testing.T) {
+		if got := x.Find("server.pem", KindFile, 0); len(got.Results) != 0 {
+			t.Fatalf("Find() = %+v, want no results", got.Results)
+		}
+	})
+
+	t.Run("recent files rank first", func(t *testing.T) {
+		write("files/mode.go", "package files\n", time.Now())
+		x.Invalidate()
+
+		got := x.Find("mode", KindFile, 1)
+		if len(got.Results) != 1 || got.Results[0].Path != "files/mode.go" || !got.Truncated {
+			t.Fatalf("Find() = %+v, want files/mode.go first of several", got)
+		}
+	})
+
+	t.Run("removed files", func(t *testing.T) {
+		os.Remove(filepath.Join(dir, "files", "models.go"))
+		x.Invalidate()
+
+		if got := x.Find("FileEntry", "", 0); len(got.Results) != 0 {
+			t.Fatalf("Find() = %+v, want no results", got.Results)
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package fuzz
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+)
+
+// header is the first line of every corpus file written by go test
+const header = "go test fuzz v1"
+
+// maxEntries caps the on-disk corpus entries returned for one fuzz test
+const maxEntries = 200
+
+// Load returns the seed corpus and on-disk corpus of a fuzz test. testFile is
+// relative to the base directory of service; the on-disk corpus is read from
+// testdata/fuzz/<test> next to it. Every file is read through service, so
+// corpus entries outside the base directory or on the deny-list are skipped.
+func Load(service *files.Service, testFile, testName string) (*files.FuzzCorpus, error) {
+	content, err := service.ReadFile(testFile)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, testFile, content.Content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	fn := analysis.FindFunc(file, testName)
+	if fn == nil || !analysis.IsTestFunc(fn, "Fuzz") {
+		return nil, fmt.Errorf("fuzz test %s not found in %s", testName, testFile)
+	}
+
+	corpus := &files.FuzzCorpus{
+		Seeds:   Seeds(fset, fn),
+		Entries: []files.FuzzEntry{},
+	}
+
+	dir := path.Join(path.Dir(filepath.ToSlash(testFile)), "testdata", "fuzz", testName)
+	fullDir, err := service.Resolve(dir)
+	if errors.Is(err, files.ErrForbidden) {
+		return corpus, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(fullDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if len(corpus.Entries) == maxEntries {
+			corpus.Truncated = true
+			break
+		}
+
+		item := files.FuzzEntry{
+			Name: entry.Name(),
+			Path: path.Join(dir, entry.Name()),
+		}
+		data, err := service.ReadFile(item.Path)
+		switch {
+		case errors.Is(err, files.ErrForbidden):
+			continue
+		case err != nil:
+			item.Error = err.Error()
+		case data.Binary:
+			item.Error = "binary file"
+		case data.Truncated:
+			item.Error = "file too large"
+		default:
+			item.Values, err = Decode([]byte(data.Content))
+			if err != nil {
+				item.Error = err.Error()
+			}
+		}
+		corpus.Entries = append(corpus.Entries, item)
+	}
+
+	return corpus, nil
+}
+
+// Decode parses a corpus file in the "go test fuzz v1" format: a header line
+// followed by one Go expression per value, each a conversion of a literal to
+// one of the types fuzzing supports, such as string("a") or int(-3).
+func Decode(data []byte) ([]files.FuzzValue, error) {
+	lines := strings.Split(string(bytes.TrimRight(data, "\n")), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != header {
+		return nil, fmt.Errorf("missing %q header", header)
+	}
+
+	values := []files.FuzzValue{}
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		value, err := decodeValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// decodeValue decodes a single typed corpus value
+func decodeValue(line string) (files.FuzzValue, error) {
+	expr, err := parser.ParseExpr(line)
+	if err != nil {
+		return files.FuzzValue{}, fmt.Errorf("malformed value %q", line)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return files.FuzzValue{}, fmt.Errorf("malformed value %q: want a conversion like int(1)", line)
+	}
+
+	// NaN and other float values without an exact literal are written as
+	// math.Float64frombits(0x...) or math.Float32frombits(0x...)
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && identName(sel.X) == "math" {
+		bits, err := literalUint(call.Args[0])
+		if err != nil {
+			return files.FuzzValue{}, err
+		}
+		switch sel.Sel.Name {
+		case "Float64frombits":
+			return files.FuzzValue{Type: "float64", Value: formatFloat(math.Float64frombits(bits), 64)}, nil
+		case "Float32frombits":
+			return files.FuzzValue{Type: "float32", Value: formatFloat(float64(math.Float32frombits(uint32(bits))), 32)}, nil
+		}
+		return files.FuzzValue{}, fmt.Errorf("unsupported value %q", line)
+	}
+
+	typ := typeName(call.Fun)
+	lit, ok := unaryLiteral(call.Args[0])
+	if !ok {
+		return files.FuzzValue{}, fmt.Errorf("malformed value %q: argument is not a literal", line)
+	}
+
+	switch typ {
+	case "string", "[]byte":
+		s, err := strconv.Unquote(lit)
+		if err != nil {
+			return files.FuzzValue{}, fmt.Errorf("malformed %s %s", typ, lit)
+		}
+		return files.FuzzValue{Type: typ, Value: strconv.Quote(s)}, nil
+	case "bool":
+		if lit != "true" && lit != "false" {
+			return files.FuzzValue{}, fmt.Errorf("malformed bool %s", lit)
+		}
+		return files.FuzzValue{Type: typ, Value: lit}, nil
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		n, err := literalInt(lit)
+		if err != nil {
+			return files.FuzzValue{}, fmt.Errorf("malformed %s %s", typ, lit)
+		}
+		return files.FuzzValue{Type: typ, Value: strconv.FormatInt(n, 10)}, nil
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
+		if n, err := strconv.ParseUint(lit, 0, 64); err == nil {
+			return files.FuzzValue{Type: typ, Value: strconv.FormatUint(n, 10)}, nil
+		}
+		n, err := literalInt(lit)
+		if err != nil || n < 0 {
+			return files.FuzzValue{}, fmt.Errorf("malformed %s %s", typ, lit)
+		}
+		return files.FuzzValue{Type: typ, Value: strconv.FormatInt(n, 10)}, nil
+	case "float32", "float64":
+		f, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return files.FuzzValue{}, fmt.Errorf("malformed %s %s", typ, lit)
+		}
+		bitSize := 64
+		if typ == "float32" {
+			bitSize = 32
+		}
+		return files.FuzzValue{Type: typ, Value: formatFloat(f, bitSize)}, nil
+	}
+	return files.FuzzValue{}, fmt.Errorf("unsupported type %q", typ)
+}
+
+// unaryLiteral returns the source of a literal with an optional sign
+func unaryLiteral(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return e.Value, true
+	case *ast.Ident:
+		return e.Name, e.Name == "true" || e.Name == "false"
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.BasicLit); ok && (e.Op == token.SUB || e.Op == token.ADD) {
+			return e.Op.String() + lit.Value, true
+		}
+	}
+	return "", false
+}
+
+// literalInt parses an integer or rune literal
+func literalInt(lit string) (int64, error) {
+	sign := ""
+	if strings.HasPrefix(lit, "-") || strings.HasPrefix(lit, "+") {
+		sign, lit = lit[:1], lit[1:]
+	}
+	if strings.HasPrefix(lit, "'") {
+		r, _, tail, err := strconv.UnquoteChar(lit[1:len(lit)-1], '\'')
+		if err != nil || tail != "" || sign != "" {
+			return 0, fmt.Errorf("malformed rune %s", lit)
+		}
+		return int64(r), nil
+	}
+	return strconv.ParseInt(sign+lit, 0, 64)
+}
+
+// literalUint parses the bit pattern argument of math.FloatXXfrombits
+func literalUint(expr ast.Expr) (uint64, error) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT {
+		return 0, fmt.Errorf("malformed float bits")
+	}
+	return strconv.ParseUint(lit.Value, 0, 64)
+}
+
+// formatFloat renders a float the way Go source would
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, bitSize)
+}
+
+// typeName returns the name of a conversion's type
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.ArrayType:
+		if e.Len == nil && identName(e.Elt) == "byte" {
+			return "[]byte"
+		}
+	case *ast.ParenExpr:
+		return typeName(e.X)
+	}
+	return ""
+}
+
+// identName returns the name of an identifier expression, or ""
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// Format renders a corpus as text, one input per line, for display as the
+// input data of its fuzz test
+func Format(corpus *files.FuzzCorpus) string {
+	var b strings.Builder
+	write := func(label string, entry files.FuzzEntry) {
+		b.WriteString(label)
+		b.WriteString(": ")
+		if entry.Error != "" {
+			b.WriteString("invalid (" + entry.Error + ")\n")
+			return
+		}
+		for i, value := range entry.Values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if value.Type != "" {
+				b.WriteString(value.Type + "(" + value.Value + ")")
+			} else {
+				b.WriteString(value.Value)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for _, seed := range corpus.Seeds {
+		write(fmt.Sprintf("seed (line %d)", seed.Line), seed)
+	}
+	for _, entry := range corpus.Entries {
+		write(entry.Path, entry)
+	}
+	if corpus.Truncated {
+		fmt.Fprintf(&b, "... more than %d corpus entries\n", maxEntries)
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package fuzz
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []files.FuzzValue
+		wantErr string
+	}{
+		{
+			name: "typed values",
+			data: "go test fuzz v1\nstring(\"a\\x00\")\nint(-7)\n[]byte(\"b\")\nfloat64(1.5)\nuint64(18446744073709551615)\nint32('x')\nbool(true)\nbyte('y')\n",
+			want: []files.FuzzValue{
+				{Type: "string", Value: `"a\x00"`},
+				{Type: "int", Value: "-7"},
+				{Type: "[]byte", Value: `"b"`},
+				{Type: "float64", Value: "1.5"},
+				{Type: "uint64", Value: "18446744073709551615"},
+				{Type: "int32", Value: "120"},
+				{Type: "bool", Value: "true"},
+				{Type: "byte", Value: "121"},
+			},
+		},
+		{
+			name: "float bits",
+			data: "go test fuzz v1\nmath.Float64frombits(0x7ff8000000000001)\n",
+			want: []files.FuzzValue{{Type: "float64", Value: "NaN"}},
+		},
+		{
+			name:    "missing header",
+			data:    "string(\"a\")\n",
+			wantErr: "missing",
+		},
+		{
+			name:    "not a literal",
+			data:    "go test fuzz v1\nstring(x)\n",
+			wantErr: "line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"parse/parse_test.go":                "package parse\n\nimport \"testing\"\n\nfunc FuzzParse(f *testing.F) {\n\tf.Add(\"a,b\", 2)\n\tf.Add(\"\", -1)\n\tf.Fuzz(func(t *testing.T, s string, n int) {})\n}\n",
+		"parse/testdata/fuzz/FuzzParse/0a1b": "go test fuzz v1\nstring(\",\")\nint(0)\n",
+		"parse/testdata/fuzz/FuzzParse/ffff": "garbage",
+	}
+	writeFiles(t, dir, sources)
+
+	got, err := Load(files.NewService(dir), "parse/parse_test.go", "FuzzParse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &files.FuzzCorpus{
+		Seeds: []files.FuzzEntry{
+			{Line: 6, Values: []files.FuzzValue{{Type: "string", Value: `"a,b"`}, {Type: "int", Value: "2"}}},
+			{Line: 7, Values: []files.FuzzValue{{Type: "string", Value: `""`}, {Type: "int", Value: "-1"}}},
+		},
+		Entries: []files.FuzzEntry{
+			{Name: "0a1b", Path: "parse/testdata/fuzz/FuzzParse/0a1b", Values: []files.FuzzValue{{Type: "string", Value: `","`}, {Type: "int", Value: "0"}}},
+			{Name: "ffff", Path: "parse/testdata/fuzz/FuzzParse/ffff", Error: `missing "go test fuzz v1" header`},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+
+	text := Format(got)
+	if !strings.Contains(text, `seed (line 6): string("a,b"), int(2)`) || !strings.Contains(text, "ffff: invalid") {
+		t.Fatalf("Format() =\n%s", text)
+	}
+
+	if _, err := Load(files.NewService(dir), "parse/parse_test.go", "TestParse"); err == nil {
+		t.Fatal("Load() of a missing fuzz test succeeded")
+	}
+}
+
+func TestLoadStaysInBaseDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "base")
+	outside := filepath.Join(root, "outside")
+	sources := map[string]string{
+		"base/parse/parse_test.go":                "package parse\n\nimport \"testing\"\n\nfunc FuzzParse(f *testing.F) {}\n\nfunc FuzzSplit(f *testing.F) {}\n",
+		"base/parse/testdata/fuzz/FuzzParse/0a1b": "go test fuzz v1\nint(0)\n",
+		"outside/FuzzSplit/leak":                  "go test fuzz v1\nstring(\"secret\")\n",
+	}
+	writeFiles(t, root, sources)
+	// A symlinked entry and a symlinked corpus directory, both leading out
+	if err := os.Symlink(filepath.Join(outside, "FuzzSplit", "leak"), filepath.Join(dir, "parse/testdata/fuzz/FuzzParse/leak")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "FuzzSplit"), filepath.Join(dir, "parse/testdata/fuzz/FuzzSplit")); err != nil {
+		t.Fatal(err)
+	}
+
+	service := files.NewService(dir)
+	tests := []struct {
+		testName string
+		want     []files.FuzzEntry
+	}{
+		{
+			testName: "FuzzParse",
+			want:     []files.FuzzEntry{{Name: "0a1b", Path: "parse/testdata/fuzz/FuzzParse/0a1b", Values: []files.FuzzValue{{Type: "int", Value: "0"}}}},
+		},
+		{
+			testName: "FuzzSplit",
+			want:     []files.FuzzEntry{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := Load(service, "parse/parse_test.go", tt.testName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Entries, tt.want) {
+				t.Fatalf("Load() entries = %+v, want %+v", got.Entries, tt.want)
+			}
+		})
+	}
+}
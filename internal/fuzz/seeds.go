@@ -0,0 +1,118 @@
+package fuzz
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// Seeds returns the seed corpus a fuzz test adds with f.Add, one entry per
+// call. Values are typed by the parameters of the function passed to f.Fuzz
+// when the test has one, and by the kind of their literal otherwise.
+func Seeds(fset *token.FileSet, fn *ast.FuncDecl) []files.FuzzEntry {
+	seeds := []files.FuzzEntry{}
+	if fn.Body == nil || len(fn.Type.Params.List) == 0 || len(fn.Type.Params.List[0].Names) == 0 {
+		return seeds
+	}
+	f := fn.Type.Params.List[0].Names[0].Name
+
+	var adds []*ast.CallExpr
+	var types []string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || identName(sel.X) != f {
+			return true
+		}
+		switch sel.Sel.Name {
+		case "Add":
+			adds = append(adds, call)
+		case "Fuzz":
+			if len(call.Args) == 1 {
+				if lit, ok := call.Args[0].(*ast.FuncLit); ok {
+					types = fuzzParams(fset, lit)
+				}
+			}
+		}
+		return true
+	})
+
+	for _, call := range adds {
+		entry := files.FuzzEntry{
+			Line:   fset.Position(call.Pos()).Line,
+			Values: []files.FuzzValue{},
+		}
+		for i, arg := range call.Args {
+			value := files.FuzzValue{Value: source(fset, arg)}
+			if i < len(types) {
+				value.Type = types[i]
+			} else {
+				value.Type = literalType(arg)
+			}
+			entry.Values = append(entry.Values, value)
+		}
+		seeds = append(seeds, entry)
+	}
+	return seeds
+}
+
+// fuzzParams returns the types of the fuzzed parameters of the function
+// passed to f.Fuzz, which follow its *testing.T parameter
+func fuzzParams(fset *token.FileSet, lit *ast.FuncLit) []string {
+	var types []string
+	for _, field := range lit.Type.Params.List {
+		typ := source(fset, field.Type)
+		for range max(len(field.Names), 1) {
+			types = append(types, typ)
+		}
+	}
+	if len(types) > 0 {
+		types = types[1:]
+	}
+	return types
+}
+
+// literalType returns the default type of a literal argument, or "" when
+// it cannot be told from the syntax
+func literalType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return "string"
+		case token.INT:
+			return "int"
+		case token.FLOAT:
+			return "float64"
+		case token.CHAR:
+			return "rune"
+		}
+	case *ast.UnaryExpr:
+		return literalType(e.X)
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return "bool"
+		}
+	case *ast.CallExpr:
+		// Conversions such as []byte("x") or uint8(3)
+		if len(e.Args) == 1 {
+			return typeName(e.Fun)
+		}
+	}
+	return ""
+}
+
+// source renders a node as Go source
+func source(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
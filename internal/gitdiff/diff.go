@@ -0,0 +1,288 @@
+package gitdiff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// ChangedLines runs git diff in dir between base and head and returns the
+// changed line ranges of every file on the head side, relative to dir. An
+// empty head compares base with the working tree.
+func ChangedLines(ctx context.Context, dir, base, head string) (map[string][]files.LineRange, error) {
+	if base == "" {
+		return nil, fmt.Errorf("base revision is required")
+	}
+	for _, rev := range []string{base, head} {
+		if strings.HasPrefix(rev, "-") {
+			return nil, fmt.Errorf("invalid revision %q", rev)
+		}
+	}
+
+	args := []string{base}
+	if head != "" {
+		args = append(args, head)
+	}
+
+	output, err := diff(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseUnified(output)
+}
+
+// MapToWorkingTree maps changed line ranges of files at revision rev onto the
+// working tree in dir. Lines untouched since rev shift with the edits around
+// them, edited lines map to their replacement and lines or files deleted
+// since rev are dropped.
+func MapToWorkingTree(ctx context.Context, dir, rev string, changes map[string][]files.LineRange) (map[string][]files.LineRange, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid revision %q", rev)
+	}
+
+	output, err := diff(ctx, dir, "--no-renames", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	edits, err := parseEdits(output)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make(map[string][]files.LineRange)
+	for path, ranges := range changes {
+		fileEdits, ok := edits[path]
+		if !ok {
+			mapped[path] = ranges
+			continue
+		}
+		if fileEdits.deleted {
+			continue
+		}
+
+		lines := make(map[int]bool)
+		for _, r := range ranges {
+			for line := r.Start; line <= r.End; line++ {
+				target := fileEdits.mapLine(line)
+				for l := target.Start; l <= target.End; l++ {
+					lines[l] = true
+				}
+			}
+		}
+		if len(lines) > 0 {
+			mapped[path] = toRanges(lines)
+		}
+	}
+
+	return mapped, nil
+}
+
+// diff runs git diff in dir with zero context and returns its output
+func diff(ctx context.Context, dir string, revs ...string) (*bytes.Buffer, error) {
+	// Fix the path prefixes, which diff.noprefix and diff.mnemonicPrefix
+	// would change
+	args := []string{"-C", dir, "diff", "--unified=0", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--relative"}
+	args = append(append(args, revs...), "--")
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return &stdout, nil
+}
+
+// ParseUnified parses a unified diff and returns the added or modified line
+// ranges of each file on the new side. Deleted files are omitted.
+func ParseUnified(r io.Reader) (map[string][]files.LineRange, error) {
+	changes := make(map[string][]files.LineRange)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	current := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = parseFileName(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			_, r, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			if r.End >= r.Start {
+				changes[current] = append(changes[current], r)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	for path := range changes {
+		ranges := changes[path]
+		sort.Slice(ranges, func(i, j int) bool {
+			return ranges[i].Start < ranges[j].Start
+		})
+	}
+
+	return changes, nil
+}
+
+// fileEdits lists the hunks of one file in a diff against the working tree
+type fileEdits struct {
+	deleted bool
+	hunks   []hunk
+}
+
+// hunk records the old-side lines a diff hunk replaces with its new-side
+// lines. Either side has End < Start when it is empty.
+type hunk struct {
+	old, new files.LineRange
+}
+
+// mapLine returns the working tree lines an old-side line ends up as: the
+// shifted line when no hunk touches it, the replacement when one does, or an
+// empty range when it was deleted.
+func (e *fileEdits) mapLine(line int) files.LineRange {
+	delta := 0
+	for _, h := range e.hunks {
+		if h.old.End >= h.old.Start {
+			if line < h.old.Start {
+				break
+			}
+			if line <= h.old.End {
+				return h.new
+			}
+		} else if line <= h.old.Start {
+			// Pure insertions go after old line h.old.Start
+			break
+		}
+		delta += (h.new.End - h.new.Start) - (h.old.End - h.old.Start)
+	}
+	return files.LineRange{Start: line + delta, End: line + delta}
+}
+
+// parseEdits parses a unified diff into the hunks of each file, keyed by its
+// old-side path. Files added on the new side are omitted.
+func parseEdits(r io.Reader) (map[string]*fileEdits, error) {
+	edits := make(map[string]*fileEdits)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	oldName := ""
+	inHeader := false
+	var current *fileEdits
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			inHeader, oldName, current = true, "", nil
+		case strings.HasPrefix(line, "--- ") && inHeader:
+			oldName = parseFileName(strings.TrimPrefix(line, "--- "), "a/")
+			current = nil
+		case strings.HasPrefix(line, "+++ ") && inHeader && oldName != "":
+			current = &fileEdits{deleted: parseFileName(strings.TrimPrefix(line, "+++ "), "b/") == ""}
+			edits[oldName] = current
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			inHeader = false
+			old, new, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			current.hunks = append(current.hunks, hunk{old: old, new: new})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+
+	return edits, nil
+}
+
+// toRanges collapses a set of line numbers into sorted contiguous ranges
+func toRanges(lines map[int]bool) []files.LineRange {
+	sorted := make([]int, 0, len(lines))
+	for line := range lines {
+		sorted = append(sorted, line)
+	}
+	sort.Ints(sorted)
+
+	var ranges []files.LineRange
+	for _, line := range sorted {
+		if n := len(ranges); n > 0 && ranges[n-1].End == line-1 {
+			ranges[n-1].End = line
+			continue
+		}
+		ranges = append(ranges, files.LineRange{Start: line, End: line})
+	}
+	return ranges
+}
+
+// parseFileName extracts the path from a "--- a/path" or "+++ b/path" header
+// line with the given prefix
+func parseFileName(name, prefix string) string {
+	name = strings.TrimRight(name, "\t")
+	if name == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		name = unquoted
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// parseHunkHeader returns the old-side and new-side ranges of an
+// "@@ -a,b +c,d @@" header. An empty side yields a range with End < Start.
+func parseHunkHeader(line string) (old, new files.LineRange, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return files.LineRange{}, files.LineRange{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+
+	if old, err = parseHunkRange(fields[1][1:]); err != nil {
+		return files.LineRange{}, files.LineRange{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+	if new, err = parseHunkRange(fields[2][1:]); err != nil {
+		return files.LineRange{}, files.LineRange{}, fmt.Errorf("malformed hunk header %q", line)
+	}
+	return old, new, nil
+}
+
+// parseHunkRange parses the "start,count" of one side of a hunk header
+func parseHunkRange(field string) (files.LineRange, error) {
+	startStr, countStr, hasCount := strings.Cut(field, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return files.LineRange{}, err
+	}
+
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return files.LineRange{}, err
+		}
+	}
+
+	return files.LineRange{Start: start, End: start + count - 1}, nil
+}
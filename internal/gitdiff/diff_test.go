@@ -0,0 +1,150 @@
+package gitdiff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func TestParseUnified(t *testing.T) {
+	input := `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@ func A() {
++	x := 1
++	y := 2
+@@ -10 +12 @@ func B() {
+-	return 1
++	return 2
+@@ -20,3 +21,0 @@ func C() {
+-	a
+-	b
+-	c
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package old
+-
+`
+	changes, err := ParseUnified(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("files count = %d, want %d (%v)", len(changes), 1, changes)
+	}
+
+	want := []files.LineRange{{Start: 4, End: 5}, {Start: 12, End: 12}}
+	got := changes["a.go"]
+	if len(got) != len(want) {
+		t.Fatalf("ranges = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("range %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	configs := []string{"diff.noprefix=true", "diff.mnemonicPrefix=true"}
+	for _, config := range configs {
+		t.Run(config, func(t *testing.T) {
+			dir := t.TempDir()
+			git := func(args ...string) {
+				t.Helper()
+				cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+				if output, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %v\n%s", args, err, output)
+				}
+			}
+			write := func(content string) {
+				t.Helper()
+				if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			git("init", "-q")
+			git("config", strings.Split(config, "=")[0], "true")
+			write("package a\n")
+			git("add", "a.go")
+			git("commit", "-q", "-m", "init")
+			write("package a\n\nfunc A() {}\n")
+
+			got, err := ChangedLines(context.Background(), dir, "HEAD", "")
+			if err != nil {
+				t.Fatalf("ChangedLines() error = %v", err)
+			}
+			want := map[string][]files.LineRange{"a.go": {{Start: 2, End: 3}}}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("ChangedLines() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMapToWorkingTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("a.go", "1\n2\n3\n4\n5\n6\n")
+	write("b.go", "b\n")
+	write("c.go", "c\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	// Insert two lines after 1, replace 3, delete 5 and remove b.go
+	write("a.go", "1\nx\ny\n2\nthree\n4\n6\n")
+	if err := os.Remove(filepath.Join(dir, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := map[string][]files.LineRange{
+		"a.go": {{Start: 1, End: 2}, {Start: 3, End: 3}, {Start: 5, End: 6}},
+		"b.go": {{Start: 1, End: 1}},
+		"c.go": {{Start: 1, End: 1}},
+	}
+	got, err := MapToWorkingTree(context.Background(), dir, "HEAD", changed)
+	if err != nil {
+		t.Fatalf("MapToWorkingTree() error = %v", err)
+	}
+	want := map[string][]files.LineRange{
+		"a.go": {{Start: 1, End: 1}, {Start: 4, End: 5}, {Start: 7, End: 7}},
+		"c.go": {{Start: 1, End: 1}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MapToWorkingTree() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+package gomod
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/ignore"
+)
+
+// Module describes a Go module found under the base directory
+type Module struct {
+	Path string // module path declared in go.mod
+	Dir  string // directory relative to the base directory ("." for the root)
+}
+
+// Modules finds all go.mod files under baseDir and returns the declared modules,
+// longest module path first so that nested modules win over their parents
+func Modules(baseDir string) ([]Module, error) {
+	var modules []Module
+
+	ignored := ignore.For(baseDir)
+	err := filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			name := d.Name()
+			if p != baseDir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "testdata" || ignored.IgnoredPath(p, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Name() != "go.mod" {
+			return nil
+		}
+
+		modulePath, err := readModulePath(p)
+		if err != nil || modulePath == "" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, filepath.Dir(p))
+		if err != nil {
+			return nil
+		}
+
+		modules = append(modules, Module{Path: modulePath, Dir: filepath.ToSlash(rel)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan modules: %w", err)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return len(modules[i].Path) > len(modules[j].Path)
+	})
+
+	return modules, nil
+}
+
+// readModulePath extracts the module path from a go.mod file
+func readModulePath(goModPath string) (string, error) {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted, nil
+		}
+		return fields[1], nil
+	}
+
+	return "", scanner.Err()
+}
+
+// Resolver maps between Go import paths and paths relative to the base directory
+type Resolver struct {
+	baseDir string
+	modules []Module
+}
+
+// NewResolver creates a resolver for the modules found under baseDir
+func NewResolver(baseDir string) (*Resolver, error) {
+	modules, err := Modules(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Resolver{
+		baseDir: baseDir,
+		modules: modules,
+	}, nil
+}
+
+// RelativePath converts an import-path-qualified file name (as written by
+// go test -coverprofile) to a slash-separated path relative to the base directory
+func (r *Resolver) RelativePath(name string) (string, bool) {
+	if filepath.IsAbs(name) {
+		rel, err := filepath.Rel(r.baseDir, name)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+
+	for _, module := range r.modules {
+		if name != module.Path && !strings.HasPrefix(name, module.Path+"/") {
+			continue
+		}
+
+		rest := strings.TrimPrefix(strings.TrimPrefix(name, module.Path), "/")
+		return path.Clean(path.Join(module.Dir, rest)), true
+	}
+
+	return "", false
+}
+
+// PackagePath returns the import path of the package containing the given
+// relative file or directory path
+func (r *Resolver) PackagePath(relPath string) (string, bool) {
+	dir := packageDir(relPath)
+
+	module, ok := r.ModuleFor(dir)
+	if !ok {
+		return "", false
+	}
+
+	if dir == module.Dir || dir == "." {
+		return module.Path, true
+	}
+
+	rest := dir
+	if module.Dir != "." {
+		rest = strings.TrimPrefix(dir, module.Dir+"/")
+	}
+
+	return module.Path + "/" + rest, true
+}
+
+// ModuleFor returns the innermost module containing the given relative path
+func (r *Resolver) ModuleFor(relPath string) (Module, bool) {
+	dir := packageDir(relPath)
+
+	var best Module
+	bestDepth := -1
+	for _, module := range r.modules {
+		depth := 0
+		if module.Dir != "." {
+			if dir != module.Dir && !strings.HasPrefix(dir, module.Dir+"/") {
+				continue
+			}
+			depth = len(module.Dir)
+		}
+		if depth > bestDepth {
+			best = module
+			bestDepth = depth
+		}
+	}
+
+	return best, bestDepth >= 0
+}
+
+// packageDir returns the slash-separated directory for a file or directory path
+func packageDir(relPath string) string {
+	dir := filepath.ToSlash(relPath)
+	if strings.HasSuffix(dir, ".go") {
+		dir = path.Dir(dir)
+	}
+	return path.Clean(dir)
+}
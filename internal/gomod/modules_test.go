@@ -0,0 +1,121 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	tests := []struct {
+		name  string
+		goMod string
+		want  string
+	}{
+		{name: "plain", goMod: "module example.com/app\n\ngo 1.22\n", want: "example.com/app"},
+		{name: "trailing comment", goMod: "module example.com/app // the app\n", want: "example.com/app"},
+		{name: "quoted", goMod: "module \"example.com/app\"\n", want: "example.com/app"},
+		{name: "leading comment", goMod: "// module example.com/old\nmodule example.com/app\n", want: "example.com/app"},
+		{name: "other directive first", goMod: "modulefoo bar\nmodule example.com/app\n", want: "example.com/app"},
+		{name: "no module", goMod: "go 1.22\n", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(path, []byte(tt.goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readModulePath(path)
+			if err != nil {
+				t.Fatalf("readModulePath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("readModulePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	baseDir := t.TempDir()
+	writeFiles(t, baseDir, map[string]string{
+		"go.mod":                "module example.com/root\n",
+		"cmd/main.go":           "package main\n",
+		"tools/go.mod":          "module example.com/root/tools // nested\n",
+		"tools/gen/gen.go":      "package gen\n",
+		"node_modules/x/go.mod": "module example.com/hidden\n",
+	})
+
+	resolver, err := NewResolver(baseDir)
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	t.Run("RelativePath", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			want   string
+			wantOK bool
+		}{
+			{name: "example.com/root/cmd/main.go", want: "cmd/main.go", wantOK: true},
+			{name: "example.com/root/tools/gen/gen.go", want: "tools/gen/gen.go", wantOK: true},
+			{name: filepath.Join(baseDir, "cmd", "main.go"), want: "cmd/main.go", wantOK: true},
+			{name: filepath.Join(filepath.Dir(baseDir), "other.go")},
+			{name: "example.com/rootx/main.go"},
+			{name: "example.com/hidden/x.go"},
+		}
+		for _, tt := range tests {
+			got, ok := resolver.RelativePath(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("RelativePath(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		}
+	})
+
+	t.Run("PackagePath", func(t *testing.T) {
+		tests := []struct {
+			relPath string
+			want    string
+		}{
+			{relPath: "cmd/main.go", want: "example.com/root/cmd"},
+			{relPath: ".", want: "example.com/root"},
+			{relPath: "tools", want: "example.com/root/tools"},
+			{relPath: "tools/gen/gen.go", want: "example.com/root/tools/gen"},
+		}
+		for _, tt := range tests {
+			if got, ok := resolver.PackagePath(tt.relPath); !ok || got != tt.want {
+				t.Fatalf("PackagePath(%q) = %q, %v, want %q", tt.relPath, got, ok, tt.want)
+			}
+		}
+	})
+
+	t.Run("ModuleFor", func(t *testing.T) {
+		tests := []struct {
+			relPath string
+			want    string
+		}{
+			{relPath: "cmd/main.go", want: "."},
+			{relPath: "tools/gen/gen.go", want: "tools"},
+			{relPath: "toolsx/a.go", want: "."},
+		}
+		for _, tt := range tests {
+			if got, ok := resolver.ModuleFor(tt.relPath); !ok || got.Dir != tt.want {
+				t.Fatalf("ModuleFor(%q) = %+v, %v, want dir %q", tt.relPath, got, ok, tt.want)
+			}
+		}
+	})
+}
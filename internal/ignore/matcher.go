@@ -0,0 +1,346 @@
+package ignore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigFile is the project configuration read from the base directory
+const ConfigFile = ".codebase-view.json"
+
+// Config lists extra globs applied on top of .gitignore. Globs use gitignore
+// syntax relative to the base directory. Include globs win over every exclude
+// rule; to reach into an excluded directory they must be anchored (contain a
+// "/"), e.g. "vendor/example.com/lib/**". Deny globs name files that are never
+// served, whether shown or not; they extend the default deny-list and "!glob"
+// takes a path back off it.
+type Config struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	Deny    []string `json:"deny,omitempty"`
+}
+
+// defaultExcludes are hidden unless a .gitignore negation or an include glob
+// brings them back: dot files, and dependency trees that are rarely reviewed
+var defaultExcludes = []string{".*", "node_modules/", "vendor/"}
+
+// defaultDenied are files that commonly hold credentials
+var defaultDenied = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore",
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", ".ssh/", ".gnupg/",
+	".netrc", ".npmrc", ".pypirc", ".git-credentials", ".aws/credentials", ".docker/config.json",
+	"*.tfstate", "*.tfstate.backup",
+}
+
+// recheckInterval is how long a loaded .gitignore or config file is trusted
+// before its modification time is checked again
+const recheckInterval = time.Second
+
+// Matcher decides which paths under a base directory are hidden from the
+// explorer, search and scanners
+type Matcher struct {
+	baseDir  string
+	defaults []pattern
+	denied   []pattern
+
+	mu     sync.Mutex
+	files  map[string]*patternFile // by slash-separated path relative to baseDir
+	config *patternFile
+}
+
+// patternFile is a cached .gitignore, exclude or config file
+type patternFile struct {
+	checked  time.Time
+	modTime  time.Time
+	size     int64
+	patterns []pattern // for the config file: the exclude globs
+	include  []pattern // for the config file: the include globs
+	deny     []pattern // for the config file: the deny globs
+	err      error     // for the config file: why it was not applied
+}
+
+// state is the visibility of a path
+type state int
+
+const (
+	shown       state = iota
+	passthrough       // an excluded directory shown to reach included paths
+	hidden
+)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*Matcher)
+)
+
+// For returns the shared matcher of a base directory
+func For(baseDir string) *Matcher {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	baseDir = filepath.Clean(baseDir)
+	m, ok := registry[baseDir]
+	if !ok {
+		m = New(baseDir)
+		registry[baseDir] = m
+	}
+	return m
+}
+
+// New creates a matcher for a base directory. Most callers should use For,
+// which shares the cached pattern files between them.
+func New(baseDir string) *Matcher {
+	m := &Matcher{
+		baseDir: filepath.Clean(baseDir),
+		files:   make(map[string]*patternFile),
+	}
+	for _, glob := range defaultExcludes {
+		if p, ok := parsePattern(glob); ok {
+			m.defaults = append(m.defaults, p)
+		}
+	}
+	for _, glob := range defaultDenied {
+		// Default denials apply at any depth
+		if p, ok := parsePattern("**/" + glob); ok {
+			m.denied = append(m.denied, p)
+		}
+	}
+	return m
+}
+
+// Ignored reports whether a path relative to the base directory is hidden.
+// Directories are hidden with everything below them.
+func (m *Matcher) Ignored(rel string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	rel = path.Clean(filepath.ToSlash(rel))
+	if rel == "." || !filepath.IsLocal(rel) {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state(rel, isDir) == hidden
+}
+
+// IgnoredPath is Ignored for an absolute path. Paths outside the base
+// directory are never hidden.
+func (m *Matcher) IgnoredPath(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	rel, err := filepath.Rel(m.baseDir, p)
+	if err != nil {
+		return false
+	}
+	return m.Ignored(rel, isDir)
+}
+
+// Denied reports whether a path relative to the base directory is on the
+// deny-list, itself or through one of its directories. The last matching
+// glob wins, with the configured globs after the defaults.
+func (m *Matcher) Denied(rel string) bool {
+	if m == nil {
+		return false
+	}
+	rel = path.Clean(filepath.ToSlash(rel))
+	if rel == "." || !filepath.IsLocal(rel) {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config := m.loadConfig()
+	for p, isDir := rel, false; p != "."; p, isDir = path.Dir(p), true {
+		denied := false
+		for _, patterns := range [][]pattern{m.denied, config.deny} {
+			for _, pat := range patterns {
+				if pat.match(p, isDir) {
+					denied = !pat.negate
+				}
+			}
+		}
+		if denied {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigError returns why the project configuration could not be applied,
+// or nil
+func (m *Matcher) ConfigError() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadConfig().err
+}
+
+// state computes the visibility of a path from the visibility of its parent
+func (m *Matcher) state(rel string, isDir bool) state {
+	config := m.loadConfig()
+	if matchAny(config.include, rel, isDir) {
+		return shown
+	}
+
+	parent := shown
+	if dir := path.Dir(rel); dir != "." {
+		parent = m.state(dir, true)
+	}
+	if parent == hidden {
+		return hidden
+	}
+
+	if parent == shown && !m.excluded(rel, isDir, config) {
+		return shown
+	}
+	if isDir && leadsTo(config.include, rel) {
+		return passthrough
+	}
+	return hidden
+}
+
+// excluded applies the default excludes, .git/info/exclude, the .gitignore
+// files from the base directory down to the path's directory (the last
+// matching pattern wins) and the configured exclude globs
+func (m *Matcher) excluded(rel string, isDir bool, config *patternFile) bool {
+	excluded := false
+	apply := func(patterns []pattern, relTo string) {
+		for _, p := range patterns {
+			if p.match(relTo, isDir) {
+				excluded = !p.negate
+			}
+		}
+	}
+
+	apply(m.defaults, rel)
+	apply(m.load(".git/info/exclude").patterns, rel)
+
+	dir := path.Dir(rel)
+	for d := "."; ; {
+		apply(m.load(path.Join(d, ".gitignore")).patterns, relativeTo(d, rel))
+		if d == dir {
+			break
+		}
+		d = nextDir(d, dir)
+	}
+
+	return excluded || matchAny(config.patterns, rel, isDir)
+}
+
+// load returns the patterns of an ignore file, re-reading it when it changed
+func (m *Matcher) load(rel string) *patternFile {
+	f := m.files[rel]
+	if f != nil && time.Since(f.checked) < recheckInterval {
+		return f
+	}
+
+	info, err := os.Stat(filepath.Join(m.baseDir, filepath.FromSlash(rel)))
+	switch {
+	case err != nil:
+		f = &patternFile{}
+	case f != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size:
+	default:
+		f = &patternFile{modTime: info.ModTime(), size: info.Size()}
+		if data, err := os.ReadFile(filepath.Join(m.baseDir, filepath.FromSlash(rel))); err == nil {
+			f.patterns = parseLines(data)
+		}
+	}
+	f.checked = time.Now()
+	m.files[rel] = f
+	return f
+}
+
+// loadConfig returns the project configuration, re-reading it when it changed
+func (m *Matcher) loadConfig() *patternFile {
+	f := m.config
+	if f != nil && time.Since(f.checked) < recheckInterval {
+		return f
+	}
+
+	info, err := os.Stat(filepath.Join(m.baseDir, ConfigFile))
+	switch {
+	case err != nil:
+		f = &patternFile{}
+	case f != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size:
+	default:
+		f = &patternFile{modTime: info.ModTime(), size: info.Size()}
+		var config Config
+		data, err := os.ReadFile(filepath.Join(m.baseDir, ConfigFile))
+		if err == nil {
+			err = json.Unmarshal(data, &config)
+		}
+		if err != nil {
+			f.err = fmt.Errorf("invalid %s: %w", ConfigFile, err)
+			break
+		}
+		f.patterns = parseGlobs(config.Exclude)
+		f.include = parseGlobs(config.Include)
+		for _, glob := range config.Deny {
+			if p, ok := parsePattern(glob); ok {
+				f.deny = append(f.deny, p)
+			}
+		}
+	}
+	f.checked = time.Now()
+	m.config = f
+	return f
+}
+
+// parseGlobs compiles configured globs; negations are meaningless there and
+// are dropped
+func parseGlobs(globs []string) []pattern {
+	var patterns []pattern
+	for _, glob := range globs {
+		if p, ok := parsePattern(glob); ok && !p.negate {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// matchAny reports whether any pattern matches a path
+func matchAny(patterns []pattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.match(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// leadsTo reports whether a directory is on the way to an anchored pattern
+func leadsTo(patterns []pattern, dir string) bool {
+	for _, p := range patterns {
+		if p.prefix != "" && (p.prefix == dir || strings.HasPrefix(p.prefix, dir+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeTo returns rel relative to an ancestor directory
+func relativeTo(dir, rel string) string {
+	if dir == "." {
+		return rel
+	}
+	return rel[len(dir)+1:]
+}
+
+// nextDir returns the child of dir on the way to target
+func nextDir(dir, target string) string {
+	rest := relativeTo(dir, target)
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		rest = rest[:i]
+	}
+	return path.Join(dir, rest)
+}
@@ -0,0 +1,135 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	dir := t.TempDir()
+	sources := map[string]string{
+		".gitignore":     "# build output\n/dist/\n*.log\n!keep.log\nbuild/\ndocs/**/*.tmp\n",
+		"web/.gitignore": "*.map\n!app.min.js.map\ngenerated/\n",
+		ConfigFile:       `{"exclude": ["**/*.pb.go"], "include": ["vendor/example.com/lib/**", ".github/"]}`,
+	}
+	writeFiles(t, dir, sources)
+
+	m := New(dir)
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{".env", false, true},
+		{".github", true, false},
+		{".github/workflows/ci.yml", false, false},
+		{"node_modules", true, true},
+		{"dist", true, true},
+		{"dist/app.js", false, true},
+		{"src/dist", true, false}, // anchored to the root
+		{"server.log", false, true},
+		{"logs/keep.log", false, false},
+		{"src/build", true, true},
+		{"src/build", false, false}, // directory-only pattern
+		{"docs/a/b/c.tmp", false, true},
+		{"docs/c.tmp", false, true},
+		{"web/app.js.map", false, true},
+		{"web/app.min.js.map", false, false},
+		{"app.js.map", false, false}, // nested .gitignore applies below web/ only
+		{"web/generated/x.js", false, true},
+		{"api/v1/api.pb.go", false, true},
+		{"vendor", true, false},
+		{"vendor/example.com", true, false},
+		{"vendor/example.com/lib/lib.go", false, false},
+		{"vendor/example.com/other/other.go", false, true},
+		{"vendor/golang.org", true, true},
+	}
+
+	for _, tt := range tests {
+		if got := m.Ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	if err := m.ConfigError(); err != nil {
+		t.Fatalf("ConfigError() = %v", err)
+	}
+}
+
+func TestMatcherDenied(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"deny": ["config/prod.yaml", "secrets/", "!.env.example"]}`
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(dir)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"main.go", false},
+		{".env", true},
+		{"services/api/.env.local", true},
+		{".env.example", false},
+		{"certs/server.pem", true},
+		{"home/.ssh/known_hosts", true},
+		{".aws/credentials", true},
+		{"docs/.aws/credentials", true},
+		{"config/prod.yaml", true},
+		{"config/dev.yaml", false},
+		{"secrets/token.txt", true},
+		{"keys.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Denied(tt.path); got != tt.want {
+			t.Errorf("Denied(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "a/b.go", true},
+		{"a/*.go", "a/b/c.go", false},
+		{"a/**/c.go", "a/c.go", true},
+		{"a/**/c.go", "a/b/d/c.go", true},
+		{"**/c", "x/y/c", true},
+		{"a/**", "a/b/c", true},
+		{"f?o", "foo", true},
+		{"[!a]bc", "abc", false},
+		{"[a-c]bc", "bbc", true},
+		{`\#x`, "#x", true},
+		{"trailing   ", "trailing", true},
+	}
+
+	for _, tt := range tests {
+		p, ok := parsePattern(tt.pattern)
+		if !ok {
+			t.Fatalf("parsePattern(%q) failed", tt.pattern)
+		}
+		if got := p.match(tt.path, false); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
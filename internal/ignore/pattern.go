@@ -0,0 +1,173 @@
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// pattern is a single line of a .gitignore file or a configured glob
+type pattern struct {
+	re      *regexp.Regexp // matches paths relative to the pattern's directory
+	negate  bool           // "!pattern" re-includes what earlier patterns excluded
+	dirOnly bool           // "pattern/" only matches directories
+	prefix  string         // literal leading directories, for include lookahead
+}
+
+// match reports whether the pattern matches a path relative to its directory
+func (p pattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	return p.re.MatchString(rel)
+}
+
+// parseLines parses the patterns of a .gitignore file
+func parseLines(data []byte) []pattern {
+	var patterns []pattern
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// parsePattern compiles a pattern with the syntax of gitignore: blank lines
+// and "#" comments are skipped, "!" negates, a trailing "/" matches only
+// directories, a "/" elsewhere anchors the pattern to its directory, "*" and
+// "?" do not cross "/", and "**" matches any number of directories
+func parsePattern(line string) (pattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return pattern{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return pattern{}, false
+	}
+	p.re = re
+
+	if anchored {
+		p.prefix = literalPrefix(line)
+	}
+	return p, true
+}
+
+// globRegexp translates a gitignore glob into a regular expression
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob) && (i == 0 || glob[i-1] == '/'):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// literalPrefix returns the leading directories of an anchored glob that
+// contain no wildcard
+func literalPrefix(glob string) string {
+	var dirs []string
+	for _, segment := range strings.Split(glob, "/") {
+		if strings.ContainsAny(segment, `*?[\`) {
+			break
+		}
+		dirs = append(dirs, segment)
+	}
+	return strings.Join(dirs, "/")
+}
+
+// trimTrailingSpaces removes trailing spaces that are not escaped
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// Glob is a compiled path glob in gitignore syntax
+type Glob struct {
+	p pattern
+}
+
+// NewGlob compiles a glob in gitignore syntax: a glob without "/" matches
+// the base name of a path at any depth, "**" matches any number of
+// directories and a trailing "/" matches everything below a directory
+func NewGlob(glob string) (*Glob, error) {
+	p, ok := parsePattern(glob)
+	if !ok || p.negate {
+		return nil, fmt.Errorf("invalid glob %q", glob)
+	}
+	return &Glob{p: p}, nil
+}
+
+// Match reports whether a slash-separated relative path matches the glob,
+// or lies below a directory it matches
+func (g *Glob) Match(rel string) bool {
+	if g.p.match(rel, false) {
+		return true
+	}
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if g.p.match(dir, true) {
+			return true
+		}
+	}
+	return false
+}
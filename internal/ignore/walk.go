@@ -0,0 +1,45 @@
+package ignore
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// WalkFiles calls fn for every regular file under baseDir that is neither
+// hidden nor denied, with its slash-separated path relative to baseDir, its
+// full path and its file info. Hidden and denied directories are not entered
+// and unreadable entries are skipped.
+func WalkFiles(baseDir string, fn func(rel, p string, info fs.FileInfo)) {
+	m := For(baseDir)
+	filepath.WalkDir(baseDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if p == baseDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if m.Ignored(rel, true) || m.Denied(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || m.Ignored(rel, false) || m.Denied(rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fn(rel, p, info)
+		return nil
+	})
+}
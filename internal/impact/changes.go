@@ -0,0 +1,131 @@
+package impact
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/coverage"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gitdiff"
+	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/relocate"
+)
+
+// Changes diffs base against head in the git repository containing the base
+// directory and reports, for every changed range, the tests covering it and
+// the changed lines no test reaches. Coverage is taken from stored test
+// references and imported cover profiles, which describe the working tree, so
+// the changed lines of a head revision are first mapped onto the working tree
+// and reported in its numbering.
+func Changes(ctx context.Context, store *metadata.Store, fileService *files.Service, base, head string) (*files.ChangeImpactResponse, error) {
+	changed, err := gitdiff.ChangedLines(ctx, fileService.BaseDir(), base, head)
+	if err != nil {
+		return nil, err
+	}
+	if head != "" {
+		if changed, err = gitdiff.MapToWorkingTree(ctx, fileService.BaseDir(), head, changed); err != nil {
+			return nil, err
+		}
+	}
+
+	relocator := relocate.New(fileService)
+	response := &files.ChangeImpactResponse{
+		Base:  base,
+		Head:  head,
+		Files: []files.FileChangeImpact{},
+	}
+
+	for path, ranges := range changed {
+		response.Files = append(response.Files, fileImpact(store, fileService, relocator, path, ranges))
+	}
+
+	sort.Slice(response.Files, func(i, j int) bool {
+		return response.Files[i].Path < response.Files[j].Path
+	})
+
+	return response, nil
+}
+
+// fileImpact intersects the changed ranges of one file with its coverage
+func fileImpact(store *metadata.Store, fileService *files.Service, relocator *relocate.Relocator, path string, ranges []files.LineRange) files.FileChangeImpact {
+	lineTests := make(map[int][]files.TestID)
+	var real *files.LineCoverage
+
+	if meta := store.GetTestMetadata(path); meta != nil {
+		for _, test := range relocator.ResolveTests(path, meta.Tests) {
+			id := files.TestID{TestFile: test.TestFile, TestName: test.TestName}
+			for _, line := range metadata.CoveredLines(test) {
+				lineTests[line] = append(lineTests[line], id)
+			}
+		}
+		real = coverage.Lines(meta.Coverage)
+	}
+
+	var content []string
+	if real == nil {
+		if file, err := fileService.ReadFile(path); err == nil {
+			content = strings.Split(file.Content, "\n")
+		}
+	}
+
+	impact := files.FileChangeImpact{
+		Path:          path,
+		Changes:       []files.ChangeImpact{},
+		UntestedLines: []int{},
+	}
+
+	for _, r := range ranges {
+		change := files.ChangeImpact{
+			Lines:         r,
+			UntestedLines: []int{},
+			Tests:         []files.TestID{},
+		}
+		seen := make(map[files.TestID]bool)
+
+		for line := r.Start; line <= r.End; line++ {
+			for _, id := range lineTests[line] {
+				if !seen[id] {
+					seen[id] = true
+					change.Tests = append(change.Tests, id)
+				}
+			}
+
+			if !isExecutable(line, real, content) {
+				continue
+			}
+			if len(lineTests[line]) == 0 && (real == nil || real.Hits[line] == 0) {
+				change.UntestedLines = append(change.UntestedLines, line)
+			}
+		}
+
+		sort.Slice(change.Tests, func(i, j int) bool {
+			if change.Tests[i].TestFile != change.Tests[j].TestFile {
+				return change.Tests[i].TestFile < change.Tests[j].TestFile
+			}
+			return change.Tests[i].TestName < change.Tests[j].TestName
+		})
+
+		impact.Changes = append(impact.Changes, change)
+		impact.UntestedLines = append(impact.UntestedLines, change.UntestedLines...)
+	}
+
+	return impact
+}
+
+// isExecutable reports whether a changed line can be covered by a test. With
+// an imported cover profile only lines inside statement blocks count;
+// otherwise blank lines and line comments are ignored.
+func isExecutable(line int, real *files.LineCoverage, content []string) bool {
+	if real != nil {
+		_, ok := real.Hits[line]
+		return ok
+	}
+
+	if line < 1 || line > len(content) {
+		return false
+	}
+
+	text := strings.TrimSpace(content[line-1])
+	return text != "" && !strings.HasPrefix(text, "//") && !strings.HasPrefix(text, "#")
+}
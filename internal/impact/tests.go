@@ -0,0 +1,170 @@
+package impact
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/relocate"
+)
+
+// Tests finds the tests affected by a change using the store's reverse index
+// from source lines to tests, built over the covered lines relocated to the
+// current source the way Changes does. Changed test files select the tests
+// they declare.
+func Tests(store *metadata.Store, fileService *files.Service, req files.ImpactRequest) (*files.ImpactResponse, error) {
+	baseDir := fileService.BaseDir()
+	resolver, err := gomod.NewResolver(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	relocator := relocate.New(fileService)
+
+	var ids []files.TestID
+	seen := make(map[files.TestID]bool)
+	add := func(tests []files.TestID) {
+		for _, id := range tests {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	for _, filePath := range req.Files {
+		add(store.TestsCovering(filePath, nil, nil))
+		add(declaredTests(baseDir, filePath, nil))
+	}
+	for _, change := range req.Changes {
+		if len(change.Lines) == 0 {
+			return nil, fmt.Errorf("lines are required for %s", change.Path)
+		}
+		add(store.TestsCovering(change.Path, change.Lines, relocator))
+		add(declaredTests(baseDir, change.Path, change.Lines))
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].TestFile != ids[j].TestFile {
+			return ids[i].TestFile < ids[j].TestFile
+		}
+		return ids[i].TestName < ids[j].TestName
+	})
+
+	response := &files.ImpactResponse{
+		Tests:    ids,
+		Packages: groupByPackage(resolver, ids),
+	}
+	if response.Tests == nil {
+		response.Tests = []files.TestID{}
+	}
+
+	return response, nil
+}
+
+// declaredTests returns the tests declared in a Go test file, limited to
+// those whose body intersects the given ranges when any are provided
+func declaredTests(baseDir, filePath string, ranges []files.LineRange) []files.TestID {
+	if !strings.HasSuffix(filePath, "_test.go") {
+		return nil
+	}
+
+	discovered, err := analysis.DiscoverTests(baseDir, path.Dir(filePath))
+	if err != nil {
+		return nil
+	}
+
+	var ids []files.TestID
+	for _, test := range discovered {
+		if test.File != path.Clean(filePath) {
+			continue
+		}
+		if len(ranges) > 0 && !intersects(ranges, test.StartLine, test.EndLine) {
+			continue
+		}
+		ids = append(ids, files.TestID{TestFile: test.File, TestName: test.Name})
+	}
+
+	return ids
+}
+
+// intersects reports whether any range overlaps start..end
+func intersects(ranges []files.LineRange, start, end int) bool {
+	for _, r := range ranges {
+		if r.Start <= end && r.End >= start {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByPackage groups Go tests by package and builds go test -run patterns
+func groupByPackage(resolver *gomod.Resolver, ids []files.TestID) []files.PackageTests {
+	byPackage := make(map[string]*files.PackageTests)
+	var order []string
+
+	for _, id := range ids {
+		if !strings.HasSuffix(id.TestFile, "_test.go") {
+			continue
+		}
+
+		pkgPath, ok := resolver.PackagePath(id.TestFile)
+		if !ok {
+			continue
+		}
+
+		group := byPackage[pkgPath]
+		if group == nil {
+			group = &files.PackageTests{
+				Package: pkgPath,
+				Dir:     path.Dir(id.TestFile),
+			}
+			byPackage[pkgPath] = group
+			order = append(order, pkgPath)
+		}
+
+		name, _, _ := strings.Cut(id.TestName, "/")
+		if !slices.Contains(group.Tests, name) {
+			group.Tests = append(group.Tests, name)
+		}
+	}
+
+	sort.Strings(order)
+
+	packages := []files.PackageTests{}
+	for _, pkgPath := range order {
+		group := byPackage[pkgPath]
+		sort.Strings(group.Tests)
+
+		quoted := make([]string, len(group.Tests))
+		for i, name := range group.Tests {
+			quoted[i] = regexp.QuoteMeta(name)
+		}
+		group.RunPattern = "^(" + strings.Join(quoted, "|") + ")$"
+
+		group.Command = fmt.Sprintf("go test -run '%s' %s", group.RunPattern, moduleTarget(resolver, group.Dir))
+
+		packages = append(packages, *group)
+	}
+
+	return packages
+}
+
+// moduleTarget returns the ./-relative package argument for go test when run
+// from the root of the module containing dir
+func moduleTarget(resolver *gomod.Resolver, dir string) string {
+	rel := dir
+	if module, ok := resolver.ModuleFor(dir); ok && module.Dir != "." {
+		rel = strings.TrimPrefix(strings.TrimPrefix(dir, module.Dir), "/")
+	}
+	if rel == "" || rel == "." {
+		return "."
+	}
+	return "./" + rel
+}
@@ -0,0 +1,70 @@
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/relocate"
+)
+
+func TestTestsRelocates(t *testing.T) {
+	dir := t.TempDir()
+	source := "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "calc.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(source)
+
+	fileService := files.NewService(dir)
+	test := relocate.New(fileService).FingerprintTest("calc.go", files.TestReference{
+		FunctionName: "Add",
+		TestFile:     "calc_test.go",
+		TestName:     "TestAdd",
+		CoveredLines: files.LineRange{Start: 3, End: 5},
+	})
+	store := metadata.NewStore("")
+	if err := store.SetTestMetadata("calc.go", []metadata.TestReference{test}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add moves down by four lines
+	write("package calc\n\n// Sub subtracts.\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n")
+
+	tests := []struct {
+		name  string
+		lines files.LineRange
+		want  []files.TestID
+	}{
+		{
+			name:  "change in the moved function",
+			lines: files.LineRange{Start: 9, End: 9},
+			want:  []files.TestID{{TestFile: "calc_test.go", TestName: "TestAdd"}},
+		},
+		{
+			name:  "change where the function was",
+			lines: files.LineRange{Start: 4, End: 5},
+			want:  []files.TestID{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := Tests(store, fileService, files.ImpactRequest{
+				Changes: []files.FileChange{{Path: "calc.go", Lines: []files.LineRange{tt.lines}}},
+			})
+			if err != nil {
+				t.Fatalf("Tests() error = %v", err)
+			}
+			if !reflect.DeepEqual(response.Tests, tt.want) {
+				t.Fatalf("Tests() = %v, want %v", response.Tests, tt.want)
+			}
+		})
+	}
+}
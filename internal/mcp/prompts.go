@@ -82,7 +82,9 @@ After identifying all tests, use the **submit-test-metadata** tool with the foll
 - "lineRange" refers to the lines in the TEST file where the test code is located
 - "functionName" must be the source function name from this prompt (not the test name)
 - "coveredLines" refers to the lines in the SOURCE file (%s) that this test covers
-- "inputLines" and "outputLines" refer to lines in the TEST file`, functionName, filePath, filePath, functionName, filePath)
+- "inputLines" and "outputLines" refer to lines in the TEST file; for Go tests they may be omitted and are then detected from the test source
+
+If the function has no tests, use the **suggest-missing-tests** tool instead. Each "testSkeleton" for a Go file is type-checked as a _test.go file of the package; the tool response lists every compile error by skeleton line and column. Fix the reported errors and submit the suggestion again under the same "suggestedName".`, functionName, filePath, filePath, functionName, filePath)
 
 		return []PromptMessage{
 			{
@@ -7,7 +7,12 @@ import (
 	"net/http"
 	"strings"
 
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
 	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/redact"
+	"codebase-view-mcp/internal/relocate"
+	"codebase-view-mcp/internal/skeleton"
 )
 
 // JSON-RPC 2.0 types
@@ -154,13 +159,17 @@ type TextContent struct {
 
 // Handler handles MCP protocol requests
 type Handler struct {
-	metaStore *metadata.Store
+	metaStore    *metadata.Store
+	fileService  *files.Service
+	materializer *skeleton.Materializer
 }
 
 // NewHandler creates a new MCP handler
-func NewHandler(metaStore *metadata.Store) *Handler {
+func NewHandler(metaStore *metadata.Store, fileService *files.Service, materializer *skeleton.Materializer) *Handler {
 	return &Handler{
-		metaStore: metaStore,
+		metaStore:    metaStore,
+		fileService:  fileService,
+		materializer: materializer,
 	}
 }
 
@@ -235,6 +244,8 @@ func (h *Handler) handleToolsCall(params json.RawMessage) (interface{}, error) {
 		return h.executeSubmitTestMetadata(callParams.Arguments)
 	case "suggest-missing-tests":
 		return h.executeSuggestMissingTests(callParams.Arguments)
+	case "materialize-suggestion":
+		return h.executeMaterializeSuggestion(callParams.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", callParams.Name)
 	}
@@ -263,7 +274,13 @@ func (h *Handler) executeSubmitTestMetadata(args map[string]interface{}) (interf
 	if err := json.Unmarshal(testsJSON, &tests); err != nil {
 		return nil, fmt.Errorf("invalid tests format: %w", err)
 	}
+	if _, err := h.fileService.Resolve(sourceFile); err != nil {
+		return nil, fmt.Errorf("sourceFile: %w", err)
+	}
 	for i, test := range tests {
+		if _, err := h.fileService.Resolve(test.TestFile); err != nil {
+			return nil, fmt.Errorf("testFile for test %d (%s): %w", i, test.TestName, err)
+		}
 		if strings.TrimSpace(test.FunctionName) == "" {
 			return nil, fmt.Errorf("functionName is required for test %d (%s)", i, test.TestName)
 		}
@@ -284,21 +301,91 @@ func (h *Handler) executeSubmitTestMetadata(args map[string]interface{}) (interf
 		}
 	}
 
+	// Fill missing input and output lines from the Go test source and
+	// flag submitted ranges that disagree with it
+	warnings := h.detectTestLines(tests)
+
+	// Extract table-driven cases, subtests and fixtures from the test
+	// source, and fingerprint the referenced ranges so they can follow
+	// later edits
+	relocator := relocate.New(h.fileService)
+	for i, test := range tests {
+		testPath, _ := h.fileService.Resolve(test.TestFile)
+		if len(test.Cases) == 0 {
+			cases, err := analysis.TestCases(testPath, test.TestName)
+			if err == nil {
+				tests[i].Cases = cases
+			}
+		}
+		if len(test.Fixtures) == 0 && strings.HasSuffix(test.TestFile, "_test.go") {
+			fixtures, err := analysis.TestFixtures(h.fileService.BaseDir(), test.TestFile, test.TestName)
+			if err == nil {
+				tests[i].Fixtures = fixtures
+			}
+		}
+		tests[i] = relocator.FingerprintTest(sourceFile, tests[i])
+	}
+
 	// Store metadata (merge with existing tests)
 	if err := h.metaStore.AddTestMetadata(sourceFile, tests); err != nil {
 		return nil, fmt.Errorf("failed to store metadata: %w", err)
 	}
 
+	text := fmt.Sprintf("Successfully stored test metadata for %s (%d tests)", sourceFile, len(tests))
+	if len(warnings) > 0 {
+		text += "\n\nPlease check these ranges against the test source:\n- " + strings.Join(warnings, "\n- ")
+	}
+
 	return ToolsCallResult{
 		Content: []ContentItem{
 			{
 				Type: "text",
-				Text: fmt.Sprintf("Successfully stored test metadata for %s (%d tests)", sourceFile, len(tests)),
+				Text: text,
 			},
 		},
 	}, nil
 }
 
+// detectTestLines fills the missing inputLines and outputLines of Go tests
+// with the lines detected in the test source, and describes every submitted
+// range that does not overlap the detected one
+func (h *Handler) detectTestLines(tests []metadata.TestReference) []string {
+	var warnings []string
+	for i, test := range tests {
+		if !strings.HasSuffix(test.TestFile, "_test.go") {
+			continue
+		}
+		testPath, err := h.fileService.Resolve(test.TestFile)
+		if err != nil {
+			continue
+		}
+
+		detected, err := analysis.DetectLines(testPath, test.TestName, test.FunctionName)
+		if err != nil {
+			continue
+		}
+
+		for _, field := range []struct {
+			name      string
+			submitted *metadata.LineRange
+			detected  metadata.LineRange
+		}{
+			{"inputLines", &tests[i].InputLines, detected.InputLines},
+			{"outputLines", &tests[i].OutputLines, detected.OutputLines},
+		} {
+			switch {
+			case field.detected.Start == 0:
+			case field.submitted.Start == 0:
+				*field.submitted = field.detected
+			case field.submitted.End < field.detected.Start || field.submitted.Start > field.detected.End:
+				warnings = append(warnings, fmt.Sprintf("%s %d-%d of %s does not overlap the detected lines %d-%d",
+					field.name, field.submitted.Start, field.submitted.End, test.TestName, field.detected.Start, field.detected.End))
+			}
+		}
+	}
+	return warnings
+}
+
 func validateRequiredLineRange(field string, lineRange metadata.LineRange) error {
 	if lineRange.Start == 0 && lineRange.End == 0 {
 		return fmt.Errorf("%s must be non-zero", field)
@@ -349,21 +436,103 @@ func (h *Handler) executeSuggestMissingTests(args map[string]interface{}) (inter
 		return nil, fmt.Errorf("invalid suggestions format: %w", err)
 	}
 
+	if _, err := h.fileService.Resolve(sourceFile); err != nil {
+		return nil, fmt.Errorf("sourceFile: %w", err)
+	}
+
 	// Set the sourceFile on each suggestion
 	for i := range suggestions {
 		suggestions[i].SourceFile = sourceFile
 	}
 
+	// Type-check Go skeletons so the agent learns what does not compile
+	if strings.HasSuffix(sourceFile, ".go") {
+		skeleton.CheckSuggestions(h.fileService.BaseDir(), suggestions)
+	}
+
 	// Store suggestions (merge with existing)
 	if err := h.metaStore.AddSuggestions(sourceFile, suggestions); err != nil {
 		return nil, fmt.Errorf("failed to store suggestions: %w", err)
 	}
 
+	text := fmt.Sprintf("Successfully stored %d test suggestion(s) for %s", len(suggestions), sourceFile)
+	for _, suggestion := range suggestions {
+		if suggestion.Check == nil || suggestion.Check.Compiles {
+			continue
+		}
+		text += fmt.Sprintf("\n\nThe skeleton of %s does not compile as a _test.go file of the package:", suggestion.SuggestedName)
+		for _, d := range suggestion.Check.Diagnostics {
+			if d.Line > 0 {
+				text += fmt.Sprintf("\n- line %d, column %d: %s", d.Line, d.Column, d.Message)
+			} else {
+				text += "\n- " + d.Message
+			}
+		}
+	}
+
+	return ToolsCallResult{
+		Content: []ContentItem{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+// executeMaterializeSuggestion executes the materialize-suggestion tool
+func (h *Handler) executeMaterializeSuggestion(args map[string]interface{}) (interface{}, error) {
+	sourceFile, ok := args["sourceFile"].(string)
+	if !ok {
+		return nil, fmt.Errorf("sourceFile is required and must be a string")
+	}
+
+	suggestedName, ok := args["suggestedName"].(string)
+	if !ok {
+		return nil, fmt.Errorf("suggestedName is required and must be a string")
+	}
+
+	apply, _ := args["apply"].(bool)
+
+	if h.materializer == nil {
+		return nil, fmt.Errorf("materializing suggestions is not available")
+	}
+	for _, p := range []string{sourceFile, strings.TrimSuffix(sourceFile, ".go") + "_test.go"} {
+		if _, err := h.fileService.Resolve(p); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := h.materializer.MaterializeStored(h.metaStore, sourceFile, suggestedName, apply)
+	if err != nil {
+		return nil, err
+	}
+
+	// The diff quotes the test file; secrets in it do not go to the agent
+	diff, redactions := redact.Text(response.TestFile, response.Diff)
+	response.Diff = diff
+
+	var text string
+	switch {
+	case response.Applied:
+		text = fmt.Sprintf("Applied %s to %s:\n\n```diff\n%s```", suggestedName, response.TestFile, response.Diff)
+	case response.Created:
+		text = fmt.Sprintf("Creating %s for %s:\n\n```diff\n%s```", response.TestFile, suggestedName, response.Diff)
+	default:
+		text = fmt.Sprintf("Inserting %s into %s:\n\n```diff\n%s```", suggestedName, response.TestFile, response.Diff)
+	}
+	if len(redactions) > 0 {
+		text += fmt.Sprintf("\n\n%d secret(s) in the diff were redacted:", len(redactions))
+		for _, r := range redactions {
+			text += fmt.Sprintf("\n- diff line %d: %s", r.Line, r.Kind)
+		}
+	}
+
 	return ToolsCallResult{
 		Content: []ContentItem{
 			{
 				Type: "text",
-				Text: fmt.Sprintf("Successfully stored %d test suggestion(s) for %s", len(suggestions), sourceFile),
+				Text: text,
 			},
 		},
 	}, nil
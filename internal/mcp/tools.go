@@ -119,7 +119,7 @@ func GetTools() []Tool {
 								},
 								"testSkeleton": {
 									"type": "string",
-									"description": "Code skeleton for the suggested test"
+									"description": "Code skeleton for the suggested test. Go skeletons are type-checked as a _test.go file of the source file's package (without a package clause the package under test is assumed, and common imports are added when none are given); compile errors are listed in the response"
 								},
 								"priority": {
 									"type": "string",
@@ -134,5 +134,27 @@ func GetTools() []Tool {
 				"required": ["sourceFile", "suggestions"]
 			}`),
 		},
+		{
+			Name:        "materialize-suggestion",
+			Description: "Turn a stored test suggestion into a unified diff that inserts its skeleton into the _test.go file next to the source file, creating the file with the package clause and imports when it does not exist. With apply set, and when the server allows it, the patch is written to the working tree and the suggestion is removed.",
+			InputSchema: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"sourceFile": {
+						"type": "string",
+						"description": "Path to the source file the suggestion belongs to"
+					},
+					"suggestedName": {
+						"type": "string",
+						"description": "Suggested test name identifying the suggestion"
+					},
+					"apply": {
+						"type": "boolean",
+						"description": "Write the patch to the working tree (requires the server to allow it)"
+					}
+				},
+				"required": ["sourceFile", "suggestedName"]
+			}`),
+		},
 	}
 }
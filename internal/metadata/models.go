@@ -8,4 +8,29 @@ type (
 	TestReference  = files.TestReference
 	TestSuggestion = files.TestSuggestion
 	LineRange      = files.LineRange
+	FileCoverage   = files.FileCoverage
+	CoverageBlock  = files.CoverageBlock
+	TestResult     = files.TestResult
+	TestCase       = files.TestCase
+	MutationRun    = files.MutationRun
 )
+
+// OriginCoverageRun marks test references created by the isolated coverage runner
+const OriginCoverageRun = "coverage-run"
+
+// CoveredLines returns the source lines a test covers: the measured line set
+// when available, otherwise every line of the declared coveredLines range
+func CoveredLines(test TestReference) []int {
+	if len(test.CoveredLineSet) > 0 {
+		return test.CoveredLineSet
+	}
+
+	var lines []int
+	for line := test.CoveredLines.Start; line <= test.CoveredLines.End; line++ {
+		if line > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
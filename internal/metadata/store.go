@@ -16,12 +16,35 @@ type Store struct {
 	mu       sync.RWMutex
 	metadata map[string]*FileMetadata // key: file path
 	filePath string                   // path to JSON persistence file
+	version  uint64                   // incremented on every modification
+	modified map[string]uint64        // file path -> version of its last modification
+
+	indexMu   sync.Mutex
+	lineIndex map[string]*lineIndex // file path -> reverse index of its covered lines
+}
+
+// lineIndex maps the lines of a file to the tests covering them. It is valid
+// while neither the file's metadata nor, when relocated, its source changed.
+type lineIndex struct {
+	version uint64 // of the file's last metadata modification
+	stamp   string // of the source the lines were relocated to
+	lines   map[int][]files.TestID
+}
+
+// Relocator moves the ranges of a file's test references to where their code
+// is now
+type Relocator interface {
+	// SourceStamp identifies the current content of a file
+	SourceStamp(filePath string) string
+	// ResolveTests returns the references with their ranges relocated
+	ResolveTests(filePath string, tests []TestReference) []TestReference
 }
 
 // NewStore creates a new metadata store
 func NewStore(persistPath string) *Store {
 	store := &Store{
 		metadata: make(map[string]*FileMetadata),
+		modified: make(map[string]uint64),
 		filePath: persistPath,
 	}
 
@@ -41,6 +64,8 @@ func (s *Store) SetTestMetadata(filePath string, tests []TestReference) error {
 
 	s.metadata[filePath] = &FileMetadata{Tests: tests}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
@@ -83,6 +108,8 @@ func (s *Store) AddTestMetadata(filePath string, tests []TestReference) error {
 		s.metadata[filePath].Tests = mergedTests
 	}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
@@ -112,6 +139,37 @@ func (s *Store) GetAllMetadata() map[string]*FileMetadata {
 	return copy
 }
 
+// Version returns a counter that changes whenever the stored data changes
+func (s *Store) Version() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version
+}
+
+// ModifiedSince returns the files whose metadata changed after the given version
+func (s *Store) ModifiedSince(version uint64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var changed []string
+	for filePath, v := range s.modified {
+		if v > version {
+			changed = append(changed, filePath)
+		}
+	}
+
+	return changed
+}
+
+// touch bumps the version and records which files changed. Callers hold s.mu.
+func (s *Store) touch(filePaths ...string) {
+	s.version++
+	for _, filePath := range filePaths {
+		s.modified[filePath] = s.version
+	}
+}
+
 // load loads metadata from the JSON file
 func (s *Store) load() error {
 	data, err := os.ReadFile(s.filePath)
@@ -184,6 +242,8 @@ func (s *Store) AddSuggestions(filePath string, suggestions []TestSuggestion) er
 		s.metadata[filePath].Suggestions = mergedSuggestions
 	}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
@@ -204,6 +264,35 @@ func (s *Store) GetSuggestions(filePath string) []TestSuggestion {
 	return meta.Suggestions
 }
 
+// RemoveSuggestion removes the suggestion with the given suggested name from a file
+func (s *Store) RemoveSuggestion(filePath string, suggestedName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.metadata[filePath]
+	if existing == nil {
+		return nil // No metadata for this file
+	}
+
+	// Filter out the suggestion to remove
+	filtered := make([]TestSuggestion, 0, len(existing.Suggestions))
+	for _, sugg := range existing.Suggestions {
+		if sugg.SuggestedName != suggestedName {
+			filtered = append(filtered, sugg)
+		}
+	}
+
+	s.metadata[filePath].Suggestions = filtered
+
+	s.touch(filePath)
+
+	if s.filePath != "" {
+		return s.saveUnsafe()
+	}
+
+	return nil
+}
+
 // ==================== COMMENT METHODS ====================
 
 // AddComment adds a new comment to a file
@@ -231,6 +320,8 @@ func (s *Store) AddComment(filePath string, comment files.Comment) (files.Commen
 		s.metadata[filePath].Comments = append(existing.Comments, comment)
 	}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		if err := s.saveUnsafe(); err != nil {
 			return comment, err
@@ -258,6 +349,8 @@ func (s *Store) UpdateComment(filePath string, commentID string, content string)
 		}
 	}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
@@ -285,6 +378,8 @@ func (s *Store) DeleteComment(filePath string, commentID string) error {
 
 	s.metadata[filePath].Comments = filtered
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
@@ -323,9 +418,337 @@ func (s *Store) ToggleCommentResolved(filePath string, commentID string) error {
 		}
 	}
 
+	s.touch(filePath)
+
 	if s.filePath != "" {
 		return s.saveUnsafe()
 	}
 
 	return nil
 }
+
+// ==================== COVERAGE METHODS ====================
+
+// testKey identifies a test across source files
+func testKey(test TestReference) string {
+	return test.TestFile + ":" + test.TestName
+}
+
+// FindTestReferences returns every stored reference to a test, keyed by source file
+func (s *Store) FindTestReferences(testFile, testName string) map[string]TestReference {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := testFile + ":" + testName
+	result := make(map[string]TestReference)
+	for filePath, meta := range s.metadata {
+		for _, test := range meta.Tests {
+			if testKey(test) == key {
+				result[filePath] = test
+			}
+		}
+	}
+
+	return result
+}
+
+// ApplyMeasuredCoverage stores measured covered lines for the tests in ran.
+// measured maps source files to references carrying a CoveredLineSet. Existing
+// references keep their declared fields and only receive the measured lines;
+// references to tests in ran that no longer reach a file lose their measured
+// lines, or are dropped entirely when the runner created them. The
+// CoverageHash of each test in ran is recorded as its last coverage run, so
+// that tests covering nothing are not measured again either.
+func (s *Store) ApplyMeasuredCoverage(ran []TestReference, measured map[string][]TestReference) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ranKeys := make(map[string]bool, len(ran))
+	var changed []string
+	for _, test := range ran {
+		ranKeys[testKey(test)] = true
+
+		if test.CoverageHash == "" {
+			continue
+		}
+		meta := s.metadata[test.TestFile]
+		if meta == nil {
+			meta = &FileMetadata{}
+			s.metadata[test.TestFile] = meta
+		}
+		if meta.CoverageRuns == nil {
+			meta.CoverageRuns = make(map[string]string)
+		}
+		meta.CoverageRuns[test.TestName] = test.CoverageHash
+		changed = append(changed, test.TestFile)
+	}
+
+	// Clear previous measurements of the tests that were re-run
+	for filePath, meta := range s.metadata {
+		kept := make([]TestReference, 0, len(meta.Tests))
+		cleared := false
+		for _, test := range meta.Tests {
+			if ranKeys[testKey(test)] {
+				if test.Origin == OriginCoverageRun {
+					continue
+				}
+				cleared = true
+				test.CoveredLineSet = nil
+				test.CoverageHash = ""
+			}
+			kept = append(kept, test)
+		}
+		if len(kept) != len(meta.Tests) || cleared {
+			changed = append(changed, filePath)
+		}
+		s.metadata[filePath].Tests = kept
+	}
+
+	for filePath, tests := range measured {
+		changed = append(changed, filePath)
+		meta := s.metadata[filePath]
+		if meta == nil {
+			meta = &FileMetadata{}
+			s.metadata[filePath] = meta
+		}
+
+		for _, test := range tests {
+			found := false
+			for i := range meta.Tests {
+				if testKey(meta.Tests[i]) == testKey(test) {
+					meta.Tests[i].CoveredLineSet = test.CoveredLineSet
+					meta.Tests[i].CoverageHash = test.CoverageHash
+					found = true
+					break
+				}
+			}
+			if !found {
+				meta.Tests = append(meta.Tests, test)
+			}
+		}
+	}
+
+	s.touch(changed...)
+
+	if s.filePath != "" {
+		return s.saveUnsafe()
+	}
+
+	return nil
+}
+
+// ImportCoverage replaces the measured coverage of every file in the given map
+func (s *Store) ImportCoverage(coverage map[string]*FileCoverage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for filePath, fileCoverage := range coverage {
+		existing := s.metadata[filePath]
+		if existing == nil {
+			s.metadata[filePath] = &FileMetadata{Coverage: fileCoverage}
+		} else {
+			existing.Coverage = fileCoverage
+		}
+	}
+
+	changed := make([]string, 0, len(coverage))
+	for filePath := range coverage {
+		changed = append(changed, filePath)
+	}
+	s.touch(changed...)
+
+	if s.filePath != "" {
+		return s.saveUnsafe()
+	}
+
+	return nil
+}
+
+// GetCoverage retrieves the measured coverage for a file
+func (s *Store) GetCoverage(filePath string) *FileCoverage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	meta := s.metadata[filePath]
+	if meta == nil {
+		return nil
+	}
+
+	return meta.Coverage
+}
+
+// SetMutationRun stores the mutation testing outcome of a function,
+// replacing the previous run of the same function
+func (s *Store) SetMutationRun(filePath string, run MutationRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.metadata[filePath]
+	if existing == nil {
+		existing = &FileMetadata{}
+		s.metadata[filePath] = existing
+	}
+	if existing.Mutations == nil {
+		existing.Mutations = make(map[string]MutationRun)
+	}
+	existing.Mutations[run.Function] = run
+
+	s.touch(filePath)
+
+	if s.filePath != "" {
+		return s.saveUnsafe()
+	}
+
+	return nil
+}
+
+// CoverageRunHash returns the module hash a test was last measured against by
+// an isolated coverage run, or "" if it never was
+func (s *Store) CoverageRunHash(testFile, testName string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if meta := s.metadata[testFile]; meta != nil {
+		return meta.CoverageRuns[testName]
+	}
+	return ""
+}
+
+// ==================== TEST RESULT METHODS ====================
+
+// SetTestResults records test results, keyed by test file and then test name
+func (s *Store) SetTestResults(results map[string]map[string]TestResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for testFile, byName := range results {
+		meta := s.metadata[testFile]
+		if meta == nil {
+			meta = &FileMetadata{}
+			s.metadata[testFile] = meta
+		}
+		if meta.TestResults == nil {
+			meta.TestResults = make(map[string]TestResult)
+		}
+
+		for testName, result := range byName {
+			meta.TestResults[testName] = result
+		}
+	}
+
+	changed := make([]string, 0, len(results))
+	for testFile := range results {
+		changed = append(changed, testFile)
+	}
+	s.touch(changed...)
+
+	if s.filePath != "" {
+		return s.saveUnsafe()
+	}
+
+	return nil
+}
+
+// GetTestResult retrieves the last recorded result of a test
+func (s *Store) GetTestResult(testFile, testName string) *TestResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	meta := s.metadata[testFile]
+	if meta == nil {
+		return nil
+	}
+
+	result, ok := meta.TestResults[testName]
+	if !ok {
+		return nil
+	}
+
+	return &result
+}
+
+// ==================== IMPACT METHODS ====================
+
+// TestsCovering returns the tests whose covered lines intersect the given
+// ranges of a file. Without ranges every test referencing the file is returned.
+// The lines are looked up in a reverse index of the file, built over the
+// covered lines as relocated by relocator, which may be nil to use the stored
+// lines as they are.
+func (s *Store) TestsCovering(filePath string, ranges []LineRange, relocator Relocator) []files.TestID {
+	if len(ranges) == 0 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		var tests []files.TestID
+		seen := make(map[files.TestID]bool)
+		if meta := s.metadata[filePath]; meta != nil {
+			for _, test := range meta.Tests {
+				id := files.TestID{TestFile: test.TestFile, TestName: test.TestName}
+				if !seen[id] {
+					seen[id] = true
+					tests = append(tests, id)
+				}
+			}
+		}
+		return tests
+	}
+
+	index := s.lineIndexFor(filePath, relocator)
+
+	var tests []files.TestID
+	seen := make(map[files.TestID]bool)
+	for _, r := range ranges {
+		for line := r.Start; line <= r.End; line++ {
+			for _, id := range index[line] {
+				if !seen[id] {
+					seen[id] = true
+					tests = append(tests, id)
+				}
+			}
+		}
+	}
+
+	return tests
+}
+
+// lineIndexFor returns the line -> tests index of a file, rebuilding it when
+// the file's metadata or the source it is relocated to changed since it was
+// last built
+func (s *Store) lineIndexFor(filePath string, relocator Relocator) map[int][]files.TestID {
+	stamp := ""
+	if relocator != nil {
+		stamp = relocator.SourceStamp(filePath)
+	}
+
+	s.mu.RLock()
+	version := s.modified[filePath]
+	var tests []TestReference
+	if meta := s.metadata[filePath]; meta != nil {
+		tests = append(tests, meta.Tests...)
+	}
+	s.mu.RUnlock()
+
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	if index := s.lineIndex[filePath]; index != nil && index.version == version && index.stamp == stamp {
+		return index.lines
+	}
+
+	if relocator != nil {
+		tests = relocator.ResolveTests(filePath, tests)
+	}
+	lines := make(map[int][]files.TestID)
+	for _, test := range tests {
+		id := files.TestID{TestFile: test.TestFile, TestName: test.TestName}
+		for _, line := range CoveredLines(test) {
+			lines[line] = append(lines[line], id)
+		}
+	}
+
+	if s.lineIndex == nil {
+		s.lineIndex = make(map[string]*lineIndex)
+	}
+	s.lineIndex[filePath] = &lineIndex{version: version, stamp: stamp, lines: lines}
+	return lines
+}
@@ -0,0 +1,191 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func TestStoreTestsCovering(t *testing.T) {
+	store := NewStore("")
+	if err := store.SetTestMetadata("a.go", []TestReference{
+		{TestFile: "a_test.go", TestName: "TestFirst", CoveredLines: LineRange{Start: 1, End: 10}},
+		{TestFile: "a_test.go", TestName: "TestSecond", CoveredLines: LineRange{Start: 20, End: 30}},
+	}); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+
+	t.Run("returns tests intersecting ranges", func(t *testing.T) {
+		tests := store.TestsCovering("a.go", []LineRange{{Start: 8, End: 12}}, nil)
+		want := files.TestID{TestFile: "a_test.go", TestName: "TestFirst"}
+		if len(tests) != 1 || tests[0] != want {
+			t.Fatalf("tests = %v, want [%v]", tests, want)
+		}
+	})
+
+	t.Run("returns all tests without ranges", func(t *testing.T) {
+		if tests := store.TestsCovering("a.go", nil, nil); len(tests) != 2 {
+			t.Fatalf("tests count = %d, want %d", len(tests), 2)
+		}
+	})
+
+	t.Run("rebuilds index after changes", func(t *testing.T) {
+		if err := store.AddTestMetadata("a.go", []TestReference{
+			{TestFile: "a_test.go", TestName: "TestThird", CoveredLineSet: []int{40}},
+		}); err != nil {
+			t.Fatalf("add metadata: %v", err)
+		}
+
+		tests := store.TestsCovering("a.go", []LineRange{{Start: 40, End: 40}}, nil)
+		if len(tests) != 1 || tests[0].TestName != "TestThird" {
+			t.Fatalf("tests = %v, want TestThird", tests)
+		}
+	})
+}
+
+func TestStoreApplyMeasuredCoverage(t *testing.T) {
+	store := NewStore("")
+	if err := store.SetTestMetadata("a.go", []TestReference{
+		{FunctionName: "Parse", TestFile: "a_test.go", TestName: "TestParse", Comment: "declared", CoveredLineSet: []int{1, 2}, CoverageHash: "old"},
+		{FunctionName: "Format", TestFile: "a_test.go", TestName: "TestFormat", CoveredLineSet: []int{8}, CoverageHash: "old"},
+		{FunctionName: "Other", TestFile: "a_test.go", TestName: "TestOther", CoveredLineSet: []int{20}, CoverageHash: "old"},
+	}); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+	if err := store.SetTestMetadata("b.go", []TestReference{
+		{FunctionName: "helper", TestFile: "a_test.go", TestName: "TestParse", CoveredLineSet: []int{5}, CoverageHash: "old", Origin: OriginCoverageRun},
+	}); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+
+	ran := []TestReference{
+		{TestFile: "a_test.go", TestName: "TestParse"},
+		{TestFile: "a_test.go", TestName: "TestFormat"},
+	}
+	measured := map[string][]TestReference{
+		"a.go": {
+			{FunctionName: "guessed", TestFile: "a_test.go", TestName: "TestParse", CoveredLineSet: []int{3, 4}, CoverageHash: "new", Origin: OriginCoverageRun},
+		},
+		"c.go": {
+			{FunctionName: "Write", TestFile: "a_test.go", TestName: "TestFormat", CoveredLineSet: []int{7}, CoverageHash: "new", Origin: OriginCoverageRun},
+		},
+	}
+	if err := store.ApplyMeasuredCoverage(ran, measured); err != nil {
+		t.Fatalf("ApplyMeasuredCoverage() error = %v", err)
+	}
+
+	tests := func(filePath string) map[string]TestReference {
+		result := make(map[string]TestReference)
+		if meta := store.GetTestMetadata(filePath); meta != nil {
+			for _, test := range meta.Tests {
+				result[test.TestName] = test
+			}
+		}
+		return result
+	}
+
+	t.Run("merges measured lines into declared references", func(t *testing.T) {
+		got := tests("a.go")["TestParse"]
+		if got.FunctionName != "Parse" || got.Comment != "declared" || got.Origin != "" {
+			t.Fatalf("TestParse = %+v, want its declared fields kept", got)
+		}
+		if !reflect.DeepEqual(got.CoveredLineSet, []int{3, 4}) || got.CoverageHash != "new" {
+			t.Fatalf("TestParse lines = %v (%s), want [3 4] (new)", got.CoveredLineSet, got.CoverageHash)
+		}
+		if len(tests("a.go")) != 3 {
+			t.Fatalf("a.go tests = %v, want 3 without duplicates", tests("a.go"))
+		}
+	})
+
+	t.Run("clears declared references of re-run tests", func(t *testing.T) {
+		got := tests("a.go")["TestFormat"]
+		if got.FunctionName != "Format" || got.CoveredLineSet != nil || got.CoverageHash != "" {
+			t.Fatalf("TestFormat = %+v, want kept without measured lines", got)
+		}
+	})
+
+	t.Run("keeps tests that did not run", func(t *testing.T) {
+		got := tests("a.go")["TestOther"]
+		if !reflect.DeepEqual(got.CoveredLineSet, []int{20}) || got.CoverageHash != "old" {
+			t.Fatalf("TestOther = %+v, want unchanged", got)
+		}
+	})
+
+	t.Run("drops runner references no longer reached", func(t *testing.T) {
+		if got := tests("b.go"); len(got) != 0 {
+			t.Fatalf("b.go tests = %v, want none", got)
+		}
+	})
+
+	t.Run("adds references to new files", func(t *testing.T) {
+		got := tests("c.go")["TestFormat"]
+		if got.FunctionName != "Write" || got.Origin != OriginCoverageRun || !reflect.DeepEqual(got.CoveredLineSet, []int{7}) {
+			t.Fatalf("c.go TestFormat = %+v, want the measured reference", got)
+		}
+	})
+
+	t.Run("updates the reverse index", func(t *testing.T) {
+		covering := store.TestsCovering("a.go", []LineRange{{Start: 3, End: 3}}, nil)
+		if len(covering) != 1 || covering[0].TestName != "TestParse" {
+			t.Fatalf("TestsCovering() = %v, want TestParse", covering)
+		}
+	})
+}
+
+// shiftRelocator moves covered lines by a fixed offset and counts how often
+// it was asked to
+type shiftRelocator struct {
+	stamp    string
+	shift    int
+	resolved int
+}
+
+func (r *shiftRelocator) SourceStamp(filePath string) string {
+	return r.stamp
+}
+
+func (r *shiftRelocator) ResolveTests(filePath string, tests []TestReference) []TestReference {
+	r.resolved++
+	moved := make([]TestReference, len(tests))
+	for i, test := range tests {
+		test.CoveredLines = LineRange{Start: test.CoveredLines.Start + r.shift, End: test.CoveredLines.End + r.shift}
+		moved[i] = test
+	}
+	return moved
+}
+
+func TestStoreTestsCoveringRelocated(t *testing.T) {
+	store := NewStore("")
+	if err := store.SetTestMetadata("a.go", []TestReference{
+		{TestFile: "a_test.go", TestName: "TestFirst", CoveredLines: LineRange{Start: 1, End: 10}},
+	}); err != nil {
+		t.Fatalf("set metadata: %v", err)
+	}
+	relocator := &shiftRelocator{stamp: "v1", shift: 5}
+
+	tests := []struct {
+		name         string
+		stamp        string
+		line         int
+		want         int
+		wantResolved int
+	}{
+		{name: "relocated lines", stamp: "v1", line: 15, want: 1, wantResolved: 1},
+		{name: "stored lines no longer match", stamp: "v1", line: 2, want: 0, wantResolved: 1},
+		{name: "source changed", stamp: "v2", line: 15, want: 1, wantResolved: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relocator.stamp = tt.stamp
+			got := store.TestsCovering("a.go", []LineRange{{Start: tt.line, End: tt.line}}, relocator)
+			if len(got) != tt.want {
+				t.Fatalf("TestsCovering() = %v, want %d tests", got, tt.want)
+			}
+			if relocator.resolved != tt.wantResolved {
+				t.Fatalf("relocated %d times, want %d", relocator.resolved, tt.wantResolved)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package mutation
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Mutant is a single source change inside a function
+type Mutant struct {
+	Line        int
+	Operator    string // conditional, arithmetic or return
+	Original    string
+	Replacement string
+	offset      int // byte offset of the replaced text
+	length      int // length of the replaced text
+}
+
+// Apply returns src with the mutation applied
+func (m Mutant) Apply(src []byte) []byte {
+	out := make([]byte, 0, len(src)+len(m.Replacement))
+	out = append(out, src[:m.offset]...)
+	out = append(out, m.Replacement...)
+	return append(out, src[m.offset+m.length:]...)
+}
+
+// conditionalSwaps flips comparisons and boolean connectives
+var conditionalSwaps = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// arithmeticSwaps replaces arithmetic operators
+var arithmeticSwaps = map[token.Token]token.Token{
+	token.ADD:        token.SUB,
+	token.SUB:        token.ADD,
+	token.MUL:        token.QUO,
+	token.QUO:        token.MUL,
+	token.REM:        token.MUL,
+	token.ADD_ASSIGN: token.SUB_ASSIGN,
+	token.SUB_ASSIGN: token.ADD_ASSIGN,
+	token.MUL_ASSIGN: token.QUO_ASSIGN,
+	token.QUO_ASSIGN: token.MUL_ASSIGN,
+	token.INC:        token.DEC,
+	token.DEC:        token.INC,
+}
+
+// Mutants lists the mutants of a function declaration parsed from src,
+// ordered by position
+func Mutants(fset *token.FileSet, fn *ast.FuncDecl, src []byte) []Mutant {
+	if fn.Body == nil {
+		return nil
+	}
+
+	var mutants []Mutant
+	swap := func(pos token.Pos, op token.Token, swaps map[token.Token]token.Token, operator string) {
+		replacement, ok := swaps[op]
+		if !ok {
+			return
+		}
+		position := fset.Position(pos)
+		mutants = append(mutants, Mutant{
+			Line:        position.Line,
+			Operator:    operator,
+			Original:    op.String(),
+			Replacement: replacement.String(),
+			offset:      position.Offset,
+			length:      len(op.String()),
+		})
+	}
+
+	var finalReturn ast.Stmt
+	if n := len(fn.Body.List); n > 0 {
+		finalReturn = fn.Body.List[n-1]
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BinaryExpr:
+			if _, ok := conditionalSwaps[node.Op]; ok {
+				swap(node.OpPos, node.Op, conditionalSwaps, "conditional")
+			} else if !isStringConcat(node) {
+				swap(node.OpPos, node.Op, arithmeticSwaps, "arithmetic")
+			}
+		case *ast.AssignStmt:
+			swap(node.TokPos, node.Tok, arithmeticSwaps, "arithmetic")
+		case *ast.IncDecStmt:
+			swap(node.TokPos, node.Tok, arithmeticSwaps, "arithmetic")
+		case *ast.ReturnStmt:
+			// Dropping the final return would not compile; early returns can go
+			if node == finalReturn {
+				break
+			}
+			start, end := fset.Position(node.Pos()), fset.Position(node.End())
+			original, _, _ := strings.Cut(string(src[start.Offset:end.Offset]), "\n")
+			mutants = append(mutants, Mutant{
+				Line:        start.Line,
+				Operator:    "return",
+				Original:    original,
+				Replacement: "",
+				offset:      start.Offset,
+				length:      end.Offset - start.Offset,
+			})
+		}
+		return true
+	})
+
+	sort.SliceStable(mutants, func(i, j int) bool {
+		return mutants[i].offset < mutants[j].offset
+	})
+
+	return mutants
+}
+
+// isStringConcat reports whether a + expression has a string literal operand
+func isStringConcat(expr *ast.BinaryExpr) bool {
+	if expr.Op != token.ADD {
+		return false
+	}
+	for _, operand := range []ast.Expr{expr.X, expr.Y} {
+		if lit, ok := operand.(*ast.BasicLit); ok && (lit.Kind == token.STRING || lit.Kind == token.CHAR) {
+			return true
+		}
+	}
+	return false
+}
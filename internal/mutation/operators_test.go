@@ -0,0 +1,66 @@
+package mutation
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const operatorsSource = `package sample
+
+func Clamp(v, limit int) int {
+	if v > limit && limit != 0 {
+		return limit
+	}
+	v++
+	return v * 2 + len("a" + "b")
+}
+`
+
+func TestMutants(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample.go", operatorsSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := file.Decls[0].(*ast.FuncDecl)
+
+	mutants := Mutants(fset, fn, []byte(operatorsSource))
+
+	want := []struct {
+		line        int
+		operator    string
+		original    string
+		replacement string
+	}{
+		{4, "conditional", ">", "<="},
+		{4, "conditional", "&&", "||"},
+		{4, "conditional", "!=", "=="},
+		{5, "return", "return limit", ""},
+		{7, "arithmetic", "++", "--"},
+		{8, "arithmetic", "*", "/"},
+		{8, "arithmetic", "+", "-"},
+	}
+
+	if len(mutants) != len(want) {
+		t.Fatalf("len(mutants) = %d, want %d: %+v", len(mutants), len(want), mutants)
+	}
+	for i, w := range want {
+		m := mutants[i]
+		if m.Line != w.line || m.Operator != w.operator || m.Original != w.original || m.Replacement != w.replacement {
+			t.Fatalf("mutants[%d] = %+v, want %+v", i, m, w)
+		}
+	}
+
+	t.Run("apply", func(t *testing.T) {
+		mutated := string(mutants[1].Apply([]byte(operatorsSource)))
+		if _, err := parser.ParseFile(token.NewFileSet(), "sample.go", mutated, 0); err != nil {
+			t.Fatalf("mutated source does not parse: %v", err)
+		}
+		if want := "if v > limit || limit != 0 {"; !strings.Contains(mutated, want) {
+			t.Fatalf("mutated source lacks %q:\n%s", want, mutated)
+		}
+	})
+}
@@ -0,0 +1,467 @@
+package mutation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/ignore"
+	"codebase-view-mcp/internal/metadata"
+)
+
+var failRe = regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`)
+
+// Runner applies mutants to a function in temporary copies of its module and
+// runs the tests mapped to the function in the metadata store against each one
+type Runner struct {
+	store    *metadata.Store
+	baseDir  string
+	Parallel int           // number of module copies tested concurrently
+	Timeout  time.Duration // limit for one go test run; slower mutants count as killed
+}
+
+// NewRunner creates a mutation runner for the codebase under baseDir
+func NewRunner(store *metadata.Store, baseDir string) *Runner {
+	return &Runner{
+		store:    store,
+		baseDir:  baseDir,
+		Parallel: runtime.NumCPU(),
+		Timeout:  2 * time.Minute,
+	}
+}
+
+// target is the function under mutation and the tests that exercise it
+type target struct {
+	modFile   string // relative to the module directory
+	moduleRel string // module directory relative to the base directory
+	moduleDir string // absolute
+	fn        *ast.FuncDecl
+	name      string
+	src       []byte
+	fset      *token.FileSet
+	tests     []string
+	packages  []string // ./dir patterns relative to the module directory
+}
+
+// RunFunction mutation-tests the named function of a Go file and stores the
+// outcome. functionName is "Func" or "Receiver.Method".
+func (r *Runner) RunFunction(ctx context.Context, relFile, functionName string) (*files.MutationRun, error) {
+	t, err := r.resolve(relFile, functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	mutants := Mutants(t.fset, t.fn, t.src)
+	if len(mutants) == 0 {
+		return nil, fmt.Errorf("no mutation operator applies to %s", t.name)
+	}
+
+	workers := r.Parallel
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(mutants) {
+		workers = len(mutants)
+	}
+
+	copies := make([]string, workers)
+	for i := range copies {
+		dir, err := os.MkdirTemp("", "mutation-*")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		if err := copyTree(ignore.For(r.baseDir), t.moduleDir, t.moduleRel, dir); err != nil {
+			return nil, fmt.Errorf("failed to copy module: %w", err)
+		}
+		if err := absoluteReplaces(ctx, dir, t.moduleDir); err != nil {
+			return nil, fmt.Errorf("failed to rewrite go.mod replacements: %w", err)
+		}
+		copies[i] = dir
+	}
+
+	// Mutants are only meaningful against tests that pass on the original code
+	status, failed, err := r.runTests(ctx, copies[0], t)
+	if err != nil {
+		return nil, err
+	}
+	if status != files.MutantSurvived {
+		return nil, fmt.Errorf("mapped tests do not pass before mutation (%s %s)", status, strings.Join(failed, ", "))
+	}
+
+	results := make([]files.MutantResult, len(mutants))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for _, dir := range copies {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			target := filepath.Join(dir, t.modFile)
+			defer os.WriteFile(target, t.src, 0644)
+
+			for i := range jobs {
+				m := mutants[i]
+				results[i] = files.MutantResult{
+					Line:     m.Line,
+					Operator: m.Operator,
+					Original: m.Original,
+					Mutated:  m.Replacement,
+				}
+
+				// A mutant that cannot be tested does not spoil the others
+				if err := os.WriteFile(target, m.Apply(t.src), 0644); err != nil {
+					results[i].Status, results[i].Error = files.MutantInvalid, err.Error()
+					continue
+				}
+				status, failed, err := r.runTests(ctx, dir, t)
+				if err != nil {
+					results[i].Status, results[i].Error = files.MutantInvalid, err.Error()
+					continue
+				}
+				results[i].Status = status
+				results[i].KilledBy = failed
+			}
+		}(dir)
+	}
+
+	for i := range mutants {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	run := files.MutationRun{
+		Function: t.name,
+		LineRange: files.LineRange{
+			Start: t.fset.Position(t.fn.Pos()).Line,
+			End:   t.fset.Position(t.fn.End()).Line,
+		},
+		Tests:   t.tests,
+		Mutants: results,
+		RunAt:   time.Now(),
+	}
+	for _, result := range results {
+		switch result.Status {
+		case files.MutantKilled, files.MutantTimeout:
+			run.Killed++
+		case files.MutantSurvived:
+			run.Survived++
+		case files.MutantInvalid:
+			run.Invalid++
+		}
+	}
+	if run.Killed+run.Survived > 0 {
+		run.Score = float64(run.Killed) / float64(run.Killed+run.Survived)
+	}
+
+	if err := r.store.SetMutationRun(relFile, run); err != nil {
+		return &run, fmt.Errorf("failed to store mutation results: %w", err)
+	}
+
+	return &run, nil
+}
+
+// resolve parses the file, finds the function and the tests mapped to it
+func (r *Runner) resolve(relFile, functionName string) (*target, error) {
+	resolver, err := gomod.NewResolver(r.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	module, ok := resolver.ModuleFor(relFile)
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside a Go module", relFile)
+	}
+
+	src, err := os.ReadFile(filepath.Join(r.baseDir, relFile))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, relFile, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &target{
+		modFile:   relativeTo(module.Dir, relFile),
+		moduleRel: module.Dir,
+		moduleDir: filepath.Join(r.baseDir, module.Dir),
+		src:       src,
+		fset:      fset,
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		qualified := analysis.Function{Name: fn.Name.Name, Receiver: analysis.ReceiverName(fn)}.QualifiedName()
+		if qualified == functionName || (fn.Recv == nil && fn.Name.Name == functionName) {
+			t.fn, t.name = fn, qualified
+			break
+		}
+	}
+	if t.fn == nil {
+		return nil, fmt.Errorf("function %s not found in %s", functionName, relFile)
+	}
+
+	start, end := fset.Position(t.fn.Pos()).Line, fset.Position(t.fn.End()).Line
+	seenTest := make(map[string]bool)
+	seenPkg := make(map[string]bool)
+
+	if meta := r.store.GetTestMetadata(relFile); meta != nil {
+		for _, test := range meta.Tests {
+			if !mapsTo(test, t.name, start, end) {
+				continue
+			}
+			if module.Dir != "." && !strings.HasPrefix(test.TestFile, module.Dir+"/") {
+				continue
+			}
+
+			// Subtests run through their top-level test
+			name, _, _ := strings.Cut(test.TestName, "/")
+			if !seenTest[name] {
+				seenTest[name] = true
+				t.tests = append(t.tests, name)
+			}
+
+			pkg := "./" + path.Dir(relativeTo(module.Dir, test.TestFile))
+			if !seenPkg[pkg] {
+				seenPkg[pkg] = true
+				t.packages = append(t.packages, pkg)
+			}
+		}
+	}
+
+	if len(t.tests) == 0 {
+		return nil, fmt.Errorf("no tests are mapped to %s in the metadata store", t.name)
+	}
+
+	sort.Strings(t.tests)
+	sort.Strings(t.packages)
+	return t, nil
+}
+
+// mapsTo reports whether a test reference targets the function by its
+// qualified name or covers any of its lines
+func mapsTo(test files.TestReference, name string, start, end int) bool {
+	if test.FunctionName == name {
+		return true
+	}
+	for _, line := range metadata.CoveredLines(test) {
+		if line >= start && line <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// runTests runs the mapped tests in a module copy. A passing run means the
+// current code survives; failures name the tests that caught it.
+func (r *Runner) runTests(ctx context.Context, dir string, t *target) (string, []string, error) {
+	runCtx := ctx
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	pattern := make([]string, len(t.tests))
+	for i, name := range t.tests {
+		pattern[i] = regexp.QuoteMeta(name)
+	}
+
+	args := append([]string{"test", "-count=1", "-vet=off", "-run", "^(" + strings.Join(pattern, "|") + ")$"}, t.packages...)
+	cmd := exec.CommandContext(runCtx, "go", args...)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", nil, ctx.Err()
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return files.MutantTimeout, nil, nil
+	}
+	if err == nil {
+		return files.MutantSurvived, nil, nil
+	}
+
+	out := output.String()
+	if strings.Contains(out, "[build failed]") || strings.Contains(out, "[setup failed]") {
+		return files.MutantInvalid, nil, nil
+	}
+
+	var failed []string
+	for _, m := range failRe.FindAllStringSubmatch(out, -1) {
+		failed = append(failed, m[1])
+	}
+	return files.MutantKilled, failed, nil
+}
+
+// relativeTo strips a module directory prefix from a slash-separated path
+func relativeTo(moduleDir, relPath string) string {
+	if moduleDir == "." {
+		return relPath
+	}
+	return strings.TrimPrefix(relPath, moduleDir+"/")
+}
+
+// copyTree copies a module directory, leaving out paths hidden by the ignore
+// rules. relDir is the module directory relative to the base directory the
+// rules apply to. Go sources and the module's vendor/ directory are copied
+// regardless, since the build needs them.
+func copyTree(ignored *ignore.Matcher, src, relDir, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		slashRel := filepath.ToSlash(rel)
+		keep := slashRel == "vendor" || strings.HasPrefix(slashRel, "vendor/")
+
+		if d.IsDir() {
+			if p != src && !keep && ignored.Ignored(path.Join(relDir, slashRel), true) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+
+		if !keep && !isBuildInput(d.Name()) && ignored.Ignored(path.Join(relDir, slashRel), false) {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		return copyFile(p, target)
+	})
+}
+
+// isBuildInput reports whether go test reads a file of that name to build a
+// package
+func isBuildInput(name string) bool {
+	switch name {
+	case "go.mod", "go.sum", "go.work", "go.work.sum":
+		return true
+	}
+	return strings.HasSuffix(name, ".go")
+}
+
+// absoluteReplaces points the relative replace directives in the go.mod of a
+// module copy back at the directories they name next to the original module
+func absoluteReplaces(ctx context.Context, dir, moduleDir string) error {
+	cmd := exec.CommandContext(ctx, "go", "mod", "edit", "-json")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return err
+	}
+
+	var mod struct {
+		Replace []struct {
+			Old, New struct{ Path, Version string }
+		}
+	}
+	if err := json.Unmarshal(output, &mod); err != nil {
+		return err
+	}
+
+	args := []string{"mod", "edit"}
+	for _, replace := range mod.Replace {
+		// Local replacements start with ./ or ../ and carry no version
+		if replace.New.Version != "" || !(strings.HasPrefix(replace.New.Path, "./") || strings.HasPrefix(replace.New.Path, "../")) {
+			continue
+		}
+		old := replace.Old.Path
+		if replace.Old.Version != "" {
+			old += "@" + replace.Old.Version
+		}
+		args = append(args, "-replace="+old+"="+filepath.Join(moduleDir, filepath.FromSlash(replace.New.Path)))
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	cmd = exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// copyFile copies a regular file, keeping its permissions
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,125 @@
+package mutation
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMapsTo(t *testing.T) {
+	tests := []struct {
+		name string
+		test files.TestReference
+		fn   string
+		want bool
+	}{
+		{
+			name: "function name",
+			test: files.TestReference{FunctionName: "Parse"},
+			fn:   "Parse",
+			want: true,
+		},
+		{
+			name: "qualified method name",
+			test: files.TestReference{FunctionName: "Token.String"},
+			fn:   "Token.String",
+			want: true,
+		},
+		{
+			name: "method of another receiver",
+			test: files.TestReference{FunctionName: "Token.String"},
+			fn:   "Kind.String",
+		},
+		{
+			name: "bare method name",
+			test: files.TestReference{FunctionName: "String"},
+			fn:   "Kind.String",
+		},
+		{
+			name: "covered lines",
+			test: files.TestReference{FunctionName: "Lex", CoveredLines: files.LineRange{Start: 12, End: 14}},
+			fn:   "Kind.String",
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapsTo(tt.test, tt.fn, 10, 20); got != tt.want {
+				t.Fatalf("mapsTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	baseDir := t.TempDir()
+	sources := map[string]string{
+		".gitignore":             "*.log\n*_gen.go\ngen/\n",
+		"app/go.mod":             "module example.com/app\n\ngo 1.22\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+		"app/app.go":             "package app\n",
+		"app/types_gen.go":       "package app\n",
+		"app/debug.log":          "log\n",
+		"app/gen/gen.go":         "package gen\n",
+		"app/ignored.log.go":     "package app\n",
+		"app/vendor/modules.txt": "# vendor\n",
+		"app/.cache/data":        "cache\n",
+		"lib/go.mod":             "module example.com/lib\n\ngo 1.22\n",
+	}
+	writeFiles(t, baseDir, sources)
+
+	moduleDir := filepath.Join(baseDir, "app")
+	dst := t.TempDir()
+	if err := copyTree(ignore.For(baseDir), moduleDir, "app", dst); err != nil {
+		t.Fatalf("copyTree() error = %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"go.mod":             true,
+		"app.go":             true,
+		"types_gen.go":       true,
+		"vendor/modules.txt": true,
+		"debug.log":          false,
+		"gen/gen.go":         false,
+		".cache/data":        false,
+	} {
+		_, err := os.Stat(filepath.Join(dst, name))
+		if got := err == nil; got != want {
+			t.Fatalf("copied %s = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := absoluteReplaces(context.Background(), dst, moduleDir); err != nil {
+		t.Fatalf("absoluteReplaces() error = %v", err)
+	}
+	goMod, err := os.ReadFile(filepath.Join(dst, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "example.com/lib => " + filepath.Join(baseDir, "lib"); !strings.Contains(string(goMod), want) {
+		t.Fatalf("go.mod = %q, want it to contain %q", goMod, want)
+	}
+}
@@ -0,0 +1,199 @@
+package patch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// maxCells bounds the size of the edit table; larger changes are reported as
+// a single replacement of the differing region
+const maxCells = 4_000_000
+
+// op is one line of an edit script
+type op struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// Unified returns a unified diff that turns oldText into newText, or an empty
+// string when they are equal. An empty oldName marks a new file (/dev/null).
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	if oldName == "" {
+		b.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&b, "--- a/%s\n", oldName)
+	}
+	fmt.Fprintf(&b, "+++ b/%s\n", newName)
+
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk until the gap to the next change exceeds the context
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		from := max(start-contextLines, 0)
+		to := min(end+contextLines, len(ops))
+		writeHunk(&b, ops, from, to)
+		start = to
+	}
+
+	return b.String()
+}
+
+// writeHunk writes the hunk covering ops[from:to]
+func writeHunk(b *strings.Builder, ops []op, from, to int) {
+	oldStart, newStart := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			oldStart++
+		}
+		if o.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty side starts at the line before the hunk
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops[from:to] {
+		b.WriteByte(o.kind)
+		b.WriteString(o.text)
+		if !strings.HasSuffix(o.text, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of one side of a hunk
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits text into lines that keep their newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script from a to b, computed as a longest
+// common subsequence of the lines between the common prefix and suffix
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxCells {
+		for _, line := range midA {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, op{'+', line})
+		}
+	} else {
+		ops = append(ops, lcs(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// lcs returns the edit script of a longest common subsequence of a and b,
+// with deletions before insertions within each changed region
+func lcs(a, b []string) []op {
+	n, m := len(a), len(b)
+	// table[i][j] is the LCS length of a[i:] and b[j:]
+	table := make([][]int32, n+1)
+	for i := range table {
+		table[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case j == m || (i < n && table[i+1][j] >= table[i][j+1]):
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
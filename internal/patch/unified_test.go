@@ -0,0 +1,69 @@
+package patch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	numbered := func(from, to int) string {
+		var b strings.Builder
+		for i := from; i <= to; i++ {
+			b.WriteString("line")
+			b.WriteString(strings.Repeat("x", i))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name     string
+		oldName  string
+		old, new string
+		want     string
+	}{
+		{
+			name: "equal",
+			old:  "a\n", new: "a\n",
+			oldName: "f.go",
+			want:    "",
+		},
+		{
+			name:    "new file",
+			new:     "package x\n\nfunc A() {}\n",
+			want:    "--- /dev/null\n+++ b/f.go\n@@ -0,0 +1,3 @@\n+package x\n+\n+func A() {}\n",
+			oldName: "",
+		},
+		{
+			name:    "insertion with context",
+			oldName: "f.go",
+			old:     "a\nb\nc\nd\ne\n",
+			new:     "a\nb\nc\nX\nd\ne\n",
+			want:    "--- a/f.go\n+++ b/f.go\n@@ -1,5 +1,6 @@\n a\n b\n c\n+X\n d\n e\n",
+		},
+		{
+			name:    "separate hunks",
+			oldName: "f.go",
+			old:     numbered(1, 12),
+			new:     "first\n" + numbered(1, 12) + "last\n",
+			want: "--- a/f.go\n+++ b/f.go\n" +
+				"@@ -1,3 +1,4 @@\n+first\n linex\n linexx\n linexxx\n" +
+				"@@ -10,3 +11,4 @@\n linexxxxxxxxxx\n linexxxxxxxxxxx\n linexxxxxxxxxxxx\n+last\n",
+		},
+		{
+			name:    "missing final newline",
+			oldName: "f.go",
+			old:     "a\nb",
+			new:     "a\nb\nc\n",
+			want:    "--- a/f.go\n+++ b/f.go\n@@ -1,2 +1,3 @@\n a\n-b\n\\ No newline at end of file\n+b\n+c\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.oldName, "f.go", tt.old, tt.new); got != tt.want {
+				t.Fatalf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
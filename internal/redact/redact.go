@@ -0,0 +1,193 @@
+package redact
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+)
+
+// rule finds one kind of secret on a line. When the expression has a group
+// named "secret", only that group is replaced.
+type rule struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// rules are applied in order; the more specific token formats come first
+var rules = []rule{
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"stripe-key", regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}`)},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"api-key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"url-credentials", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@"'` + "`" + `]+:(?P<secret>[^\s@/"'` + "`" + `]+)@`)},
+	{"secret-assignment", regexp.MustCompile(`(?i)\b[\w.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)[\w.-]*["']?\s*` +
+		// quoted values, as in code
+		`(?:(?::=|=|:)\s*["'` + "`" + `](?P<secret>[^"'` + "`" + `\s]{8,})["'` + "`" + `]` +
+		// or unquoted values up to the end of the word, as in .env and YAML
+		// files; values with brackets or separators are taken for code
+		`|(?:=|:)\s*(?P<secret>[^\s"'` + "`" + `()\[\]{},;=][^\s"'` + "`" + `()\[\]{},;]{7,})(?:\s|$))`)},
+}
+
+// reference matches a value that names another value, like cfg.Password or
+// process.env.TOKEN, rather than being a secret
+var reference = regexp.MustCompile(`^[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)+$`)
+
+// privateKey matches a PEM private key block, also when its line breaks are
+// escaped inside a string literal. A block without its END line runs to the
+// end of the text.
+var privateKey = regexp.MustCompile(`(-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----)((?s:.*?))(-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)
+
+// candidate matches the quoted strings and assigned values checked for high
+// entropy
+var candidate = regexp.MustCompile(`["'` + "`" + `](?P<secret>[A-Za-z0-9+/_=-]{20,})["'` + "`" + `]|(?:=|:)\s*(?P<secret>[A-Za-z0-9+/_=-]{20,})\s*$`)
+
+// minEntropy is the Shannon entropy per character above which a candidate
+// string is taken for a random secret
+const minEntropy = 3.8
+
+// marker replaces a secret of a kind
+func marker(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// Text replaces the secrets in the content of a file with markers and
+// returns the redactions made, by line. Lines are neither added nor removed.
+func Text(path, text string) (string, []files.Redaction) {
+	var redactions []files.Redaction
+	report := func(line int, kind string) {
+		redactions = append(redactions, files.Redaction{Path: path, Line: line, Kind: kind})
+	}
+
+	// Private key blocks span lines: keep the armor lines and the line
+	// structure, and put a single marker in place of the key
+	text = replaceAllIndex(privateKey, text, func(m []int) string {
+		report(strings.Count(text[:m[0]], "\n")+1, "private-key")
+		header, body, footer := text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]
+		segments := strings.Split(body, "\n")
+		marked := false
+		for i, segment := range segments {
+			if marked || strings.TrimSpace(segment) == "" {
+				segments[i] = ""
+				continue
+			}
+			segments[i], marked = marker("private-key"), true
+		}
+		return header + strings.Join(segments, "\n") + footer
+	})
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for _, r := range rules {
+			line = replaceSecrets(r.re, line, func(string) bool { return true }, func() string {
+				report(i+1, r.kind)
+				return marker(r.kind)
+			})
+		}
+		line = replaceSecrets(candidate, line, highEntropy, func() string {
+			report(i+1, "high-entropy")
+			return marker("high-entropy")
+		})
+		lines[i] = line
+	}
+
+	sort.SliceStable(redactions, func(i, j int) bool { return redactions[i].Line < redactions[j].Line })
+	return strings.Join(lines, "\n"), redactions
+}
+
+// replaceAllIndex replaces every match of re with the result of repl, which
+// receives the submatch indexes of the match
+func replaceAllIndex(re *regexp.Regexp, s string, repl func([]int) string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		b.WriteString(repl(m))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// replaceSecrets replaces the secrets re finds on a line: its "secret"
+// group when it has one, the whole match otherwise. Secrets that are
+// placeholders or that accept rejects are kept.
+func replaceSecrets(re *regexp.Regexp, line string, accept func(string) bool, repl func() string) string {
+	names := re.SubexpNames()
+	return replaceAllIndex(re, line, func(m []int) string {
+		start, end := m[0], m[1]
+		for i, name := range names {
+			if name == "secret" && m[2*i] >= 0 {
+				start, end = m[2*i], m[2*i+1]
+				break
+			}
+		}
+		secret := line[start:end]
+		if placeholder(secret) || !accept(secret) {
+			return line[m[0]:m[1]]
+		}
+		return line[m[0]:start] + repl() + line[end:m[1]]
+	})
+}
+
+// placeholder reports whether a value stands for a secret rather than being
+// one: format verbs, template and shell variables, masks like "****" or
+// references to other values
+func placeholder(value string) bool {
+	switch {
+	case strings.HasPrefix(value, "%"), strings.HasPrefix(value, "$"), strings.HasPrefix(value, "{"), strings.HasPrefix(value, "<"):
+		return true
+	case strings.Trim(value, "*xX.") == "":
+		return true
+	case strings.Contains(value, "REDACTED"):
+		return true
+	case reference.MatchString(value):
+		return true
+	}
+	return false
+}
+
+// highEntropy reports whether a string looks randomly generated: letters
+// and digits mixed with a high entropy per character. Hex strings, which are
+// mostly hashes, are left alone.
+func highEntropy(s string) bool {
+	hasLetter, hasDigit, hex := false, false, true
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			hasLetter = true
+			if !(c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+				hex = false
+			}
+		default:
+			hex = false
+		}
+	}
+	return hasLetter && hasDigit && !hex && entropy(s) >= minEntropy
+}
+
+// entropy returns the Shannon entropy of a string in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+	n := float64(len(s))
+	var h float64
+	for _, count := range counts {
+		p := float64(count) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,222 @@
+package relocate
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+)
+
+// Status describes how a stored range relates to the current file content
+type Status int
+
+const (
+	// Fresh means the range still holds the fingerprinted content
+	Fresh Status = iota
+	// Moved means the unchanged content was found at another position
+	Moved
+	// Stale means the content changed; the range is a best-effort guess
+	Stale
+)
+
+// Source is the content of a file prepared for locating ranges in it: its
+// lines, the hash of each trimmed line and, for Go files, its functions
+type Source struct {
+	Lines     []string
+	Functions []analysis.Function
+	hashes    []uint64
+}
+
+// NewSource hashes every line of a file once
+func NewSource(lines []string, functions []analysis.Function) *Source {
+	src := &Source{Lines: lines, Functions: functions, hashes: make([]uint64, len(lines))}
+	for i, line := range lines {
+		src.hashes[i] = hashLine(line)
+	}
+	return src
+}
+
+// hashLine hashes a trimmed line. Its hex form is what fingerprints store for
+// single lines.
+func hashLine(line string) uint64 {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(line) + "\n"))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// rollingBase is the multiplier of the polynomial hash over line hashes
+const rollingBase = 1_000_003
+
+// windowHash combines the hashes of size lines from start (1-based). A single
+// line hashes to its own line hash.
+func (s *Source) windowHash(start, size int) uint64 {
+	var h uint64
+	for _, lh := range s.hashes[start-1 : start-1+size] {
+		h = h*rollingBase + lh
+	}
+	return h
+}
+
+// legacyHash hashes a range the way fingerprints recorded before line
+// hashes were combined did
+func legacyHash(lines []string) string {
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(strings.TrimSpace(line)))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// formatHash renders a hash as stored in a fingerprint
+func formatHash(h uint64) string {
+	return fmt.Sprintf("%016x", h)
+}
+
+// parseHash reads a hash stored in a fingerprint
+func parseHash(s string) (uint64, bool) {
+	h, err := strconv.ParseUint(s, 16, 64)
+	return h, err == nil
+}
+
+// validRange reports whether a range lies within a file of n lines
+func validRange(r files.LineRange, n int) bool {
+	return r.Start >= 1 && r.End >= r.Start && r.End <= n
+}
+
+// Fingerprint captures the content and surroundings of a range.
+// It returns nil when the range does not lie within the file.
+func Fingerprint(src *Source, r files.LineRange) *files.Fingerprint {
+	if !validRange(r, len(src.Lines)) {
+		return nil
+	}
+
+	fp := &files.Fingerprint{
+		Hash: formatHash(src.windowHash(r.Start, r.End-r.Start+1)),
+	}
+	if r.Start > 1 {
+		fp.Before = formatHash(src.hashes[r.Start-2])
+	}
+	if r.End < len(src.Lines) {
+		fp.After = formatHash(src.hashes[r.End])
+	}
+	if fn, ok := analysis.FunctionAt(src.Functions, r.Start); ok {
+		fp.Anchor = fn.QualifiedName()
+		fp.Offset = r.Start - fn.StartLine
+	}
+
+	return fp
+}
+
+// Locate finds the current position of a fingerprinted range: first by exact
+// content, then between its surrounding lines, then relative to its
+// enclosing function. Ranges that cannot be located keep their position.
+func Locate(src *Source, r files.LineRange, fp files.Fingerprint) (files.LineRange, Status) {
+	size := r.End - r.Start + 1
+	n := len(src.Lines)
+
+	if validRange(r, n) && (formatHash(src.windowHash(r.Start, size)) == fp.Hash || legacyHash(src.Lines[r.Start-1:r.End]) == fp.Hash) {
+		return r, Fresh
+	}
+
+	// Same content elsewhere in the file: roll the window hash over the
+	// file and pick the match nearest to the old position
+	best, bestDist := 0, -1
+	if want, ok := parseHash(fp.Hash); ok && size >= 1 && size <= n {
+		// top is the weight of the line leaving the window
+		top := uint64(1)
+		for i := 1; i < size; i++ {
+			top *= rollingBase
+		}
+
+		h := src.windowHash(1, size)
+		for start := 1; ; start++ {
+			if h == want {
+				if dist := abs(start - r.Start); bestDist < 0 || dist < bestDist {
+					best, bestDist = start, dist
+				}
+			}
+			if start+size > n {
+				break
+			}
+			h = (h-src.hashes[start-1]*top)*rollingBase + src.hashes[start+size-1]
+		}
+	}
+	if bestDist >= 0 {
+		return files.LineRange{Start: best, End: best + size - 1}, Moved
+	}
+
+	// Content changed but its neighbours survived: take the lines between them
+	if fp.Before != "" && fp.After != "" {
+		if located, ok := locateBetween(src, r, fp); ok {
+			return located, Stale
+		}
+	}
+
+	// Fall back to the enclosing function, keeping the relative offset
+	if fp.Anchor != "" {
+		for _, fn := range src.Functions {
+			if fn.QualifiedName() != fp.Anchor {
+				continue
+			}
+			start := fn.StartLine + fp.Offset
+			if start > fn.EndLine {
+				start = fn.StartLine
+			}
+			end := start + size - 1
+			if end > fn.EndLine {
+				end = fn.EndLine
+			}
+			return files.LineRange{Start: start, End: end}, Stale
+		}
+	}
+
+	return r, Stale
+}
+
+// locateBetween finds the nearest pair of lines matching the fingerprint's
+// neighbours that encloses a block of roughly the original size
+func locateBetween(src *Source, r files.LineRange, fp files.Fingerprint) (files.LineRange, bool) {
+	before, ok := parseHash(fp.Before)
+	if !ok {
+		return files.LineRange{}, false
+	}
+	after, ok := parseHash(fp.After)
+	if !ok {
+		return files.LineRange{}, false
+	}
+
+	size := r.End - r.Start + 1
+	maxSize := size*2 + 5
+	n := len(src.Lines)
+
+	best, bestDist := files.LineRange{}, -1
+	for i := 1; i <= n; i++ {
+		if src.hashes[i-1] != before {
+			continue
+		}
+		for j := i + 2; j <= n && j-i-1 <= maxSize; j++ {
+			if src.hashes[j-1] != after {
+				continue
+			}
+			if dist := abs(i + 1 - r.Start); bestDist < 0 || dist < bestDist {
+				best = files.LineRange{Start: i + 1, End: j - 1}
+				bestDist = dist
+			}
+			break
+		}
+	}
+
+	return best, bestDist >= 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
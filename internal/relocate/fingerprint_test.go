@@ -0,0 +1,133 @@
+package relocate
+
+import (
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+)
+
+const originalSource = `package sample
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`
+
+func parseSource(t *testing.T, src string) *Source {
+	t.Helper()
+	functions, err := analysis.ParseFunctions("sample.go", []byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return NewSource(strings.Split(src, "\n"), functions)
+}
+
+func TestLocate(t *testing.T) {
+	src := parseSource(t, originalSource)
+	subBody := files.LineRange{Start: 8, End: 8}
+	fp := Fingerprint(src, subBody)
+	if fp == nil {
+		t.Fatal("fingerprint is nil")
+	}
+
+	t.Run("keeps unchanged range", func(t *testing.T) {
+		located, status := Locate(src, subBody, *fp)
+		if status != Fresh || located != subBody {
+			t.Fatalf("located = %+v (%d), want %+v fresh", located, status, subBody)
+		}
+	})
+
+	t.Run("follows moved content", func(t *testing.T) {
+		edited := strings.Replace(originalSource, "package sample\n", "package sample\n\n// Add adds\n", 1)
+		src := parseSource(t, edited)
+
+		located, status := Locate(src, subBody, *fp)
+		want := files.LineRange{Start: 10, End: 10}
+		if status != Moved || located != want {
+			t.Fatalf("located = %+v (%d), want %+v moved", located, status, want)
+		}
+	})
+
+	t.Run("marks changed content stale", func(t *testing.T) {
+		edited := strings.Replace(originalSource, "return a - b", "return b - a", 1)
+		src := parseSource(t, edited)
+
+		located, status := Locate(src, subBody, *fp)
+		if status != Stale || located != subBody {
+			t.Fatalf("located = %+v (%d), want %+v stale", located, status, subBody)
+		}
+	})
+}
+
+func TestLocateMultiLine(t *testing.T) {
+	src := parseSource(t, originalSource)
+	sub := files.LineRange{Start: 7, End: 9}
+	fp := Fingerprint(src, sub)
+	if fp == nil {
+		t.Fatal("fingerprint is nil")
+	}
+
+	tests := []struct {
+		name       string
+		edit       func(string) string
+		wantRange  files.LineRange
+		wantStatus Status
+	}{
+		{
+			name:       "keeps unchanged range",
+			edit:       func(s string) string { return s },
+			wantRange:  sub,
+			wantStatus: Fresh,
+		},
+		{
+			name: "follows moved content",
+			edit: func(s string) string {
+				return strings.Replace(s, "package sample\n", "package sample\n\nconst x = 1\n", 1)
+			},
+			wantRange:  files.LineRange{Start: 9, End: 11},
+			wantStatus: Moved,
+		},
+		{
+			name: "picks the copy nearest to the old position",
+			edit: func(s string) string {
+				return strings.Replace(s, "func Sub(a, b int) int {\n\treturn a - b\n}\n", "func Sub(a, b int) int {\n\treturn a - b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n", 1)
+			},
+			wantRange:  sub,
+			wantStatus: Fresh,
+		},
+		{
+			name: "takes the lines between unchanged neighbours",
+			edit: func(s string) string {
+				return strings.Replace(s, "\treturn a - b\n", "\td := a - b\n\treturn d\n", 1)
+			},
+			wantRange:  files.LineRange{Start: 7, End: 10},
+			wantStatus: Stale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			located, status := Locate(parseSource(t, tt.edit(originalSource)), sub, *fp)
+			if located != tt.wantRange || status != tt.wantStatus {
+				t.Fatalf("Locate() = %+v (%d), want %+v (%d)", located, status, tt.wantRange, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLocateLegacyHash(t *testing.T) {
+	src := parseSource(t, originalSource)
+	sub := files.LineRange{Start: 7, End: 9}
+	fp := files.Fingerprint{Hash: legacyHash(src.Lines[6:9])}
+
+	located, status := Locate(src, sub, fp)
+	if status != Fresh || located != sub {
+		t.Fatalf("Locate() = %+v (%d), want %+v fresh", located, status, sub)
+	}
+}
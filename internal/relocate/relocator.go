@@ -0,0 +1,253 @@
+package relocate
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+)
+
+// Relocator fingerprints metadata ranges when they are submitted and moves
+// them to their current position when they are read. It caches file content
+// and should be used for a single request only.
+type Relocator struct {
+	fileService *files.Service
+	cache       map[string]*Source
+}
+
+// New creates a relocator reading files through the given service
+func New(fileService *files.Service) *Relocator {
+	return &Relocator{
+		fileService: fileService,
+		cache:       make(map[string]*Source),
+	}
+}
+
+// load returns the cached content of a file, with each line hashed once, or
+// nil if it cannot be read
+func (r *Relocator) load(path string) *Source {
+	if src, ok := r.cache[path]; ok {
+		return src
+	}
+
+	var src *Source
+	if content, err := r.fileService.ReadFile(path); err == nil {
+		var functions []analysis.Function
+		if strings.HasSuffix(path, ".go") {
+			functions, _ = analysis.ParseFunctions(path, []byte(content.Content))
+		}
+		src = NewSource(strings.Split(content.Content, "\n"), functions)
+	}
+
+	r.cache[path] = src
+	return src
+}
+
+// SourceStamp identifies the current content of a file by its size and
+// modification time, or returns "" when it cannot be read
+func (r *Relocator) SourceStamp(path string) string {
+	fullPath, err := r.fileService.Resolve(path)
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// rangeRef points at a range of a test reference and the file it refers to
+type rangeRef struct {
+	file string
+	r    *files.LineRange
+}
+
+// testRanges lists the ranges of a test reference keyed by field name
+func testRanges(sourceFile string, test *files.TestReference) map[string]rangeRef {
+	return map[string]rangeRef{
+		"lineRange":    {test.TestFile, &test.LineRange},
+		"coveredLines": {sourceFile, &test.CoveredLines},
+		"inputLines":   {test.TestFile, &test.InputLines},
+		"outputLines":  {test.TestFile, &test.OutputLines},
+	}
+}
+
+// FingerprintTest records the current content of every range of a test reference
+func (r *Relocator) FingerprintTest(sourceFile string, test files.TestReference) files.TestReference {
+	test.Fingerprints = make(map[string]files.Fingerprint)
+
+	for field, ref := range testRanges(sourceFile, &test) {
+		src := r.load(ref.file)
+		if src == nil {
+			continue
+		}
+		if fp := Fingerprint(src, *ref.r); fp != nil {
+			test.Fingerprints[field] = *fp
+		}
+	}
+
+	if len(test.Fingerprints) == 0 {
+		test.Fingerprints = nil
+	}
+
+	return test
+}
+
+// ResolveTest returns a copy of a test reference with its ranges moved to
+// their current position. The copy is marked stale, with a hint for the
+// agent, when the referenced code changed since it was fingerprinted.
+func (r *Relocator) ResolveTest(sourceFile string, test files.TestReference) files.TestReference {
+	if len(test.Fingerprints) == 0 {
+		return test
+	}
+
+	original := test.LineRange
+	var staleFields []string
+
+	for field, ref := range testRanges(sourceFile, &test) {
+		fp, ok := test.Fingerprints[field]
+		if !ok {
+			continue
+		}
+
+		src := r.load(ref.file)
+		if src == nil {
+			staleFields = append(staleFields, field)
+			continue
+		}
+
+		located, status := Locate(src, *ref.r, fp)
+		if field == "coveredLines" && status == Moved {
+			test.CoveredLineSet = shiftLines(test.CoveredLineSet, located.Start-ref.r.Start)
+		}
+		*ref.r = located
+		if status == Stale {
+			staleFields = append(staleFields, field)
+		}
+	}
+
+	// Cases and fixture references live inside the test body and move along
+	// with it
+	if delta := test.LineRange.Start - original.Start; delta != 0 && len(test.Cases) > 0 {
+		cases := make([]files.TestCase, len(test.Cases))
+		for i, tc := range test.Cases {
+			tc.LineRange = shiftRange(tc.LineRange, delta)
+			tc.InputLines = shiftRange(tc.InputLines, delta)
+			tc.OutputLines = shiftRange(tc.OutputLines, delta)
+			cases[i] = tc
+		}
+		test.Cases = cases
+	}
+	if delta := test.LineRange.Start - original.Start; delta != 0 && len(test.Fixtures) > 0 {
+		fixtures := make([]files.FixtureLink, len(test.Fixtures))
+		for i, fixture := range test.Fixtures {
+			fixture.Line += delta
+			fixtures[i] = fixture
+		}
+		test.Fixtures = fixtures
+	}
+
+	if len(staleFields) > 0 {
+		sort.Strings(staleFields)
+		test.Stale = true
+		test.StaleHint = fmt.Sprintf("%s changed since the metadata was submitted; re-run the codebase-tests-review prompt for %s in %s",
+			strings.Join(staleFields, ", "), test.FunctionName, sourceFile)
+	}
+
+	return test
+}
+
+// FingerprintComment records the content of the commented line
+func (r *Relocator) FingerprintComment(sourceFile string, comment files.Comment) files.Comment {
+	if src := r.load(sourceFile); src != nil {
+		comment.Fingerprint = Fingerprint(src, files.LineRange{Start: comment.Line, End: comment.Line})
+	}
+	return comment
+}
+
+// ResolveComment returns a copy of a comment moved to the current position
+// of its line, marked stale when the line itself changed
+func (r *Relocator) ResolveComment(sourceFile string, comment files.Comment) files.Comment {
+	if comment.Fingerprint == nil {
+		return comment
+	}
+
+	src := r.load(sourceFile)
+	if src == nil {
+		comment.Stale = true
+		return comment
+	}
+
+	located, status := Locate(src, files.LineRange{Start: comment.Line, End: comment.Line}, *comment.Fingerprint)
+	delta := located.Start - comment.Line
+	comment.Line = located.Start
+	comment.ContextLines = shiftRange(comment.ContextLines, delta)
+	comment.Stale = status == Stale
+
+	return comment
+}
+
+// ResolveMetadata returns a copy of file metadata with every test reference
+// and comment resolved against the current file content
+func (r *Relocator) ResolveMetadata(sourceFile string, meta *files.FileMetadata) *files.FileMetadata {
+	if meta == nil {
+		return nil
+	}
+
+	resolved := *meta
+	resolved.Tests = r.ResolveTests(sourceFile, meta.Tests)
+	resolved.Comments = r.ResolveComments(sourceFile, meta.Comments)
+
+	return &resolved
+}
+
+// ResolveTests resolves a list of test references
+func (r *Relocator) ResolveTests(sourceFile string, tests []files.TestReference) []files.TestReference {
+	if tests == nil {
+		return nil
+	}
+
+	resolved := make([]files.TestReference, len(tests))
+	for i, test := range tests {
+		resolved[i] = r.ResolveTest(sourceFile, test)
+	}
+	return resolved
+}
+
+// ResolveComments resolves a list of comments
+func (r *Relocator) ResolveComments(sourceFile string, comments []files.Comment) []files.Comment {
+	if comments == nil {
+		return nil
+	}
+
+	resolved := make([]files.Comment, len(comments))
+	for i, comment := range comments {
+		resolved[i] = r.ResolveComment(sourceFile, comment)
+	}
+	return resolved
+}
+
+// shiftRange moves a non-empty range by delta lines
+func shiftRange(r files.LineRange, delta int) files.LineRange {
+	if r.Start == 0 && r.End == 0 {
+		return r
+	}
+	return files.LineRange{Start: r.Start + delta, End: r.End + delta}
+}
+
+// shiftLines moves a set of line numbers by delta lines
+func shiftLines(lines []int, delta int) []int {
+	if delta == 0 || lines == nil {
+		return lines
+	}
+
+	shifted := make([]int, len(lines))
+	for i, line := range lines {
+		shifted[i] = line + delta
+	}
+	return shifted
+}
@@ -0,0 +1,102 @@
+package relocate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+const originalTest = `package sample
+
+import "testing"
+
+func TestSub(t *testing.T) {
+	tests := []struct{ a, b, want int }{
+		{a: 2, b: 1, want: 1},
+	}
+	for _, tt := range tests {
+		if got := Sub(tt.a, tt.b); got != tt.want {
+			t.Fatalf("Sub() = %d, want %d", got, tt.want)
+		}
+	}
+	os.ReadFile("testdata/sub.txt")
+}
+`
+
+func TestRelocatorResolve(t *testing.T) {
+	baseDir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(baseDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("sample.go", originalSource)
+	write("sample_test.go", originalTest)
+
+	service := files.NewService(baseDir)
+	test := New(service).FingerprintTest("sample.go", files.TestReference{
+		FunctionName: "Sub",
+		TestFile:     "sample_test.go",
+		TestName:     "TestSub",
+		LineRange:    files.LineRange{Start: 5, End: 15},
+		CoveredLines: files.LineRange{Start: 8, End: 8},
+		Cases: []files.TestCase{{
+			Name:        "a=2",
+			LineRange:   files.LineRange{Start: 7, End: 7},
+			InputLines:  files.LineRange{Start: 7, End: 7},
+			OutputLines: files.LineRange{Start: 7, End: 7},
+		}},
+		Fixtures: []files.FixtureLink{{Path: "testdata/sub.txt", Line: 14}},
+	})
+	comment := New(service).FingerprintComment("sample.go", files.Comment{
+		ID:           "c1",
+		Line:         8,
+		ContextLines: files.LineRange{Start: 7, End: 9},
+	})
+
+	// Two lines added above the test and three above the commented line
+	write("sample_test.go", strings.Replace(originalTest, "import \"testing\"\n", "import \"testing\"\n\n// TestSub checks Sub\n", 1))
+	write("sample.go", strings.Replace(originalSource, "package sample\n", "package sample\n\n// Add adds\n// a and b\n", 1))
+
+	relocator := New(service)
+
+	t.Run("test", func(t *testing.T) {
+		got := relocator.ResolveTest("sample.go", test)
+		if got.Stale {
+			t.Fatalf("ResolveTest() stale: %s", got.StaleHint)
+		}
+		if want := (files.LineRange{Start: 7, End: 17}); got.LineRange != want {
+			t.Fatalf("LineRange = %+v, want %+v", got.LineRange, want)
+		}
+		if want := (files.LineRange{Start: 11, End: 11}); got.CoveredLines != want {
+			t.Fatalf("CoveredLines = %+v, want %+v", got.CoveredLines, want)
+		}
+		wantCase := files.LineRange{Start: 9, End: 9}
+		if tc := got.Cases[0]; tc.LineRange != wantCase || tc.InputLines != wantCase || tc.OutputLines != wantCase {
+			t.Fatalf("Cases[0] = %+v, want ranges %+v", tc, wantCase)
+		}
+		if got.Fixtures[0].Line != 16 {
+			t.Fatalf("Fixtures[0].Line = %d, want 16", got.Fixtures[0].Line)
+		}
+		if test.Cases[0].LineRange.Start != 7 || test.Fixtures[0].Line != 14 {
+			t.Fatal("ResolveTest() modified the stored reference")
+		}
+	})
+
+	t.Run("comment", func(t *testing.T) {
+		got := relocator.ResolveComment("sample.go", comment)
+		if got.Stale {
+			t.Fatal("ResolveComment() stale")
+		}
+		if got.Line != 11 {
+			t.Fatalf("Line = %d, want 11", got.Line)
+		}
+		if want := (files.LineRange{Start: 10, End: 12}); got.ContextLines != want {
+			t.Fatalf("ContextLines = %+v, want %+v", got.ContextLines, want)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package rollup
+
+import (
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/coverage"
+	"codebase-view-mcp/internal/discovery"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// Cache aggregates the metadata store into per-file and per-directory coverage
+// summaries. A file summary is recomputed only when the file's metadata or
+// source changes; directory totals only when a file summary changed.
+type Cache struct {
+	store   *metadata.Store
+	baseDir string
+
+	mu        sync.Mutex
+	loaded    bool
+	version   uint64 // store version the summaries reflect
+	files     map[string]fileSummary
+	summaries map[string]files.CoverageSummary
+	hidden    map[string]bool // ignored files left out of the summaries
+}
+
+// fileSummary is the cached summary of one file and the source it was computed from
+type fileSummary struct {
+	summary files.CoverageSummary
+	modTime time.Time
+	size    int64
+}
+
+// New creates a rollup cache over the metadata store
+func New(store *metadata.Store, baseDir string) *Cache {
+	return &Cache{
+		store:   store,
+		baseDir: baseDir,
+		files:   make(map[string]fileSummary),
+	}
+}
+
+// Summaries returns the summary of every file with metadata and of every
+// directory containing one, keyed by slash-separated path relative to the base
+// directory ("." for the base directory itself). The map must not be modified.
+func (c *Cache) Summaries() map[string]files.CoverageSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	version := c.store.Version()
+	all := c.store.GetAllMetadata()
+
+	dirty := make(map[string]bool)
+	if !c.loaded {
+		for filePath := range all {
+			dirty[filePath] = true
+		}
+	} else {
+		for _, filePath := range c.store.ModifiedSince(c.version) {
+			dirty[filePath] = true
+		}
+	}
+
+	// Source edits change function boundaries without touching the store
+	stats := make(map[string]os.FileInfo, len(all))
+	for filePath := range all {
+		info, _ := os.Stat(filepath.Join(c.baseDir, filePath))
+		stats[filePath] = info
+
+		cached, ok := c.files[filePath]
+		if !ok || (info != nil && (!info.ModTime().Equal(cached.modTime) || info.Size() != cached.size)) {
+			dirty[filePath] = true
+		}
+	}
+
+	// Ignored files are left out of the totals; the ignore rules can change
+	// without the store or the sources changing
+	ignored := ignore.For(c.baseDir)
+	hidden := make(map[string]bool)
+	for filePath := range all {
+		if ignored.Ignored(filePath, false) {
+			hidden[filePath] = true
+		}
+	}
+
+	if c.loaded && len(dirty) == 0 && maps.Equal(hidden, c.hidden) {
+		return c.summaries
+	}
+
+	for filePath := range dirty {
+		meta, ok := all[filePath]
+		if !ok {
+			delete(c.files, filePath)
+			continue
+		}
+
+		entry := fileSummary{summary: c.summarize(filePath, meta)}
+		if info := stats[filePath]; info != nil {
+			entry.modTime, entry.size = info.ModTime(), info.Size()
+		}
+		c.files[filePath] = entry
+	}
+
+	summaries := make(map[string]files.CoverageSummary, len(c.files)*2)
+	for filePath, entry := range c.files {
+		if hidden[filePath] {
+			continue
+		}
+		summaries[filePath] = entry.summary
+
+		for dir := path.Dir(filePath); ; dir = path.Dir(dir) {
+			total := summaries[dir]
+			add(&total, entry.summary)
+			summaries[dir] = total
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+	}
+
+	c.summaries = summaries
+	c.hidden = hidden
+	c.version = version
+	c.loaded = true
+
+	return summaries
+}
+
+// span is a function's line range
+type span struct {
+	name       string
+	start, end int
+}
+
+// summarize computes the summary of a single file
+func (c *Cache) summarize(filePath string, meta *files.FileMetadata) files.CoverageSummary {
+	var summary files.CoverageSummary
+	if meta == nil {
+		return summary
+	}
+
+	if len(meta.Tests) > 0 || len(meta.Suggestions) > 0 || len(meta.Comments) > 0 ||
+		meta.Coverage != nil || len(meta.TestResults) > 0 || len(meta.Mutations) > 0 {
+		summary.FilesWithMetadata = 1
+	}
+
+	summary.OpenSuggestions = len(meta.Suggestions)
+	for _, comment := range meta.Comments {
+		if !comment.Resolved {
+			summary.UnresolvedComments++
+		}
+	}
+
+	real := coverage.Lines(meta.Coverage)
+	if real != nil {
+		summary.RealCoveredLines = len(real.CoveredLines)
+		summary.RealExecutableLines = len(real.CoveredLines) + len(real.UncoveredLines)
+	}
+
+	declared := make(map[int]bool)
+	named := make(map[string]bool)
+	for _, test := range meta.Tests {
+		named[test.FunctionName] = true
+		for _, line := range metadata.CoveredLines(test) {
+			declared[line] = true
+		}
+	}
+
+	functionLines := make(map[int]bool)
+	for _, fn := range c.functions(filePath) {
+		tested := named[fn.name] || named[fn.name[strings.LastIndex(fn.name, ".")+1:]]
+		for line := fn.start; line <= fn.end; line++ {
+			functionLines[line] = true
+			if declared[line] || (real != nil && real.Hits[line] > 0) {
+				tested = true
+			}
+		}
+
+		if tested {
+			summary.TestedFunctions++
+		} else {
+			summary.UntestedFunctions++
+		}
+	}
+
+	summary.FunctionLines = len(functionLines)
+	for line := range functionLines {
+		if declared[line] {
+			summary.DeclaredLines++
+		}
+	}
+
+	setPercentages(&summary)
+	return summary
+}
+
+// functions returns the functions declared in a source file. Test files
+// and files no parser understands have none.
+func (c *Cache) functions(filePath string) []span {
+	if strings.HasSuffix(filePath, "_test.go") {
+		return nil
+	}
+
+	analyzer, isDiscoverable := discovery.ForFile(filePath)
+	if !strings.HasSuffix(filePath, ".go") && (!isDiscoverable || analyzer.IsTestFile(filePath)) {
+		return nil
+	}
+
+	src, err := os.ReadFile(filepath.Join(c.baseDir, filePath))
+	if err != nil {
+		return nil
+	}
+
+	var spans []span
+	if strings.HasSuffix(filePath, ".go") {
+		functions, err := analysis.ParseFunctions(filePath, src)
+		if err != nil {
+			return nil
+		}
+		for _, fn := range functions {
+			spans = append(spans, span{name: fn.QualifiedName(), start: fn.StartLine, end: fn.EndLine})
+		}
+		return spans
+	}
+
+	for _, fn := range analyzer.ParseFunctions(src) {
+		spans = append(spans, span{name: fn.Name, start: fn.StartLine, end: fn.EndLine})
+	}
+	return spans
+}
+
+// add accumulates the counts of src into dst
+func add(dst *files.CoverageSummary, src files.CoverageSummary) {
+	dst.FilesWithMetadata += src.FilesWithMetadata
+	dst.TestedFunctions += src.TestedFunctions
+	dst.UntestedFunctions += src.UntestedFunctions
+	dst.FunctionLines += src.FunctionLines
+	dst.DeclaredLines += src.DeclaredLines
+	dst.RealCoveredLines += src.RealCoveredLines
+	dst.RealExecutableLines += src.RealExecutableLines
+	dst.OpenSuggestions += src.OpenSuggestions
+	dst.UnresolvedComments += src.UnresolvedComments
+	setPercentages(dst)
+}
+
+// setPercentages derives the percentages of a summary from its line counts
+func setPercentages(s *files.CoverageSummary) {
+	s.DeclaredPercent = 0
+	if s.FunctionLines > 0 {
+		s.DeclaredPercent = 100 * float64(s.DeclaredLines) / float64(s.FunctionLines)
+	}
+	s.RealPercent = nil
+	if s.RealExecutableLines > 0 {
+		percent := 100 * float64(s.RealCoveredLines) / float64(s.RealExecutableLines)
+		s.RealPercent = &percent
+	}
+}
@@ -0,0 +1,93 @@
+package rollup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+)
+
+const rollupSource = `package calc
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`
+
+func TestSummaries(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg", "calc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sourcePath := filepath.Join(dir, "pkg", "calc", "calc.go")
+	if err := os.WriteFile(sourcePath, []byte(rollupSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := metadata.NewStore("")
+	store.AddTestMetadata("pkg/calc/calc.go", []files.TestReference{{
+		FunctionName: "Add",
+		TestFile:     "pkg/calc/calc_test.go",
+		TestName:     "TestAdd",
+		CoveredLines: files.LineRange{Start: 3, End: 5},
+	}})
+	store.AddComment("pkg/calc/calc.go", files.Comment{Line: 7, Content: "check overflow"})
+
+	cache := New(store, dir)
+	summaries := cache.Summaries()
+
+	t.Run("file", func(t *testing.T) {
+		got := summaries["pkg/calc/calc.go"]
+		if got.TestedFunctions != 1 || got.UntestedFunctions != 1 {
+			t.Fatalf("functions = %d tested, %d untested, want 1 and 1", got.TestedFunctions, got.UntestedFunctions)
+		}
+		if got.FunctionLines != 6 || got.DeclaredLines != 3 || got.DeclaredPercent != 50 {
+			t.Fatalf("lines = %d/%d (%v%%), want 3/6 (50%%)", got.DeclaredLines, got.FunctionLines, got.DeclaredPercent)
+		}
+		if got.UnresolvedComments != 1 || got.RealPercent != nil {
+			t.Fatalf("summary = %+v, want 1 unresolved comment and no real coverage", got)
+		}
+	})
+
+	t.Run("directories", func(t *testing.T) {
+		for _, d := range []string{"pkg/calc", "pkg", "."} {
+			if got := summaries[d]; got.FilesWithMetadata != 1 || got.TestedFunctions != 1 {
+				t.Fatalf("summaries[%q] = %+v, want the file's counts", d, got)
+			}
+		}
+	})
+
+	t.Run("store changes", func(t *testing.T) {
+		store.ImportCoverage(map[string]*files.FileCoverage{
+			"pkg/calc/calc.go": {Mode: "set", Blocks: []files.CoverageBlock{
+				{StartLine: 3, EndLine: 5, NumStmts: 1, Count: 1},
+				{StartLine: 7, EndLine: 9, NumStmts: 1, Count: 1},
+			}},
+		})
+
+		got := cache.Summaries()["pkg"]
+		if got.TestedFunctions != 2 || got.RealPercent == nil || *got.RealPercent != 100 {
+			t.Fatalf("summary = %+v, want both functions tested at 100%% real coverage", got)
+		}
+	})
+
+	t.Run("source changes", func(t *testing.T) {
+		extended := rollupSource + "\nfunc Mul(a, b int) int {\n\treturn a * b\n}\n"
+		if err := os.WriteFile(sourcePath, []byte(extended), 0644); err != nil {
+			t.Fatal(err)
+		}
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(sourcePath, later, later)
+
+		if got := cache.Summaries()["pkg/calc/calc.go"]; got.UntestedFunctions != 1 {
+			t.Fatalf("UntestedFunctions = %d, want 1 after adding Mul", got.UntestedFunctions)
+		}
+	})
+}
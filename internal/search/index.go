@@ -0,0 +1,232 @@
+package search
+
+import (
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// maxFileSize is the size above which files are not indexed
+const maxFileSize = 1 << 20
+
+// refreshInterval is how long the index is trusted before the file tree is
+// checked for changes again
+const refreshInterval = 2 * time.Second
+
+// trigram is three consecutive bytes of lowercased content
+type trigram uint32
+
+// document is an indexed file
+type document struct {
+	path    string // slash-separated, relative to the base directory
+	content []byte
+	modTime time.Time
+	size    int64
+}
+
+// Index is an in-memory trigram index of the text files under a base
+// directory. Every posting list holds the ids of the documents containing a
+// trigram in increasing order. Changed files get a new id; the ids of
+// removed and replaced documents are dropped lazily and compacted away once
+// they make up half of the index.
+type Index struct {
+	baseDir string
+
+	mu        sync.RWMutex
+	docs      []*document // by id; nil for removed documents
+	byPath    map[string]int
+	postings  map[trigram][]int
+	removed   int
+	refreshed time.Time
+	stale     bool // a change was reported since the last refresh
+}
+
+// New creates an empty index of baseDir; it is filled by the first search
+func New(baseDir string) *Index {
+	return &Index{
+		baseDir:  baseDir,
+		byPath:   make(map[string]int),
+		postings: make(map[trigram][]int),
+	}
+}
+
+// Invalidate makes the next search check the file tree for changes without
+// waiting for the refresh interval
+func (x *Index) Invalidate() {
+	x.mu.Lock()
+	x.stale = true
+	x.mu.Unlock()
+}
+
+// refresh brings the index in sync with the file tree when it is due
+func (x *Index) refresh() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if !x.stale && !x.refreshed.IsZero() && time.Since(x.refreshed) < refreshInterval {
+		return
+	}
+
+	seen := make(map[string]bool, len(x.byPath))
+	ignore.WalkFiles(x.baseDir, func(rel, p string, info fs.FileInfo) {
+		if info.Size() > maxFileSize {
+			return
+		}
+		seen[rel] = true
+
+		if id, ok := x.byPath[rel]; ok {
+			doc := x.docs[id]
+			if doc.modTime.Equal(info.ModTime()) && doc.size == info.Size() {
+				return
+			}
+			x.remove(rel)
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil || files.IsBinary(content) {
+			return
+		}
+		x.add(&document{path: rel, content: content, modTime: info.ModTime(), size: info.Size()})
+	})
+
+	for rel := range x.byPath {
+		if !seen[rel] {
+			x.remove(rel)
+		}
+	}
+
+	if x.removed > 0 && x.removed*2 >= len(x.docs) {
+		x.compact()
+	}
+
+	x.refreshed = time.Now()
+	x.stale = false
+}
+
+// add indexes a document under a new id
+func (x *Index) add(doc *document) {
+	id := len(x.docs)
+	x.docs = append(x.docs, doc)
+	x.byPath[doc.path] = id
+	for t := range trigrams(doc.content) {
+		x.postings[t] = append(x.postings[t], id)
+	}
+}
+
+// remove drops a document; its id stays in the posting lists until compaction
+func (x *Index) remove(path string) {
+	id, ok := x.byPath[path]
+	if !ok {
+		return
+	}
+	x.docs[id] = nil
+	delete(x.byPath, path)
+	x.removed++
+}
+
+// compact rebuilds the index from its live documents
+func (x *Index) compact() {
+	docs := x.docs
+	x.docs = nil
+	x.byPath = make(map[string]int, len(docs)-x.removed)
+	x.postings = make(map[trigram][]int, len(x.postings))
+	x.removed = 0
+	for _, doc := range docs {
+		if doc != nil {
+			x.add(doc)
+		}
+	}
+}
+
+// candidates returns the live documents containing every trigram of every
+// literal, sorted by path. Literals shorter than a trigram do not narrow the
+// search.
+func (x *Index) candidates(literals []string) []*document {
+	var ids []int
+	narrowed := false
+	for _, literal := range literals {
+		for _, t := range queryTrigrams(literal) {
+			list := x.postings[t]
+			if !narrowed {
+				ids = append([]int(nil), list...)
+				narrowed = true
+			} else {
+				ids = intersect(ids, list)
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+		}
+	}
+
+	var docs []*document
+	if narrowed {
+		for _, id := range ids {
+			if doc := x.docs[id]; doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	} else {
+		for _, doc := range x.docs {
+			if doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].path < docs[j].path })
+	return docs
+}
+
+// trigrams returns the set of trigrams of lowercased content
+func trigrams(content []byte) map[trigram]struct{} {
+	set := make(map[trigram]struct{})
+	var window trigram
+	for i, c := range content {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		window = (window<<8 | trigram(c)) & 0xFFFFFF
+		if i >= 2 {
+			set[window] = struct{}{}
+		}
+	}
+	return set
+}
+
+// queryTrigrams returns the trigrams of a literal that can be looked up
+// regardless of case. Windows with non-ASCII bytes are skipped, since the
+// index only folds ASCII letters.
+func queryTrigrams(literal string) []trigram {
+	var out []trigram
+	for t := range trigrams([]byte(literal)) {
+		if t&0x808080 == 0 {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// intersect returns the ids present in both sorted lists
+func intersect(a, b []int) []int {
+	out := a[:0]
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
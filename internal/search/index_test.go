@@ -0,0 +1,142 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("calc/calc.go", "package calc\n\n// Add returns a + b\nfunc Add(a, b int) int {\n\treturn a + b\n}\n")
+	write("calc/calc_test.go", "package calc\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 3 {\n\t\tt.Fatal(\"add\")\n\t}\n}\n")
+	write("README.md", "héllo Wörld\n")
+	write("bin/data", "func Add\x00")
+	write("node_modules/lib/index.js", "function Add() {}\n")
+
+	x := New(dir)
+
+	type hit struct {
+		Path         string
+		Line, Column int
+	}
+	hits := func(t *testing.T, q Query) []hit {
+		t.Helper()
+		response, err := x.Search(q)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", q, err)
+		}
+		var got []hit
+		for _, m := range response.Matches {
+			got = append(got, hit{m.Path, m.Line, m.Column})
+		}
+		return got
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want []hit
+	}{
+		{
+			name: "literal ignores case by default",
+			q:    Query{Pattern: "func ADD"},
+			want: []hit{{"calc/calc.go", 4, 1}},
+		},
+		{
+			name: "case sensitive",
+			q:    Query{Pattern: "add", CaseSensitive: true},
+			want: []hit{{"calc/calc_test.go", 5, 12}},
+		},
+		{
+			name: "regex",
+			q:    Query{Pattern: `Add\(\d, \d\)`, Regex: true},
+			want: []hit{{"calc/calc_test.go", 4, 5}},
+		},
+		{
+			name: "path glob",
+			q:    Query{Pattern: "Add", Path: "*_test.go", Limit: 1},
+			want: []hit{{"calc/calc_test.go", 3, 10}},
+		},
+		{
+			name: "columns count characters",
+			q:    Query{Pattern: "wörld"},
+			want: []hit{{"README.md", 1, 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hits(t, tt.q); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Search() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("context and limit", func(t *testing.T) {
+		response, err := x.Search(Query{Pattern: "\treturn", Context: 1, Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := response.Matches[0]
+		if !reflect.DeepEqual(m.Before, []string{"func Add(a, b int) int {"}) || !reflect.DeepEqual(m.After, []string{"}"}) || m.Length != 7 {
+			t.Fatalf("match = %+v", m)
+		}
+		if response.Truncated {
+			t.Fatalf("Truncated = true with a single match")
+		}
+	})
+
+	t.Run("follows file changes", func(t *testing.T) {
+		write("calc/sub.go", "package calc\n\nfunc Subtract(a, b int) int { return a - b }\n")
+		os.Remove(filepath.Join(dir, "calc", "calc.go"))
+		// Make sure the modification time differs from the indexed one
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(filepath.Join(dir, "calc", "sub.go"), later, later)
+		x.Invalidate()
+
+		if got, want := hits(t, Query{Pattern: "int) int"}), []hit{{"calc/sub.go", 3, 20}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Search() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("invalid regex", func(t *testing.T) {
+		if _, err := x.Search(Query{Pattern: "(", Regex: true}); err == nil {
+			t.Fatal("Search() error = nil, want an invalid expression error")
+		}
+	})
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`foo\.bar`, []string{"foo.bar"}},
+		{`func (\w+)Handler\(`, []string{"func ", "Handler("}},
+		{`a|b`, nil},
+		{`(abc)+x?`, []string{"abc"}},
+	}
+
+	for _, tt := range tests {
+		got, err := requiredLiterals(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("requiredLiterals(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package search
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// Limits of a search
+const (
+	DefaultLimit   = 100
+	MaxLimit       = 1000
+	DefaultContext = 2
+	MaxContext     = 10
+)
+
+// Query is a search request
+type Query struct {
+	Pattern       string
+	Regex         bool   // Pattern is a regular expression (RE2 syntax)
+	CaseSensitive bool   // otherwise letters match regardless of case
+	Path          string // optional glob the file path must match (gitignore syntax)
+	Limit         int    // maximum number of matches
+	Context       int    // lines returned before and after each match
+}
+
+// Search finds the lines matching a query, at most one match per line,
+// ordered by path and line
+func (x *Index) Search(q Query) (*files.SearchResponse, error) {
+	if q.Pattern == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	q.Limit = min(q.Limit, MaxLimit)
+	q.Context = max(min(q.Context, MaxContext), 0)
+
+	expr := q.Pattern
+	if !q.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !q.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+
+	var glob *ignore.Glob
+	if q.Path != "" {
+		if glob, err = ignore.NewGlob(q.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	literals := []string{q.Pattern}
+	if q.Regex {
+		literals, err = requiredLiterals(q.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	x.refresh()
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	response := &files.SearchResponse{Matches: []files.SearchMatch{}}
+	for _, doc := range x.candidates(literals) {
+		if glob != nil && !glob.Match(doc.path) {
+			continue
+		}
+		response.FilesSearched++
+
+		if !re.Match(doc.content) {
+			continue
+		}
+
+		lines := splitLines(doc.content)
+		for i, line := range lines {
+			loc := re.FindIndex(line)
+			if loc == nil {
+				continue
+			}
+			if len(response.Matches) == q.Limit {
+				response.Truncated = true
+				return response, nil
+			}
+
+			response.Matches = append(response.Matches, files.SearchMatch{
+				Path:   doc.path,
+				Line:   i + 1,
+				Column: utf8.RuneCount(line[:loc[0]]) + 1,
+				Length: utf8.RuneCount(line[loc[0]:loc[1]]),
+				Text:   string(line),
+				Before: contextLines(lines, i-q.Context, i),
+				After:  contextLines(lines, i+1, i+1+q.Context),
+			})
+		}
+	}
+	return response, nil
+}
+
+// requiredLiterals returns literal strings every match of a regular
+// expression contains, to narrow the search through the index
+func requiredLiterals(expr string) ([]string, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	return literalsOf(re.Simplify()), nil
+}
+
+// literalsOf collects the required literals of a parsed expression
+func literalsOf(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return literalsOf(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return literalsOf(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		// Adjacent literals form one longer literal
+		var literals []string
+		var run strings.Builder
+		flush := func() {
+			if run.Len() > 0 {
+				literals = append(literals, run.String())
+				run.Reset()
+			}
+		}
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(string(sub.Rune))
+				continue
+			}
+			flush()
+			literals = append(literals, literalsOf(sub)...)
+		}
+		flush()
+		return literals
+	}
+	return nil
+}
+
+// splitLines splits content into lines without their line endings
+func splitLines(content []byte) [][]byte {
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return lines
+}
+
+// contextLines returns lines[from:to] clamped to the file
+func contextLines(lines [][]byte, from, to int) []string {
+	from, to = max(from, 0), min(to, len(lines))
+	if from >= to {
+		return nil
+	}
+	out := make([]string, 0, to-from)
+	for _, line := range lines[from:to] {
+		out = append(out, string(line))
+	}
+	return out
+}
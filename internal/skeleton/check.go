@@ -0,0 +1,374 @@
+package skeleton
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+)
+
+// stdImports are standard library packages a skeleton may refer to without
+// importing them, keyed by package name
+var stdImports = map[string]string{
+	"bytes":    "bytes",
+	"context":  "context",
+	"errors":   "errors",
+	"filepath": "path/filepath",
+	"fmt":      "fmt",
+	"http":     "net/http",
+	"httptest": "net/http/httptest",
+	"io":       "io",
+	"json":     "encoding/json",
+	"maps":     "maps",
+	"math":     "math",
+	"os":       "os",
+	"path":     "path",
+	"reflect":  "reflect",
+	"regexp":   "regexp",
+	"slices":   "slices",
+	"sort":     "sort",
+	"strconv":  "strconv",
+	"strings":  "strings",
+	"sync":     "sync",
+	"testing":  "testing",
+	"time":     "time",
+}
+
+// majorVersion matches the major version suffix of an import path
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// skeletonFile is the name the skeleton is checked under
+const skeletonFile = "suggested_skeleton_test.go"
+
+// Check type-checks a test skeleton as if it were a _test.go file next to
+// sourceFile. A skeleton without a package clause is placed in the source
+// file's package, and one without imports gets an import for every package
+// it refers to, taken from the imports of the package or the standard
+// library. Diagnostics are positioned within the skeleton text.
+func Check(baseDir, sourceFile, skeleton string) (*files.SkeletonCheck, error) {
+	return newChecker(baseDir).check(sourceFile, skeleton)
+}
+
+// CheckSuggestions type-checks the skeleton of every suggestion for a Go
+// source file and records the result on the suggestion. Suggestions without
+// a skeleton, or whose package cannot be loaded, are left unchecked.
+func CheckSuggestions(baseDir string, suggestions []files.TestSuggestion) {
+	c := newChecker(baseDir)
+	for i := range suggestions {
+		if strings.TrimSpace(suggestions[i].TestSkeleton) == "" {
+			continue
+		}
+		if check, err := c.check(suggestions[i].SourceFile, suggestions[i].TestSkeleton); err == nil {
+			suggestions[i].Check = check
+		}
+	}
+}
+
+// checker type-checks skeletons, sharing imported packages between them
+type checker struct {
+	baseDir  string
+	fset     *token.FileSet
+	importer types.Importer
+}
+
+// newChecker creates a checker for the codebase under baseDir
+func newChecker(baseDir string) *checker {
+	fset := token.NewFileSet()
+	return &checker{baseDir: baseDir, fset: fset, importer: importer.ForCompiler(fset, "source", nil)}
+}
+
+// check type-checks one skeleton against the package of sourceFile
+func (c *checker) check(sourceFile, skeleton string) (*files.SkeletonCheck, error) {
+	baseDir, fset := c.baseDir, c.fset
+	if !strings.HasSuffix(sourceFile, ".go") || strings.HasSuffix(sourceFile, "_test.go") {
+		return nil, fmt.Errorf("%s is not a Go source file", sourceFile)
+	}
+
+	relDir := path.Dir(filepath.ToSlash(sourceFile))
+	dir := filepath.Join(baseDir, filepath.FromSlash(relDir))
+
+	pkg, err := parsePackage(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := gomod.NewResolver(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	importPath, ok := resolver.PackagePath(relDir)
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside a Go module", relDir)
+	}
+
+	check := &files.SkeletonCheck{CheckedAt: time.Now()}
+	filename := filepath.Join(dir, skeletonFile)
+
+	src, offset, autoImported, err := wrap(filename, skeleton, pkg)
+	if err != nil {
+		check.Diagnostics = parseDiagnostics(err, offset)
+		return check, nil
+	}
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		check.Diagnostics = parseDiagnostics(err, offset)
+		return check, nil
+	}
+
+	var diagnostics []files.SkeletonDiagnostic
+	conf := types.Config{
+		Importer: c.importer,
+		Error: func(err error) {
+			var typeErr types.Error
+			if !errors.As(err, &typeErr) {
+				return
+			}
+			pos := fset.Position(typeErr.Pos)
+			if pos.Filename != filename {
+				return
+			}
+			// Imports added for the skeleton are allowed to go unused
+			if pos.Line <= offset && autoImported && strings.Contains(typeErr.Msg, "imported and not used") {
+				return
+			}
+			diagnostics = append(diagnostics, diagnostic(pos, typeErr.Msg, offset))
+		},
+	}
+
+	switch file.Name.Name {
+	case pkg.name:
+		parsed := append(append([]*ast.File{}, pkg.prod...), pkg.tests...)
+		conf.Check(importPath, fset, append(parsed, file), nil)
+	case pkg.name + "_test":
+		// The external test package sees the package with its internal tests
+		inner := types.Config{Importer: conf.Importer, Error: func(error) {}}
+		withTests, _ := inner.Check(importPath, fset, append(append([]*ast.File{}, pkg.prod...), pkg.tests...), nil)
+		conf.Importer = importerFunc(func(p string) (*types.Package, error) {
+			if p == importPath && withTests != nil {
+				return withTests, nil
+			}
+			return inner.Importer.Import(p)
+		})
+		conf.Check(importPath+"_test", fset, append(append([]*ast.File{}, pkg.xtests...), file), nil)
+	default:
+		diagnostics = append(diagnostics, diagnostic(fset.Position(file.Name.Pos()),
+			fmt.Sprintf("package %s does not match the package under test; use %s or %s_test", file.Name.Name, pkg.name, pkg.name), offset))
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+	check.Diagnostics = diagnostics
+	check.Compiles = len(diagnostics) == 0
+	return check, nil
+}
+
+// goPackage holds the parsed files of a package directory, split the way go
+// test compiles them
+type goPackage struct {
+	name     string
+	prod     []*ast.File
+	tests    []*ast.File
+	xtests   []*ast.File
+	declared map[string]bool   // top-level names of prod and internal test files
+	imports  map[string]string // package name to import path, from every file
+}
+
+// parsePackage parses the Go files of a directory that build on the current
+// platform
+func parsePackage(fset *token.FileSet, dir string) (*goPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &goPackage{declared: make(map[string]bool), imports: make(map[string]string)}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || name == skeletonFile {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+
+		for _, spec := range file.Imports {
+			importPath := strings.Trim(spec.Path.Value, `"`)
+			pkg.imports[importName(spec, importPath)] = importPath
+		}
+
+		switch {
+		case !strings.HasSuffix(name, "_test.go"):
+			pkg.name = file.Name.Name
+			pkg.prod = append(pkg.prod, file)
+		case strings.HasSuffix(file.Name.Name, "_test"):
+			pkg.xtests = append(pkg.xtests, file)
+			continue
+		default:
+			pkg.tests = append(pkg.tests, file)
+		}
+		for _, name := range topLevelNames(file) {
+			pkg.declared[name] = true
+		}
+	}
+
+	if pkg.name == "" {
+		return nil, fmt.Errorf("no Go source files in %s", dir)
+	}
+	return pkg, nil
+}
+
+// wrap completes a skeleton into a test file: a package clause when it has
+// none, and imports for the packages it refers to when it imports nothing.
+// It returns the source, the number of lines added before the skeleton and
+// whether imports were added.
+func wrap(filename, skeleton string, pkg *goPackage) (string, int, bool, error) {
+	header := ""
+	if _, err := parser.ParseFile(token.NewFileSet(), filename, skeleton, parser.PackageClauseOnly); err != nil {
+		header = "package " + pkg.name + "\n\n"
+	}
+
+	// Object resolution tells package qualifiers from local variables
+	file, err := parser.ParseFile(token.NewFileSet(), filename, header+skeleton, 0)
+	if err != nil {
+		return "", strings.Count(header, "\n"), false, err
+	}
+	if len(file.Imports) > 0 || header == "" {
+		return header + skeleton, strings.Count(header, "\n"), false, nil
+	}
+
+	needed := make(map[string]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || pkg.declared[ident.Name] {
+			return true
+		}
+		if importPath, ok := pkg.imports[ident.Name]; ok {
+			needed[ident.Name] = importPath
+		} else if importPath, ok := stdImports[ident.Name]; ok {
+			needed[ident.Name] = importPath
+		}
+		return true
+	})
+	if len(needed) == 0 {
+		return header + skeleton, strings.Count(header, "\n"), false, nil
+	}
+
+	names := make([]string, 0, len(needed))
+	for name := range needed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(header)
+	b.WriteString("import (\n")
+	for _, name := range names {
+		importPath := needed[name]
+		if path.Base(importPath) == name {
+			fmt.Fprintf(&b, "\t%q\n", importPath)
+		} else {
+			fmt.Fprintf(&b, "\t%s %q\n", name, importPath)
+		}
+	}
+	b.WriteString(")\n\n")
+
+	prefix := b.String()
+	return prefix + skeleton, strings.Count(prefix, "\n"), true, nil
+}
+
+// importName returns the name an import is referred to by
+func importName(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersion.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// topLevelNames returns the names declared at the top level of a file
+func topLevelNames(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						names = append(names, ident.Name)
+					}
+				case *ast.TypeSpec:
+					names = append(names, s.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// parseDiagnostics converts parser errors into diagnostics
+func parseDiagnostics(err error, offset int) []files.SkeletonDiagnostic {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []files.SkeletonDiagnostic{{Message: err.Error()}}
+	}
+
+	diagnostics := make([]files.SkeletonDiagnostic, 0, len(list))
+	for _, e := range list {
+		diagnostics = append(diagnostics, diagnostic(e.Pos, e.Msg, offset))
+	}
+	return diagnostics
+}
+
+// diagnostic positions a message within the skeleton text
+func diagnostic(pos token.Position, msg string, offset int) files.SkeletonDiagnostic {
+	line := pos.Line - offset
+	if line < 1 {
+		return files.SkeletonDiagnostic{Message: msg}
+	}
+	return files.SkeletonDiagnostic{Line: line, Column: pos.Column, Message: msg}
+}
+
+// importerFunc adapts a function to types.Importer
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -0,0 +1,84 @@
+package skeleton
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func writeFiles(t *testing.T, dir string, contents map[string]string) {
+	t.Helper()
+	for name, content := range contents {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":       "module example.com/m\n\ngo 1.21\n",
+		"calc/calc.go": "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n",
+	})
+
+	tests := []struct {
+		name     string
+		skeleton string
+		want     []files.SkeletonDiagnostic
+	}{
+		{
+			name:     "imports added",
+			skeleton: "func TestAdd(t *testing.T) {\n\tif got := Add(1, 2); !reflect.DeepEqual(got, 3) {\n\t\tt.Fatal(got)\n\t}\n}\n",
+		},
+		{
+			name:     "type errors",
+			skeleton: "func TestAdd(t *testing.T) {\n\tgot := Add(\"1\", 2)\n\tunused := 1\n}\n",
+			want: []files.SkeletonDiagnostic{
+				{Line: 2, Column: 13, Message: `cannot use "1" (untyped string constant) as int value in argument to Add`},
+				{Line: 2, Column: 2, Message: "declared and not used: got"},
+				{Line: 3, Column: 2, Message: "declared and not used: unused"},
+			},
+		},
+		{
+			name:     "syntax error",
+			skeleton: "func TestAdd(t *testing.T) {\n\tAdd(1,\n",
+			want:     []files.SkeletonDiagnostic{{Line: 2, Column: 9, Message: "expected ')', found 'EOF'"}},
+		},
+		{
+			name:     "external test package",
+			skeleton: "package calc_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/m/calc\"\n)\n\nfunc TestAdd(t *testing.T) {\n\t_ = calc.Sub(1, 2)\n}\n",
+			want:     []files.SkeletonDiagnostic{{Line: 10, Column: 11, Message: "undefined: calc.Sub"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check(dir, "calc/calc.go", tt.skeleton)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Compiles != (len(tt.want) == 0) {
+				t.Fatalf("Compiles = %v, diagnostics %+v", got.Compiles, got.Diagnostics)
+			}
+			if len(got.Diagnostics) != len(tt.want) {
+				t.Fatalf("Diagnostics = %+v, want %+v", got.Diagnostics, tt.want)
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, d := range got.Diagnostics {
+					found = found || d == want
+				}
+				if !found {
+					t.Fatalf("Diagnostics = %+v, want %+v", got.Diagnostics, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package skeleton
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"codebase-view-mcp/internal/analysis"
+)
+
+// reserved are identifiers the generated test uses itself; parameters with
+// these names get an "Arg" suffix
+var reserved = map[string]bool{
+	"t": true, "tt": true, "tests": true, "name": true, "receiver": true,
+	"got": true, "want": true, "err": true, "wantErr": true,
+}
+
+// field is a column of the generated test table
+type field struct {
+	name string
+	typ  string
+}
+
+// TestName returns the name of the generated test for a function: TestFunc,
+// TestType_Method, or Test_func for unexported functions
+func TestName(fn *ast.FuncDecl) string {
+	name := fn.Name.Name
+	if recv := analysis.ReceiverName(fn); recv != "" {
+		name = recv + "_" + name
+	}
+
+	if r := []rune(name); unicode.IsLower(r[0]) {
+		return "Test_" + name
+	}
+	return "Test" + name
+}
+
+// Generate returns a table-driven test skeleton for a function declared in a
+// file of the same package. Each parameter becomes a column of the table,
+// methods get a receiver column, results are compared with reflect.DeepEqual
+// and a trailing error result is checked against a wantErr column.
+// Type parameters are instantiated with any. constructor names a function
+// that builds the receiver, if the package has one; the skeleton refers to it.
+func Generate(fset *token.FileSet, fn *ast.FuncDecl, constructor string) string {
+	typeArgs := typeParams(fn)
+	subst := make(map[string]bool, len(typeArgs))
+	for _, tp := range typeArgs {
+		subst[tp] = true
+	}
+	if fn.Recv != nil {
+		for _, tp := range receiverTypeParams(fn) {
+			subst[tp] = true
+		}
+	}
+	typeString := func(expr ast.Expr) string {
+		return exprString(fset, substitute(expr, subst))
+	}
+
+	var columns []field
+	var receiver *field
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		receiver = &field{name: "receiver", typ: typeString(fn.Recv.List[0].Type)}
+		columns = append(columns, *receiver)
+	}
+
+	var args []string
+	index := 0
+	for _, param := range fn.Type.Params.List {
+		names := param.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, ident := range names {
+			name := fmt.Sprintf("arg%d", index)
+			if ident != nil && ident.Name != "_" {
+				name = ident.Name
+			}
+			if reserved[name] {
+				name += "Arg"
+			}
+			index++
+
+			col := field{name: name}
+			if ellipsis, ok := param.Type.(*ast.Ellipsis); ok {
+				col.typ = "[]" + typeString(ellipsis.Elt)
+				args = append(args, "tt."+name+"...")
+			} else {
+				col.typ = typeString(param.Type)
+				args = append(args, "tt."+name)
+			}
+			columns = append(columns, col)
+		}
+	}
+
+	var results []field
+	returnsErr := false
+	if fn.Type.Results != nil {
+		var types []string
+		for _, result := range fn.Type.Results.List {
+			count := max(len(result.Names), 1)
+			for range count {
+				types = append(types, typeString(result.Type))
+			}
+		}
+		if len(types) > 0 && types[len(types)-1] == "error" {
+			returnsErr = true
+			types = types[:len(types)-1]
+		}
+		for i, typ := range types {
+			suffix := ""
+			if i > 0 {
+				suffix = fmt.Sprint(i)
+			}
+			results = append(results, field{name: suffix, typ: typ})
+		}
+	}
+
+	var b strings.Builder
+	testName := TestName(fn)
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", testName)
+	b.WriteString("tests := []struct {\nname string\n")
+	for _, col := range columns {
+		fmt.Fprintf(&b, "%s %s\n", col.name, col.typ)
+	}
+	for _, result := range results {
+		fmt.Fprintf(&b, "want%s %s\n", result.name, result.typ)
+	}
+	if returnsErr {
+		b.WriteString("wantErr bool\n")
+	}
+	b.WriteString("}{\n// TODO: add test cases\n")
+	if receiver != nil && constructor != "" {
+		fmt.Fprintf(&b, "// build receivers with %s\n", constructor)
+	}
+	b.WriteString("}\n")
+
+	b.WriteString("for _, tt := range tests {\n")
+	b.WriteString("t.Run(tt.name, func(t *testing.T) {\n")
+
+	callee := fn.Name.Name
+	if receiver != nil {
+		callee = "tt.receiver." + callee
+	}
+	if len(typeArgs) > 0 {
+		callee += "[" + strings.Join(repeat("any", len(typeArgs)), ", ") + "]"
+	}
+	call := callee + "(" + strings.Join(args, ", ") + ")"
+	display := fn.Name.Name
+	if recv := analysis.ReceiverName(fn); recv != "" {
+		display = recv + "." + display
+	}
+
+	var lhs []string
+	for _, result := range results {
+		lhs = append(lhs, "got"+result.name)
+	}
+	if returnsErr {
+		lhs = append(lhs, "err")
+	}
+	if len(lhs) > 0 {
+		fmt.Fprintf(&b, "%s := %s\n", strings.Join(lhs, ", "), call)
+	} else {
+		b.WriteString(call + "\n")
+	}
+
+	if returnsErr {
+		fmt.Fprintf(&b, "if (err != nil) != tt.wantErr {\nt.Fatalf(\"%s() error = %%v, wantErr %%v\", err, tt.wantErr)\n}\n", display)
+		if len(results) > 0 {
+			b.WriteString("if err != nil {\nreturn\n}\n")
+		}
+	}
+	for _, result := range results {
+		fmt.Fprintf(&b, "if !reflect.DeepEqual(got%s, tt.want%s) {\nt.Errorf(\"%s() got%s = %%v, want %%v\", got%s, tt.want%s)\n}\n",
+			result.name, result.name, display, result.name, result.name, result.name)
+	}
+
+	b.WriteString("})\n}\n}\n")
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+	return string(formatted)
+}
+
+// typeParams returns the type parameter names of a generic function
+func typeParams(fn *ast.FuncDecl) []string {
+	if fn.Type.TypeParams == nil {
+		return nil
+	}
+
+	var names []string
+	for _, param := range fn.Type.TypeParams.List {
+		for _, ident := range param.Names {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
+
+// receiverTypeParams returns the type parameter names of a generic receiver
+func receiverTypeParams(fn *ast.FuncDecl) []string {
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	var indices []ast.Expr
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{e.Index}
+	case *ast.IndexListExpr:
+		indices = e.Indices
+	}
+
+	var names []string
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok && ident.Name != "_" {
+			names = append(names, ident.Name)
+		}
+	}
+	return names
+}
+
+// substitute returns a copy of a type expression with the named type
+// parameters replaced by any
+func substitute(expr ast.Expr, params map[string]bool) ast.Expr {
+	if len(params) == 0 {
+		return expr
+	}
+
+	var walk func(ast.Expr) ast.Expr
+	walk = func(e ast.Expr) ast.Expr {
+		switch e := e.(type) {
+		case *ast.Ident:
+			if params[e.Name] {
+				return ast.NewIdent("any")
+			}
+			return e
+		case *ast.StarExpr:
+			return &ast.StarExpr{X: walk(e.X)}
+		case *ast.ArrayType:
+			return &ast.ArrayType{Len: e.Len, Elt: walk(e.Elt)}
+		case *ast.MapType:
+			return &ast.MapType{Key: walk(e.Key), Value: walk(e.Value)}
+		case *ast.ChanType:
+			return &ast.ChanType{Dir: e.Dir, Value: walk(e.Value)}
+		case *ast.Ellipsis:
+			return &ast.Ellipsis{Elt: walk(e.Elt)}
+		case *ast.IndexExpr:
+			return &ast.IndexExpr{X: e.X, Index: walk(e.Index)}
+		case *ast.IndexListExpr:
+			indices := make([]ast.Expr, len(e.Indices))
+			for i, index := range e.Indices {
+				indices[i] = walk(index)
+			}
+			return &ast.IndexListExpr{X: e.X, Indices: indices}
+		case *ast.FuncType:
+			return &ast.FuncType{Params: walkFields(e.Params, walk), Results: walkFields(e.Results, walk)}
+		default:
+			return e
+		}
+	}
+
+	return walk(expr)
+}
+
+// walkFields applies a type rewrite to every field of a list
+func walkFields(list *ast.FieldList, walk func(ast.Expr) ast.Expr) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+
+	out := &ast.FieldList{}
+	for _, f := range list.List {
+		out.List = append(out.List, &ast.Field{Names: f.Names, Type: walk(f.Type)})
+	}
+	return out
+}
+
+// exprString prints an expression as source code
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+// repeat returns n copies of s
+func repeat(s string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,317 @@
+package skeleton
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+	"codebase-view-mcp/internal/patch"
+)
+
+// ErrApplyDisabled is returned when a patch is applied by a materializer that
+// does not allow writing to the working tree
+var ErrApplyDisabled = errors.New("applying suggestions to the working tree is disabled")
+
+// ErrNoSuggestion is returned when a file has no suggestion with the
+// requested name
+var ErrNoSuggestion = errors.New("suggestion not found")
+
+// ErrConflict is returned when the test file already declares a name the
+// skeleton declares
+var ErrConflict = errors.New("test file already declares the suggested test")
+
+// Materializer turns stored suggestions into patches of the test file next
+// to their source file and, when allowed, applies them
+type Materializer struct {
+	baseDir    string
+	allowApply bool
+}
+
+// NewMaterializer creates a materializer for the codebase under baseDir.
+// Patches are only written to the working tree when allowApply is set.
+func NewMaterializer(baseDir string, allowApply bool) *Materializer {
+	return &Materializer{baseDir: baseDir, allowApply: allowApply}
+}
+
+// ApplyEnabled reports whether patches may be written to the working tree
+func (m *Materializer) ApplyEnabled() bool {
+	return m != nil && m.allowApply
+}
+
+// Patch is a suggestion inserted into its test file
+type Patch struct {
+	TestFile string // relative to the base directory
+	Created  bool   // the test file does not exist yet
+	Diff     string // unified diff against the current test file
+
+	original []byte
+	content  []byte
+}
+
+// Materialize inserts the skeleton of a suggestion into the _test.go file
+// next to its source file: the skeleton's declarations are appended and its
+// imports merged into the file's imports. A missing test file is created with
+// the package clause and imports of the skeleton. The result is gofmt'd.
+func (m *Materializer) Materialize(suggestion files.TestSuggestion) (*Patch, error) {
+	sourceFile := filepath.ToSlash(suggestion.SourceFile)
+	if !filepath.IsLocal(sourceFile) {
+		return nil, fmt.Errorf("source file %s is outside the base directory", sourceFile)
+	}
+	if !strings.HasSuffix(sourceFile, ".go") || strings.HasSuffix(sourceFile, "_test.go") {
+		return nil, fmt.Errorf("%s is not a Go source file", sourceFile)
+	}
+	if strings.TrimSpace(suggestion.TestSkeleton) == "" {
+		return nil, fmt.Errorf("suggestion %s has no test skeleton", suggestion.SuggestedName)
+	}
+
+	testFile := strings.TrimSuffix(sourceFile, ".go") + "_test.go"
+	testPath := filepath.Join(m.baseDir, filepath.FromSlash(testFile))
+
+	pkg, err := parsePackage(token.NewFileSet(), filepath.Join(m.baseDir, filepath.FromSlash(path.Dir(sourceFile))))
+	if err != nil {
+		return nil, err
+	}
+
+	src, _, _, err := wrap(testPath, suggestion.TestSkeleton, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("skeleton does not parse: %w", err)
+	}
+
+	original, err := os.ReadFile(testPath)
+	if errors.Is(err, os.ErrNotExist) {
+		content, err := format.Source([]byte(src))
+		if err != nil {
+			return nil, fmt.Errorf("skeleton does not parse: %w", err)
+		}
+		return &Patch{
+			TestFile: testFile,
+			Created:  true,
+			Diff:     patch.Unified("", testFile, "", string(content)),
+			content:  content,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := merge(testPath, original, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Patch{
+		TestFile: testFile,
+		Diff:     patch.Unified(testFile, testFile, string(original), string(content)),
+		original: original,
+		content:  content,
+	}, nil
+}
+
+// MaterializeStored materializes the stored suggestion of a source file with
+// the given suggested name. With apply set the patch is written to the
+// working tree and the suggestion, now accepted, is removed from the store.
+func (m *Materializer) MaterializeStored(store *metadata.Store, sourceFile, suggestedName string, apply bool) (*files.MaterializeResponse, error) {
+	if apply && !m.ApplyEnabled() {
+		return nil, ErrApplyDisabled
+	}
+
+	var suggestion *files.TestSuggestion
+	for _, s := range store.GetSuggestions(sourceFile) {
+		if s.SuggestedName == suggestedName {
+			suggestion = &s
+			break
+		}
+	}
+	if suggestion == nil {
+		return nil, fmt.Errorf("%w: %s has no suggestion %s", ErrNoSuggestion, sourceFile, suggestedName)
+	}
+	suggestion.SourceFile = sourceFile
+
+	p, err := m.Materialize(*suggestion)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &files.MaterializeResponse{
+		SourceFile:    sourceFile,
+		SuggestedName: suggestedName,
+		TestFile:      p.TestFile,
+		Created:       p.Created,
+		Diff:          p.Diff,
+	}
+	if !apply {
+		return response, nil
+	}
+
+	if err := m.Apply(p); err != nil {
+		return nil, err
+	}
+	response.Applied = true
+
+	if err := store.RemoveSuggestion(sourceFile, suggestedName); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// Apply writes a patch to the working tree. It fails when the test file
+// changed since the patch was made.
+func (m *Materializer) Apply(p *Patch) error {
+	if !m.ApplyEnabled() {
+		return ErrApplyDisabled
+	}
+
+	testPath := filepath.Join(m.baseDir, filepath.FromSlash(p.TestFile))
+	current, err := os.ReadFile(testPath)
+	switch {
+	case p.Created && err == nil:
+		return fmt.Errorf("%s was created since the patch was made", p.TestFile)
+	case !p.Created && err != nil:
+		return err
+	case !p.Created && !bytes.Equal(current, p.original):
+		return fmt.Errorf("%s changed since the patch was made", p.TestFile)
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(testPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	// Write through a temporary file so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(testPath), ".suggestion-*.go")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(p.content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), testPath)
+}
+
+// merge appends the declarations of a complete skeleton file to an existing
+// test file and adds the imports the test file lacks
+func merge(testPath string, original []byte, skeleton string) ([]byte, error) {
+	fset := token.NewFileSet()
+	testAST, err := parser.ParseFile(fset, testPath, original, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", filepath.Base(testPath), err)
+	}
+	skeletonAST, err := parser.ParseFile(fset, testPath, skeleton, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("skeleton does not parse: %w", err)
+	}
+
+	if skeletonAST.Name.Name != testAST.Name.Name {
+		return nil, fmt.Errorf("skeleton is in package %s but %s is in package %s",
+			skeletonAST.Name.Name, filepath.Base(testPath), testAST.Name.Name)
+	}
+
+	declared := make(map[string]bool)
+	for _, name := range topLevelNames(testAST) {
+		declared[name] = true
+	}
+	var conflicts []string
+	for _, name := range topLevelNames(skeletonAST) {
+		if declared[name] && name != "_" {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("%w: %s", ErrConflict, strings.Join(conflicts, ", "))
+	}
+
+	existing := make(map[string]bool)
+	for _, spec := range testAST.Imports {
+		existing[importKey(spec)] = true
+	}
+	var missing []string
+	for _, spec := range skeletonAST.Imports {
+		if !existing[importKey(spec)] {
+			missing = append(missing, importKey(spec))
+		}
+	}
+
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+	body := strings.TrimSpace(skeleton[offset(declStart(skeletonAST)):])
+
+	src := string(original)
+	if len(missing) > 0 {
+		src = addImports(src, testAST, offset, missing)
+	}
+	src = strings.TrimRight(src, "\n") + "\n\n" + body + "\n"
+
+	content, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, fmt.Errorf("merged test file does not parse: %w", err)
+	}
+	return content, nil
+}
+
+// declStart returns the position after a file's package clause and imports
+func declStart(file *ast.File) token.Pos {
+	start := file.Name.End()
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			start = gen.End()
+		}
+	}
+	return start
+}
+
+// addImports inserts import specs into the last import declaration of a file,
+// or after its package clause when it imports nothing
+func addImports(src string, file *ast.File, offset func(token.Pos) int, specs []string) string {
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	lines := "\t" + strings.Join(specs, "\n\t") + "\n"
+	switch {
+	case last == nil:
+		at := offset(file.Name.End())
+		return src[:at] + "\n\nimport (\n" + lines + ")" + src[at:]
+	case last.Lparen.IsValid():
+		at := offset(last.Rparen)
+		if src[at-1] != '\n' {
+			lines = "\n" + lines
+		}
+		return src[:at] + lines + src[at:]
+	default:
+		from, to := offset(last.Pos()), offset(last.End())
+		existing := strings.TrimSpace(strings.TrimPrefix(src[from:to], "import"))
+		return src[:from] + "import (\n\t" + existing + "\n" + lines + ")" + src[to:]
+	}
+}
+
+// importKey returns the source form of an import spec, which identifies it
+// by name and path
+func importKey(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name + " " + spec.Path.Value
+	}
+	return spec.Path.Value
+}
@@ -0,0 +1,83 @@
+package skeleton
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+)
+
+const addSkeleton = "func TestAdd(t *testing.T) {\n\tif got := Add(1, 2); !reflect.DeepEqual(got, 3) {\n\t\tt.Fatal(got)\n\t}\n}\n"
+
+func TestMaterialize(t *testing.T) {
+	setup := func(t *testing.T, testFile string) string {
+		dir := t.TempDir()
+		sources := map[string]string{"calc/calc.go": "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"}
+		if testFile != "" {
+			sources["calc/calc_test.go"] = testFile
+		}
+		writeFiles(t, dir, sources)
+		return dir
+	}
+	suggestion := files.TestSuggestion{SourceFile: "calc/calc.go", SuggestedName: "TestAdd", TestSkeleton: addSkeleton}
+
+	t.Run("new test file", func(t *testing.T) {
+		dir := setup(t, "")
+		got, err := NewMaterializer(dir, false).Materialize(suggestion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Created || got.TestFile != "calc/calc_test.go" {
+			t.Fatalf("patch = %+v, want a new calc/calc_test.go", got)
+		}
+		want := "--- /dev/null\n+++ b/calc/calc_test.go\n@@ -0,0 +1,12 @@\n+package calc\n+\n+import (\n+\t\"reflect\"\n+\t\"testing\"\n+)\n+\n+func TestAdd"
+		if !strings.HasPrefix(got.Diff, want) {
+			t.Fatalf("Diff =\n%s\nwant prefix\n%s", got.Diff, want)
+		}
+	})
+
+	t.Run("existing test file", func(t *testing.T) {
+		dir := setup(t, "package calc\n\nimport \"testing\"\n\nfunc TestSub(t *testing.T) {}\n")
+		got, err := NewMaterializer(dir, false).Materialize(suggestion)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "--- a/calc/calc_test.go\n+++ b/calc/calc_test.go\n@@ -1,5 +1,14 @@\n package calc\n \n-import \"testing\"\n+import (\n+\t\"reflect\"\n+\t\"testing\"\n+)\n \n func TestSub(t *testing.T) {}\n+\n+func TestAdd(t *testing.T) {\n"
+		if !strings.HasPrefix(got.Diff, want) {
+			t.Fatalf("Diff =\n%s\nwant prefix\n%s", got.Diff, want)
+		}
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		dir := setup(t, "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n")
+		if _, err := NewMaterializer(dir, false).Materialize(suggestion); !errors.Is(err, ErrConflict) {
+			t.Fatalf("Materialize() error = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		dir := setup(t, "")
+		store := metadata.NewStore("")
+		store.AddSuggestions("calc/calc.go", []files.TestSuggestion{suggestion})
+
+		if _, err := NewMaterializer(dir, false).MaterializeStored(store, "calc/calc.go", "TestAdd", true); !errors.Is(err, ErrApplyDisabled) {
+			t.Fatalf("MaterializeStored() error = %v, want ErrApplyDisabled", err)
+		}
+
+		got, err := NewMaterializer(dir, true).MaterializeStored(store, "calc/calc.go", "TestAdd", true)
+		if err != nil || !got.Applied {
+			t.Fatalf("MaterializeStored() = %+v, %v, want applied", got, err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, "calc", "calc_test.go"))
+		if err != nil || !strings.Contains(string(content), "func TestAdd(t *testing.T) {") {
+			t.Fatalf("calc_test.go = %q, %v, want TestAdd", content, err)
+		}
+		if remaining := store.GetSuggestions("calc/calc.go"); len(remaining) != 0 {
+			t.Fatalf("suggestions = %+v, want the applied one removed", remaining)
+		}
+	})
+}
@@ -0,0 +1,309 @@
+package skeleton
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// OriginGenerated marks suggestions created by the skeleton scanner
+const OriginGenerated = "generated"
+
+// complexThreshold is the cyclomatic complexity from which a function is
+// considered complex enough to raise its priority
+const complexThreshold = 5
+
+// skipDirs are directories never scanned for functions
+var skipDirs = map[string]bool{
+	"vendor":       true,
+	"testdata":     true,
+	"node_modules": true,
+}
+
+// Scan walks relDir under baseDir and generates a suggestion with a test
+// skeleton for every Go function that no test reference names or covers.
+// Functions that already have a suggestion are skipped.
+func Scan(store *metadata.Store, baseDir, relDir string) (*files.GenerateSuggestionsResponse, error) {
+	response := &files.GenerateSuggestionsResponse{Suggestions: []files.TestSuggestion{}}
+
+	root := filepath.Join(baseDir, relDir)
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("cannot scan %s: %w", relDir, err)
+	}
+
+	byDir := make(map[string][]string)
+	ignored := ignore.For(baseDir)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()] || ignored.IgnoredPath(p, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignored.IgnoredPath(p, false) {
+			return nil
+		}
+
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		byDir[path.Dir(rel)] = append(byDir[path.Dir(rel)], rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		sourceFiles := byDir[dir]
+		sort.Strings(sourceFiles)
+		response.Suggestions = append(response.Suggestions, scanPackage(store, baseDir, sourceFiles)...)
+	}
+
+	return response, nil
+}
+
+// Apply stores generated suggestions and returns how many were stored
+func Apply(store *metadata.Store, response *files.GenerateSuggestionsResponse) (int, error) {
+	bySource := make(map[string][]files.TestSuggestion)
+	var sources []string
+	for _, suggestion := range response.Suggestions {
+		if _, ok := bySource[suggestion.SourceFile]; !ok {
+			sources = append(sources, suggestion.SourceFile)
+		}
+		bySource[suggestion.SourceFile] = append(bySource[suggestion.SourceFile], suggestion)
+	}
+
+	applied := 0
+	for _, source := range sources {
+		if err := store.AddSuggestions(source, bySource[source]); err != nil {
+			return applied, err
+		}
+		applied += len(bySource[source])
+	}
+
+	return applied, nil
+}
+
+// parsedFile is a parsed source file of a package
+type parsedFile struct {
+	path string
+	file *ast.File
+}
+
+// scanPackage generates suggestions for the untested functions of the source
+// files of one directory
+func scanPackage(store *metadata.Store, baseDir string, sourceFiles []string) []files.TestSuggestion {
+	fset := token.NewFileSet()
+	var parsed []parsedFile
+	for _, sourceFile := range sourceFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(baseDir, sourceFile), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil || ast.IsGenerated(file) {
+			continue
+		}
+		parsed = append(parsed, parsedFile{path: sourceFile, file: file})
+	}
+
+	constructors := make(map[string]string)
+	for _, pf := range parsed {
+		for typeName, constructor := range findConstructors(pf.file) {
+			constructors[typeName] = constructor
+		}
+	}
+
+	var suggestions []files.TestSuggestion
+	for _, pf := range parsed {
+		meta := store.GetTestMetadata(pf.path)
+		for _, decl := range pf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || !needsTest(pf.file, fn) {
+				continue
+			}
+
+			recv := analysis.ReceiverName(fn)
+			qualified := fn.Name.Name
+			if recv != "" {
+				qualified = recv + "." + qualified
+			}
+			lines := files.LineRange{
+				Start: fset.Position(fn.Pos()).Line,
+				End:   fset.Position(fn.End()).Line,
+			}
+			testName := TestName(fn)
+			if isTested(meta, fn.Name.Name, qualified, lines) || hasSuggestion(meta, qualified, testName) {
+				continue
+			}
+
+			complexity := Complexity(fn)
+			suggestions = append(suggestions, files.TestSuggestion{
+				SourceFile:    pf.path,
+				FunctionName:  qualified,
+				TargetLines:   lines,
+				Reason:        reason(fn, recv, complexity),
+				SuggestedName: testName,
+				TestSkeleton:  Generate(fset, fn, constructors[recv]),
+				Priority:      priority(fn.Name.IsExported(), complexity),
+				Origin:        OriginGenerated,
+			})
+		}
+	}
+
+	return suggestions
+}
+
+// needsTest reports whether a function is worth a suggestion: init, main and
+// blank functions are not
+func needsTest(file *ast.File, fn *ast.FuncDecl) bool {
+	switch name := fn.Name.Name; {
+	case name == "_":
+		return false
+	case fn.Recv == nil && name == "init":
+		return false
+	case fn.Recv == nil && name == "main" && file.Name.Name == "main":
+		return false
+	}
+	return true
+}
+
+// isTested reports whether a test reference names the function or declares
+// coverage of one of its lines
+func isTested(meta *files.FileMetadata, name, qualified string, lines files.LineRange) bool {
+	if meta == nil {
+		return false
+	}
+
+	for _, test := range meta.Tests {
+		if test.FunctionName == name || test.FunctionName == qualified {
+			return true
+		}
+		for _, line := range metadata.CoveredLines(test) {
+			if line >= lines.Start && line <= lines.End {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasSuggestion reports whether a suggestion for the function or with the
+// generated test name exists
+func hasSuggestion(meta *files.FileMetadata, qualified, testName string) bool {
+	if meta == nil {
+		return false
+	}
+
+	for _, suggestion := range meta.Suggestions {
+		if suggestion.FunctionName == qualified || suggestion.SuggestedName == testName {
+			return true
+		}
+	}
+	return false
+}
+
+// findConstructors maps type names to the New functions of a file that
+// return them, preferring NewT for a type T
+func findConstructors(file *ast.File) map[string]string {
+	constructors := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "New") || fn.Type.Results == nil {
+			continue
+		}
+
+		result := fn.Type.Results.List[0].Type
+		if star, ok := result.(*ast.StarExpr); ok {
+			result = star.X
+		}
+		ident, ok := result.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		// Prefer NewT over other constructors of T
+		if existing, ok := constructors[ident.Name]; !ok || existing != "New"+ident.Name {
+			constructors[ident.Name] = fn.Name.Name
+		}
+	}
+	return constructors
+}
+
+// Complexity returns the cyclomatic complexity of a function: one plus the
+// number of branches and boolean operators
+func Complexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// priority ranks a suggestion: exported functions before unexported ones,
+// complex functions before simple ones
+func priority(exported bool, complexity int) string {
+	isComplex := complexity >= complexThreshold
+	switch {
+	case exported && isComplex:
+		return "high"
+	case exported || isComplex:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// reason explains why a suggestion was generated
+func reason(fn *ast.FuncDecl, recv string, complexity int) string {
+	kind := "function"
+	if recv != "" {
+		kind = "method"
+	}
+	visibility := "Unexported"
+	if fn.Name.IsExported() {
+		visibility = "Exported"
+	}
+	return fmt.Sprintf("%s %s with no test references (cyclomatic complexity %d)", visibility, kind, complexity)
+}
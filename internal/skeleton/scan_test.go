@@ -0,0 +1,142 @@
+package skeleton
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+)
+
+const scanSource = `package store
+
+import "errors"
+
+type Store struct{}
+
+func NewStore() *Store {
+	return &Store{}
+}
+
+func (s *Store) Get(name string, opts ...int) (string, error) {
+	if name == "" || len(opts) > 3 {
+		return "", errors.New("bad input")
+	}
+	return name, nil
+}
+
+func Map[T any](xs []T, f func(T) T) []T {
+	return nil
+}
+
+func init() {}
+`
+
+func TestGenerate(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "store.go", scanSource, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decl := func(name string) *ast.FuncDecl {
+		for _, d := range file.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == name {
+				return fn
+			}
+		}
+		t.Fatalf("no function %s", name)
+		return nil
+	}
+
+	t.Run("method with error result", func(t *testing.T) {
+		got := Generate(fset, decl("Get"), "NewStore")
+		for _, want := range []string{
+			"func TestStore_Get(t *testing.T) {",
+			"receiver *Store",
+			"nameArg  string",
+			"opts     []int",
+			"wantErr  bool",
+			"// build receivers with NewStore",
+			"got, err := tt.receiver.Get(tt.nameArg, tt.opts...)",
+			"if (err != nil) != tt.wantErr {",
+		} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("skeleton missing %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("generic function", func(t *testing.T) {
+		got := Generate(fset, decl("Map"), "")
+		for _, want := range []string{"xs   []any", "f    func(any) any", "got := Map[any](tt.xs, tt.f)"} {
+			if !strings.Contains(got, want) {
+				t.Fatalf("skeleton missing %q:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("complexity", func(t *testing.T) {
+		if got := Complexity(decl("Get")); got != 3 {
+			t.Fatalf("Complexity(Get) = %d, want 3", got)
+		}
+	})
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "store"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "store", "store.go"), []byte(scanSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := metadata.NewStore("")
+	store.AddTestMetadata("store/store.go", []files.TestReference{{
+		FunctionName: "NewStore",
+		TestFile:     "store/store_test.go",
+		TestName:     "TestNewStore",
+	}})
+	store.AddSuggestions("store/store.go", []files.TestSuggestion{{
+		FunctionName:  "Map",
+		SuggestedName: "TestMapIdentity",
+	}})
+
+	response, err := Scan(store, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("untested functions", func(t *testing.T) {
+		if len(response.Suggestions) != 1 {
+			t.Fatalf("got %d suggestions, want only Store.Get: %+v", len(response.Suggestions), response.Suggestions)
+		}
+		got := response.Suggestions[0]
+		if got.FunctionName != "Store.Get" || got.SuggestedName != "TestStore_Get" || got.Origin != OriginGenerated {
+			t.Fatalf("suggestion = %+v, want generated TestStore_Get for Store.Get", got)
+		}
+		if got.TargetLines != (files.LineRange{Start: 11, End: 16}) || got.Priority != "medium" {
+			t.Fatalf("suggestion = %+v, want lines 11-16 at medium priority", got)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		applied, err := Apply(store, response)
+		if err != nil || applied != 1 {
+			t.Fatalf("Apply() = %d, %v, want 1", applied, err)
+		}
+		if len(store.GetSuggestions("store/store.go")) != 2 {
+			t.Fatalf("stored suggestions = %+v, want the existing one and the generated one", store.GetSuggestions("store/store.go"))
+		}
+
+		again, err := Scan(store, dir, "")
+		if err != nil || len(again.Suggestions) != 0 {
+			t.Fatalf("second Scan() = %+v, %v, want no new suggestions", again, err)
+		}
+	})
+}
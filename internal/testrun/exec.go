@@ -0,0 +1,229 @@
+package testrun
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+)
+
+// ErrBusy is returned when the concurrency limit of an executor is reached
+var ErrBusy = errors.New("too many test runs in progress")
+
+// Executor runs selected tests with go test -json under a timeout and a
+// limit on concurrent runs. Only packages matching the allow-list run.
+type Executor struct {
+	baseDir string
+	allow   []string
+	timeout time.Duration
+	slots   chan struct{}
+}
+
+// NewExecutor creates an executor for the codebase under baseDir. allow lists
+// package directories relative to baseDir; "dir/..." also allows its
+// subdirectories and "./..." allows every package. An empty list disables runs.
+func NewExecutor(baseDir string, allow []string, timeout time.Duration, concurrency int) *Executor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var cleaned []string
+	for _, entry := range allow {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			cleaned = append(cleaned, entry)
+		}
+	}
+
+	return &Executor{
+		baseDir: baseDir,
+		allow:   cleaned,
+		timeout: timeout,
+		slots:   make(chan struct{}, concurrency),
+	}
+}
+
+// Enabled reports whether any package is allowed to run
+func (e *Executor) Enabled() bool {
+	return e != nil && len(e.allow) > 0
+}
+
+// Allowed reports whether tests in the package directory relDir may run
+func (e *Executor) Allowed(relDir string) bool {
+	if !e.Enabled() {
+		return false
+	}
+
+	relDir = path.Clean(filepath.ToSlash(relDir))
+	for _, entry := range e.allow {
+		entry = filepath.ToSlash(entry)
+		if prefix, ok := strings.CutSuffix(entry, "/..."); ok {
+			prefix = path.Clean(prefix)
+			if prefix == "." || relDir == prefix || strings.HasPrefix(relDir, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if path.Clean(entry) == relDir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Run runs the given tests, grouped into one go test invocation per module,
+// and passes every event to emit as it arrives. Lines that are not JSON
+// events, such as build errors, are passed on as output events. Subtests
+// run through their top-level test.
+func (e *Executor) Run(ctx context.Context, tests []files.TestID, emit func(Event)) ([]Result, error) {
+	select {
+	case e.slots <- struct{}{}:
+		defer func() { <-e.slots }()
+	default:
+		return nil, ErrBusy
+	}
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	resolver, err := gomod.NewResolver(e.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	type invocation struct {
+		names    map[string]bool
+		packages map[string]bool
+	}
+	byModule := make(map[string]*invocation)
+
+	for _, test := range tests {
+		if !filepath.IsLocal(test.TestFile) {
+			return nil, fmt.Errorf("test file %s is outside the base directory", test.TestFile)
+		}
+
+		dir := path.Dir(test.TestFile)
+		if !e.Allowed(dir) {
+			return nil, fmt.Errorf("tests in %s are not allowed to run", dir)
+		}
+
+		module, ok := resolver.ModuleFor(dir)
+		if !ok {
+			return nil, fmt.Errorf("%s is not inside a Go module", dir)
+		}
+
+		inv := byModule[module.Dir]
+		if inv == nil {
+			inv = &invocation{names: make(map[string]bool), packages: make(map[string]bool)}
+			byModule[module.Dir] = inv
+		}
+
+		pkgDir := dir
+		if module.Dir != "." {
+			pkgDir = strings.TrimPrefix(dir, module.Dir+"/")
+			if dir == module.Dir {
+				pkgDir = "."
+			}
+		}
+		topLevel, _, _ := strings.Cut(test.TestName, "/")
+		inv.names[regexp.QuoteMeta(topLevel)] = true
+		inv.packages["./"+pkgDir] = true
+	}
+
+	collector := NewCollector()
+	moduleDirs := make([]string, 0, len(byModule))
+	for moduleDir := range byModule {
+		moduleDirs = append(moduleDirs, moduleDir)
+	}
+	sort.Strings(moduleDirs)
+
+	for _, moduleDir := range moduleDirs {
+		inv := byModule[moduleDir]
+		args := []string{"test", "-json", "-count=1", "-run", "^(" + strings.Join(sortedKeys(inv.names), "|") + ")$"}
+		args = append(args, sortedKeys(inv.packages)...)
+
+		err := e.stream(ctx, filepath.Join(e.baseDir, moduleDir), args, func(event Event) {
+			collector.Add(event)
+			emit(event)
+		})
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return collector.Results(), fmt.Errorf("test run timed out after %v", e.timeout)
+		}
+		if err != nil {
+			return collector.Results(), err
+		}
+	}
+
+	return collector.Results(), nil
+}
+
+// stream runs go test in dir and decodes its output line by line. A non-zero
+// exit status is not an error: failures are reported through events.
+func (e *Executor) stream(ctx context.Context, dir string, args []string, emit func(Event)) error {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	// Test binaries left behind by a cancelled run must not hold the pipe open
+	cmd.WaitDelay = 5 * time.Second
+
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start go test: %w", err)
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		var event Event
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &event) == nil {
+			emit(event)
+			continue
+		}
+		emit(Event{Time: time.Now(), Action: "output", Output: line + "\n"})
+	}
+	// Drain the pipe so go test never blocks on a full buffer
+	io.Copy(io.Discard, pr)
+
+	err := <-waitErr
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
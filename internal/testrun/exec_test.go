@@ -0,0 +1,105 @@
+package testrun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+)
+
+func TestExecutorAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		dir   string
+		want  bool
+	}{
+		{"disabled", nil, "pkg", false},
+		{"exact", []string{"pkg"}, "pkg", true},
+		{"exact excludes children", []string{"pkg"}, "pkg/sub", false},
+		{"recursive", []string{"pkg/..."}, "pkg/sub", true},
+		{"recursive includes itself", []string{"pkg/..."}, "pkg", true},
+		{"recursive prefix only", []string{"pkg/..."}, "pkgx", false},
+		{"all", []string{"./..."}, "any/dir", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutor(t.TempDir(), tt.allow, time.Minute, 1)
+			if got := e.Allowed(tt.dir); got != tt.want {
+				t.Fatalf("Allowed(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutorRun(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/run\n\ngo 1.22\n")
+	write("pkg/pkg_test.go", `package pkg
+
+import "testing"
+
+func TestPass(t *testing.T) {
+	t.Log("hello")
+}
+
+func TestFail(t *testing.T) {
+	t.Fatal("boom")
+}
+
+func TestOther(t *testing.T) {}
+`)
+
+	e := NewExecutor(dir, []string{"pkg"}, time.Minute, 1)
+	var outputs int
+	results, err := e.Run(context.Background(), []files.TestID{
+		{TestFile: "pkg/pkg_test.go", TestName: "TestPass"},
+		{TestFile: "pkg/pkg_test.go", TestName: "TestFail"},
+	}, func(event Event) {
+		if event.Action == "output" {
+			outputs++
+		}
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, result := range results {
+		statuses[result.Test] = result.Status
+	}
+	if len(statuses) != 2 || statuses["TestPass"] != "pass" || statuses["TestFail"] != "fail" {
+		t.Fatalf("statuses = %v, want TestPass pass and TestFail fail only", statuses)
+	}
+	if outputs == 0 {
+		t.Fatal("no output events streamed")
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		_, err := e.Run(context.Background(), []files.TestID{{TestFile: "other/x_test.go", TestName: "TestX"}}, func(Event) {})
+		if err == nil {
+			t.Fatal("Run() outside the allow-list succeeded")
+		}
+	})
+
+	t.Run("outside base directory", func(t *testing.T) {
+		all := NewExecutor(dir, []string{"./..."}, time.Minute, 1)
+		_, err := all.Run(context.Background(), []files.TestID{{TestFile: "../x_test.go", TestName: "TestX"}}, func(Event) {})
+		if err == nil {
+			t.Fatal("Run() with a parent directory test file succeeded")
+		}
+	})
+}
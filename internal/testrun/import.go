@@ -0,0 +1,119 @@
+package testrun
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"codebase-view-mcp/internal/analysis"
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/gomod"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// Import parses go test -json output and records the result of every test
+// whose declaration can be located under baseDir
+func Import(store *metadata.Store, baseDir string, r io.Reader) (*files.TestResultsImportResponse, error) {
+	results, err := ParseJSON(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Record(store, baseDir, results)
+}
+
+// Record stores test results on the metadata of the test files declaring them.
+// Subtest results are stored under their full name next to their parent.
+func Record(store *metadata.Store, baseDir string, results []Result) (*files.TestResultsImportResponse, error) {
+	resolver, err := gomod.NewResolver(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	locator := newTestLocator(baseDir, resolver)
+	byFile := make(map[string]map[string]metadata.TestResult)
+	response := &files.TestResultsImportResponse{}
+
+	for _, result := range results {
+		topLevel, _, _ := strings.Cut(result.Test, "/")
+
+		testFile, ok := locator.find(result.Package, topLevel)
+		if !ok {
+			if topLevel == result.Test {
+				response.Unmatched = append(response.Unmatched, result.Package+"."+result.Test)
+			}
+			continue
+		}
+
+		runAt := result.Time
+		if runAt.IsZero() {
+			runAt = time.Now()
+		}
+
+		if byFile[testFile] == nil {
+			byFile[testFile] = make(map[string]metadata.TestResult)
+		}
+		byFile[testFile][result.Test] = metadata.TestResult{
+			Status:  result.Status,
+			Elapsed: result.Elapsed,
+			Output:  result.Output,
+			RunAt:   runAt,
+		}
+
+		if topLevel != result.Test {
+			continue
+		}
+
+		switch result.Status {
+		case "pass":
+			response.Passed++
+		case "fail":
+			response.Failed++
+		case "skip":
+			response.Skipped++
+		}
+	}
+
+	if err := store.SetTestResults(byFile); err != nil {
+		return nil, fmt.Errorf("failed to store test results: %w", err)
+	}
+
+	sort.Strings(response.Unmatched)
+
+	return response, nil
+}
+
+// testLocator finds the test file declaring a test, caching parsed packages
+type testLocator struct {
+	baseDir  string
+	resolver *gomod.Resolver
+	packages map[string]map[string]string // import path -> test name -> test file
+}
+
+func newTestLocator(baseDir string, resolver *gomod.Resolver) *testLocator {
+	return &testLocator{
+		baseDir:  baseDir,
+		resolver: resolver,
+		packages: make(map[string]map[string]string),
+	}
+}
+
+// find returns the test file declaring the named top-level test in a package
+func (l *testLocator) find(pkgPath, testName string) (string, bool) {
+	tests, ok := l.packages[pkgPath]
+	if !ok {
+		tests = make(map[string]string)
+		if dir, ok := l.resolver.RelativePath(pkgPath); ok {
+			discovered, _ := analysis.DiscoverTests(l.baseDir, dir)
+			for _, test := range discovered {
+				tests[test.Name] = test.File
+			}
+		}
+		l.packages[pkgPath] = tests
+	}
+
+	testFile, ok := tests[testName]
+	return testFile, ok
+}
@@ -0,0 +1,113 @@
+package testrun
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxOutputBytes limits the stored output of a failing test
+const maxOutputBytes = 64 * 1024
+
+// Event is a single line of go test -json output (see go doc test2json)
+type Event struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// Result is the final outcome of one test in a go test -json stream
+type Result struct {
+	Package string
+	Test    string // full test name, including subtest path
+	Status  string // pass, fail or skip
+	Elapsed float64
+	Output  string
+	Time    time.Time
+}
+
+// Collector accumulates go test -json events into per-test results
+type Collector struct {
+	output  map[string]*strings.Builder
+	results []Result
+}
+
+// NewCollector creates an empty collector
+func NewCollector() *Collector {
+	return &Collector{
+		output: make(map[string]*strings.Builder),
+	}
+}
+
+// Add processes one event
+func (c *Collector) Add(event Event) {
+	if event.Test == "" {
+		return
+	}
+
+	key := event.Package + "\x00" + event.Test
+
+	switch event.Action {
+	case "output":
+		b := c.output[key]
+		if b == nil {
+			b = &strings.Builder{}
+			c.output[key] = b
+		}
+		if b.Len() < maxOutputBytes {
+			b.WriteString(event.Output)
+		}
+	case "pass", "fail", "skip":
+		result := Result{
+			Package: event.Package,
+			Test:    event.Test,
+			Status:  event.Action,
+			Elapsed: event.Elapsed,
+			Time:    event.Time,
+		}
+		if event.Action == "fail" && c.output[key] != nil {
+			result.Output = c.output[key].String()
+		}
+		delete(c.output, key)
+		c.results = append(c.results, result)
+	}
+}
+
+// Results returns the results collected so far
+func (c *Collector) Results() []Result {
+	return c.results
+}
+
+// ParseJSON reads a go test -json stream and returns the final result of each
+// test. Lines that are not JSON events (e.g. build errors) are ignored.
+func ParseJSON(r io.Reader) ([]Result, error) {
+	collector := NewCollector()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue
+		}
+		collector.Add(event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read test output: %w", err)
+	}
+
+	return collector.Results(), nil
+}
@@ -0,0 +1,38 @@
+package testrun
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	input := `{"Action":"run","Package":"example.com/m","Test":"TestA"}
+{"Action":"output","Package":"example.com/m","Test":"TestA","Output":"a_test.go:10: boom\n"}
+{"Action":"fail","Package":"example.com/m","Test":"TestA","Elapsed":0.5}
+# example.com/m [build failed]
+{"Action":"run","Package":"example.com/m","Test":"TestB"}
+{"Action":"output","Package":"example.com/m","Test":"TestB","Output":"ok\n"}
+{"Action":"pass","Package":"example.com/m","Test":"TestB","Elapsed":0.1}
+{"Action":"pass","Package":"example.com/m","Elapsed":0.6}
+`
+	results, err := ParseJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("results count = %d, want %d", len(results), 2)
+	}
+
+	if results[0].Test != "TestA" || results[0].Status != "fail" {
+		t.Fatalf("first result = %+v, want failed TestA", results[0])
+	}
+
+	if results[0].Output != "a_test.go:10: boom\n" {
+		t.Fatalf("output = %q, want failure output", results[0].Output)
+	}
+
+	if results[1].Status != "pass" || results[1].Output != "" {
+		t.Fatalf("second result = %+v, want passed TestB without output", results[1])
+	}
+}
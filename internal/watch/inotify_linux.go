@@ -0,0 +1,224 @@
+//go:build linux
+
+package watch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// inotifyMask selects the changes reported for every watched directory
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DONT_FOLLOW | syscall.IN_ONLYDIR
+
+// inotify watches every directory under the base directory that is not
+// ignored. Only the reading goroutine touches dirs and moves once started.
+type inotify struct {
+	w    *Watcher
+	fd   int
+	file *os.File
+
+	dirs  map[int32]string // watch descriptor -> directory relative to the base directory
+	moves map[uint32]move  // IN_MOVED_FROM by cookie, waiting for its IN_MOVED_TO
+}
+
+// move is the source of a rename
+type move struct {
+	path string
+	dir  bool
+}
+
+// startInotify watches the base directory tree with inotify
+func startInotify(w *Watcher) (backend, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+
+	n := &inotify{
+		w:     w,
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), "inotify"), // non-blocking, so Close ends a pending Read
+		dirs:  make(map[int32]string),
+		moves: make(map[uint32]move),
+	}
+	if err := n.addTree(".", false); err != nil {
+		n.file.Close()
+		return nil, err
+	}
+
+	go n.read()
+	return n, nil
+}
+
+// Close stops watching
+func (n *inotify) Close() error {
+	return n.file.Close()
+}
+
+// addTree watches a directory and the directories below it. With announce,
+// the files found are reported as created, since they may have appeared
+// before the watch was in place.
+func (n *inotify) addTree(rel string, announce bool) error {
+	baseDir := n.w.baseDir
+	ignored := ignore.For(baseDir)
+	return filepath.WalkDir(filepath.Join(baseDir, rel), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		r, err := filepath.Rel(baseDir, p)
+		if err != nil {
+			return nil
+		}
+		r = filepath.ToSlash(r)
+
+		if !d.IsDir() {
+			if announce {
+				n.w.record(files.ChangeEvent{Type: Created, Path: r})
+			}
+			return nil
+		}
+		if r != "." && ignored.Ignored(r, true) {
+			return filepath.SkipDir
+		}
+		if announce && r != rel {
+			n.w.record(files.ChangeEvent{Type: Created, Path: r, Dir: true})
+		}
+
+		wd, err := syscall.InotifyAddWatch(n.fd, p, inotifyMask)
+		if errors.Is(err, syscall.ENOSPC) {
+			return fmt.Errorf("inotify watch limit reached; raise fs.inotify.max_user_watches")
+		}
+		if err != nil {
+			return nil
+		}
+		n.dirs[int32(wd)] = r
+		return nil
+	})
+}
+
+// read decodes inotify events until the watcher is closed
+func (n *inotify) read() {
+	buf := make([]byte, 64<<10)
+	for {
+		size, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		rescan := false
+		for off := 0; off+syscall.SizeofInotifyEvent <= size; {
+			wd := int32(binary.NativeEndian.Uint32(buf[off:]))
+			mask := binary.NativeEndian.Uint32(buf[off+4:])
+			cookie := binary.NativeEndian.Uint32(buf[off+8:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[off+12:]))
+			off += syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[off:off+nameLen]), "\x00")
+			off += nameLen
+
+			if n.event(wd, mask, cookie, name) {
+				rescan = true
+			}
+		}
+
+		// A rename is reported as a pair of events read together; a move out
+		// of the tree has no second half
+		for cookie, from := range n.moves {
+			n.w.record(files.ChangeEvent{Type: Deleted, Path: from.path, Dir: from.dir})
+			if from.dir {
+				n.removeTree(from.path)
+			}
+			delete(n.moves, cookie)
+		}
+
+		// Directories an ignore file no longer hides need watches
+		if rescan {
+			n.addTree(".", false)
+		}
+	}
+}
+
+// event handles one inotify event and reports whether it changed an ignore
+// file
+func (n *inotify) event(wd int32, mask, cookie uint32, name string) bool {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		n.w.overflow()
+		return false
+	}
+	dir, ok := n.dirs[wd]
+	if !ok {
+		return false
+	}
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(n.dirs, wd)
+		return false
+	}
+	if name == "" {
+		return false
+	}
+
+	rel := path.Join(dir, name)
+	isDir := mask&syscall.IN_ISDIR != 0
+	switch {
+	case mask&syscall.IN_CREATE != 0:
+		n.w.record(files.ChangeEvent{Type: Created, Path: rel, Dir: isDir})
+		if isDir {
+			n.addTree(rel, true)
+		}
+	case mask&syscall.IN_MODIFY != 0:
+		n.w.record(files.ChangeEvent{Type: Modified, Path: rel})
+	case mask&syscall.IN_DELETE != 0:
+		n.w.record(files.ChangeEvent{Type: Deleted, Path: rel, Dir: isDir})
+	case mask&syscall.IN_MOVED_FROM != 0:
+		n.moves[cookie] = move{path: rel, dir: isDir}
+	case mask&syscall.IN_MOVED_TO != 0:
+		from, ok := n.moves[cookie]
+		if !ok {
+			n.w.record(files.ChangeEvent{Type: Created, Path: rel, Dir: isDir})
+			if isDir {
+				n.addTree(rel, true)
+			}
+			break
+		}
+		delete(n.moves, cookie)
+		n.w.record(files.ChangeEvent{Type: Renamed, Path: rel, OldPath: from.path, Dir: isDir})
+		if isDir {
+			n.renameTree(from.path, rel)
+		}
+	}
+
+	return name == ".gitignore" || rel == ignore.ConfigFile
+}
+
+// renameTree updates the paths of the watched directories below a renamed
+// directory
+func (n *inotify) renameTree(oldRel, newRel string) {
+	for wd, dir := range n.dirs {
+		if dir == oldRel {
+			n.dirs[wd] = newRel
+		} else if rest, ok := strings.CutPrefix(dir, oldRel+"/"); ok {
+			n.dirs[wd] = newRel + "/" + rest
+		}
+	}
+}
+
+// removeTree stops watching a directory moved out of the tree and the
+// directories below it
+func (n *inotify) removeTree(rel string) {
+	for wd, dir := range n.dirs {
+		if dir == rel || strings.HasPrefix(dir, rel+"/") {
+			syscall.InotifyRmWatch(n.fd, uint32(wd))
+			delete(n.dirs, wd)
+		}
+	}
+}
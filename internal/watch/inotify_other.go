@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// startInotify is only available on Linux; elsewhere the watcher polls
+func startInotify(w *Watcher) (backend, error) {
+	return nil, errors.New("inotify is only available on Linux")
+}
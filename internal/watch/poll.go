@@ -0,0 +1,171 @@
+package watch
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+)
+
+// stat is what the poller remembers of a path
+type stat struct {
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+// poller finds changes by scanning the file tree at an interval
+type poller struct {
+	w        *Watcher
+	interval time.Duration
+	done     chan struct{}
+	paths    map[string]stat // by slash-separated path relative to the base directory
+}
+
+// usePolling watches the base directory by scanning it at an interval
+func (w *Watcher) usePolling(interval time.Duration) {
+	p := &poller{w: w, interval: interval, done: make(chan struct{})}
+	p.paths = p.scan()
+	w.mode, w.backend = "poll", p
+	go p.run()
+}
+
+// Close stops the poller
+func (p *poller) Close() error {
+	close(p.done)
+	return nil
+}
+
+// run scans the file tree until the poller is closed
+func (p *poller) run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		next := p.scan()
+		for _, event := range diff(p.paths, next) {
+			p.w.record(event)
+		}
+		p.paths = next
+	}
+}
+
+// scan returns the paths under the base directory that are not ignored
+func (p *poller) scan() map[string]stat {
+	baseDir := p.w.baseDir
+	ignored := ignore.For(baseDir)
+	paths := make(map[string]stat)
+	filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == baseDir {
+			return nil
+		}
+		if ignored.IgnoredPath(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return nil
+		}
+		paths[filepath.ToSlash(rel)] = stat{size: info.Size(), modTime: info.ModTime(), dir: d.IsDir()}
+		return nil
+	})
+	return paths
+}
+
+// diff returns the changes between two scans. A path that disappeared while
+// exactly one path with the same size, modification time and kind appeared
+// was renamed; the contents of a renamed directory are not reported again.
+func diff(prev, next map[string]stat) []files.ChangeEvent {
+	var events, created, deleted []files.ChangeEvent
+	for rel, old := range prev {
+		cur, ok := next[rel]
+		switch {
+		case !ok:
+			deleted = append(deleted, files.ChangeEvent{Type: Deleted, Path: rel, Dir: old.dir})
+		case cur.dir != old.dir:
+			events = append(events, files.ChangeEvent{Type: Modified, Path: rel, Dir: cur.dir})
+		case !cur.dir && (cur.size != old.size || !cur.modTime.Equal(old.modTime)):
+			events = append(events, files.ChangeEvent{Type: Modified, Path: rel})
+		}
+	}
+	for rel, cur := range next {
+		if _, ok := prev[rel]; !ok {
+			created = append(created, files.ChangeEvent{Type: Created, Path: rel, Dir: cur.dir})
+		}
+	}
+
+	// Pair deletions and creations of identical paths into renames
+	bySignature := func(events []files.ChangeEvent, scan map[string]stat) map[stat][]int {
+		m := make(map[stat][]int)
+		for i, event := range events {
+			m[scan[event.Path]] = append(m[scan[event.Path]], i)
+		}
+		return m
+	}
+	createdBy, deletedBy := bySignature(created, next), bySignature(deleted, prev)
+	var renamedDirs [][2]string // old and new path
+	for sig, from := range deletedBy {
+		to := createdBy[sig]
+		if len(from) != 1 || len(to) != 1 {
+			continue
+		}
+		oldPath, newPath := deleted[from[0]].Path, created[to[0]].Path
+		events = append(events, files.ChangeEvent{Type: Renamed, Path: newPath, OldPath: oldPath, Dir: sig.dir})
+		deleted[from[0]].Type, created[to[0]].Type = "", ""
+		if sig.dir {
+			renamedDirs = append(renamedDirs, [2]string{oldPath, newPath})
+		}
+	}
+	events = append(events, deleted...)
+	events = append(events, created...)
+
+	var out []files.ChangeEvent
+	for _, event := range events {
+		if event.Type != "" && !insideRenamedDir(event, renamedDirs) {
+			out = append(out, event)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// insideRenamedDir reports whether an event is about the contents of a
+// directory that was renamed, which the rename of the directory covers
+func insideRenamedDir(event files.ChangeEvent, renamedDirs [][2]string) bool {
+	for _, dirs := range renamedDirs {
+		oldDir, newDir := dirs[0]+"/", dirs[1]+"/"
+		switch event.Type {
+		case Deleted:
+			if strings.HasPrefix(event.Path, oldDir) {
+				return true
+			}
+		case Created:
+			if strings.HasPrefix(event.Path, newDir) {
+				return true
+			}
+		case Renamed:
+			if strings.HasPrefix(event.OldPath, oldDir) && strings.HasPrefix(event.Path, newDir) {
+				return true
+			}
+		}
+	}
+	return false
+}
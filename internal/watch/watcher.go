@@ -0,0 +1,296 @@
+package watch
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/ignore"
+	"codebase-view-mcp/internal/metadata"
+)
+
+// Event types
+const (
+	Created  = "created"
+	Modified = "modified"
+	Deleted  = "deleted"
+	Renamed  = "renamed"
+	Metadata = "metadata"
+)
+
+// Timing of the watcher
+const (
+	// debounceDelay is how long the watcher waits for more changes before it
+	// publishes what it collected
+	debounceDelay = 100 * time.Millisecond
+	// maxDelay caps the wait while changes keep coming
+	maxDelay = time.Second
+	// pollInterval is how often the file tree is scanned without inotify
+	pollInterval = time.Second
+	// metadataInterval is how often the metadata store is checked for changes
+	metadataInterval = 250 * time.Millisecond
+)
+
+// subscriberBuffer is the number of events a subscriber may fall behind
+// before it is dropped
+const subscriberBuffer = 256
+
+// backend reports raw changes to the watcher until it is closed
+type backend interface {
+	Close() error
+}
+
+// Watcher publishes the changes to the files under a base directory and to
+// their stored metadata. Changes are collected for a short while so that a
+// burst of writes to one file is published once; paths hidden by the ignore
+// rules are left out.
+type Watcher struct {
+	baseDir string
+	store   *metadata.Store // may be nil
+	mode    string
+	backend backend
+	done    chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	pending []files.ChangeEvent
+	byKey   map[string]int // index in pending of the last event of a path
+	first   time.Time      // when the oldest pending event was recorded
+	timer   *time.Timer
+	hooks   []func([]files.ChangeEvent)
+
+	subMu       sync.Mutex
+	subscribers map[chan files.ChangeEvent]struct{}
+}
+
+// New creates a watcher of baseDir that also reports the files whose
+// metadata changed in store, which may be nil. It does nothing until Start.
+func New(baseDir string, store *metadata.Store) *Watcher {
+	return &Watcher{
+		baseDir:     baseDir,
+		store:       store,
+		done:        make(chan struct{}),
+		byKey:       make(map[string]int),
+		subscribers: make(map[chan files.ChangeEvent]struct{}),
+	}
+}
+
+// Start watches the base directory with inotify, or by polling where
+// inotify is not available or runs out of watches
+func (w *Watcher) Start() {
+	b, err := startInotify(w)
+	if err != nil {
+		log.Printf("Watching %s by polling: %v", w.baseDir, err)
+		w.usePolling(pollInterval)
+	} else {
+		w.mode, w.backend = "inotify", b
+	}
+
+	if w.store != nil {
+		go w.watchMetadata(w.store.Version())
+	}
+}
+
+// Mode returns how the base directory is watched: inotify or poll
+func (w *Watcher) Mode() string {
+	return w.mode
+}
+
+// Close stops the watcher and ends every subscription
+func (w *Watcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+		if w.backend != nil {
+			w.backend.Close()
+		}
+
+		w.subMu.Lock()
+		defer w.subMu.Unlock()
+		for ch := range w.subscribers {
+			close(ch)
+			delete(w.subscribers, ch)
+		}
+	})
+}
+
+// OnChange registers a function called with every batch of published
+// events, before they are sent to subscribers
+func (w *Watcher) OnChange(fn func([]files.ChangeEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, fn)
+}
+
+// Subscribe returns a channel of published events and a function ending the
+// subscription. The channel is closed when the subscriber falls too far
+// behind or the watcher is closed.
+func (w *Watcher) Subscribe() (<-chan files.ChangeEvent, func()) {
+	ch := make(chan files.ChangeEvent, subscriberBuffer)
+
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	select {
+	case <-w.done:
+		close(ch)
+		return ch, func() {}
+	default:
+	}
+	w.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		w.subMu.Lock()
+		defer w.subMu.Unlock()
+		if _, ok := w.subscribers[ch]; ok {
+			close(ch)
+			delete(w.subscribers, ch)
+		}
+	}
+}
+
+// record queues a change for publishing unless the ignore rules hide it
+func (w *Watcher) record(event files.ChangeEvent) {
+	ignored := ignore.For(w.baseDir)
+	if event.Type == Renamed {
+		oldIgnored, newIgnored := ignored.Ignored(event.OldPath, event.Dir), ignored.Ignored(event.Path, event.Dir)
+		switch {
+		case oldIgnored && newIgnored:
+			return
+		case oldIgnored:
+			// e.g. an editor saving through a hidden temporary file
+			event = files.ChangeEvent{Type: Created, Path: event.Path, Dir: event.Dir}
+		case newIgnored:
+			event = files.ChangeEvent{Type: Deleted, Path: event.OldPath, Dir: event.Dir}
+		}
+	} else if ignored.Ignored(event.Path, event.Dir) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.merge(event)
+	w.schedule()
+}
+
+// overflow reports that changes were lost, as a modification of the base
+// directory; subscribers should reload everything
+func (w *Watcher) overflow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.merge(files.ChangeEvent{Type: Modified, Path: ".", Dir: true})
+	w.schedule()
+}
+
+// merge folds an event into the pending events of its path: a file created
+// and then modified is still created, created and then deleted is dropped,
+// and deleted and then created again is modified. The caller holds w.mu.
+func (w *Watcher) merge(event files.ChangeEvent) {
+	key := "file:" + event.Path
+	if event.Type == Metadata {
+		key = "metadata:" + event.Path
+	}
+
+	if event.Type == Renamed {
+		// A rename ends the pending history of both paths
+		delete(w.byKey, "file:"+event.OldPath)
+		delete(w.byKey, key)
+		w.pending = append(w.pending, event)
+		return
+	}
+
+	if i, ok := w.byKey[key]; ok {
+		prev := &w.pending[i]
+		switch {
+		case prev.Type == Created && event.Type == Deleted:
+			prev.Type = ""
+			delete(w.byKey, key)
+		case prev.Type == Created:
+		case prev.Type == Deleted && event.Type == Created:
+			prev.Type, prev.Dir = Modified, event.Dir
+		default:
+			prev.Type = event.Type
+		}
+		return
+	}
+
+	w.byKey[key] = len(w.pending)
+	w.pending = append(w.pending, event)
+}
+
+// schedule arranges for the pending events to be published once changes
+// pause, or at the latest maxDelay after the first of them. The caller holds
+// w.mu.
+func (w *Watcher) schedule() {
+	now := time.Now()
+	if w.timer == nil {
+		w.first = now
+		w.timer = time.AfterFunc(debounceDelay, w.flush)
+		return
+	}
+	if now.Sub(w.first) < maxDelay {
+		w.timer.Reset(debounceDelay)
+	}
+}
+
+// flush publishes the pending events
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	var events []files.ChangeEvent
+	for _, event := range w.pending {
+		if event.Type != "" {
+			events = append(events, event)
+		}
+	}
+	w.pending = nil
+	w.byKey = make(map[string]int)
+	w.timer = nil
+	hooks := w.hooks
+	w.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+	for _, hook := range hooks {
+		hook(events)
+	}
+
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	for ch := range w.subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+				continue
+			default:
+			}
+			close(ch)
+			delete(w.subscribers, ch)
+			break
+		}
+	}
+}
+
+// watchMetadata publishes a metadata event for every file whose stored
+// metadata changed after version
+func (w *Watcher) watchMetadata(version uint64) {
+	ticker := time.NewTicker(metadataInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := w.store.Version()
+		if current == version {
+			continue
+		}
+		for _, path := range w.store.ModifiedSince(version) {
+			w.record(files.ChangeEvent{Type: Metadata, Path: filepath.ToSlash(path)})
+		}
+		version = current
+	}
+}
@@ -0,0 +1,215 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"codebase-view-mcp/internal/files"
+	"codebase-view-mcp/internal/metadata"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []files.ChangeEvent
+		want []files.ChangeEvent
+	}{
+		{
+			name: "created then modified",
+			in:   []files.ChangeEvent{{Type: Created, Path: "a.go"}, {Type: Modified, Path: "a.go"}},
+			want: []files.ChangeEvent{{Type: Created, Path: "a.go"}},
+		},
+		{
+			name: "created then deleted",
+			in:   []files.ChangeEvent{{Type: Created, Path: "a.go"}, {Type: Deleted, Path: "a.go"}},
+			want: nil,
+		},
+		{
+			name: "deleted then created",
+			in:   []files.ChangeEvent{{Type: Deleted, Path: "a.go"}, {Type: Created, Path: "a.go"}},
+			want: []files.ChangeEvent{{Type: Modified, Path: "a.go"}},
+		},
+		{
+			name: "modified then deleted",
+			in:   []files.ChangeEvent{{Type: Modified, Path: "a.go"}, {Type: Modified, Path: "a.go"}, {Type: Deleted, Path: "a.go"}},
+			want: []files.ChangeEvent{{Type: Deleted, Path: "a.go"}},
+		},
+		{
+			name: "metadata is kept apart from file changes",
+			in:   []files.ChangeEvent{{Type: Modified, Path: "a.go"}, {Type: Metadata, Path: "a.go"}, {Type: Metadata, Path: "a.go"}},
+			want: []files.ChangeEvent{{Type: Modified, Path: "a.go"}, {Type: Metadata, Path: "a.go"}},
+		},
+		{
+			name: "rename ends the history of a path",
+			in: []files.ChangeEvent{
+				{Type: Modified, Path: "a.go"},
+				{Type: Renamed, Path: "b.go", OldPath: "a.go"},
+				{Type: Created, Path: "a.go"},
+			},
+			want: []files.ChangeEvent{
+				{Type: Modified, Path: "a.go"},
+				{Type: Renamed, Path: "b.go", OldPath: "a.go"},
+				{Type: Created, Path: "a.go"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(t.TempDir(), nil)
+			for _, event := range tt.in {
+				w.merge(event)
+			}
+
+			var got []files.ChangeEvent
+			for _, event := range w.pending {
+				if event.Type != "" {
+					got = append(got, event)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	then := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := then.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		prev map[string]stat
+		next map[string]stat
+		want []files.ChangeEvent
+	}{
+		{
+			name: "created, modified and deleted",
+			prev: map[string]stat{"a.go": {size: 1, modTime: then}, "b.go": {size: 2, modTime: then}},
+			next: map[string]stat{"a.go": {size: 3, modTime: later}, "c.go": {size: 4, modTime: later}},
+			want: []files.ChangeEvent{
+				{Type: Modified, Path: "a.go"},
+				{Type: Deleted, Path: "b.go"},
+				{Type: Created, Path: "c.go"},
+			},
+		},
+		{
+			name: "renamed file",
+			prev: map[string]stat{"a.go": {size: 1, modTime: then}},
+			next: map[string]stat{"b.go": {size: 1, modTime: then}},
+			want: []files.ChangeEvent{{Type: Renamed, Path: "b.go", OldPath: "a.go"}},
+		},
+		{
+			name: "identical files are not paired",
+			prev: map[string]stat{"a.go": {modTime: then}, "b.go": {modTime: then}},
+			next: map[string]stat{"c.go": {modTime: then}, "d.go": {modTime: then}},
+			want: []files.ChangeEvent{
+				{Type: Deleted, Path: "a.go"},
+				{Type: Deleted, Path: "b.go"},
+				{Type: Created, Path: "c.go"},
+				{Type: Created, Path: "d.go"},
+			},
+		},
+		{
+			name: "renamed directory covers its contents",
+			prev: map[string]stat{"pkg": {modTime: then, dir: true}, "pkg/a.go": {size: 1, modTime: then}, "pkg/b.go": {size: 2, modTime: then}},
+			next: map[string]stat{"lib": {modTime: then, dir: true}, "lib/a.go": {size: 1, modTime: then}, "lib/b.go": {size: 2, modTime: then}},
+			want: []files.ChangeEvent{{Type: Renamed, Path: "lib", OldPath: "pkg", Dir: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diff(tt.prev, tt.next); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	backends := []struct {
+		name  string
+		start func(w *Watcher)
+	}{
+		{name: "native", start: func(w *Watcher) { w.Start() }},
+		{name: "poll", start: func(w *Watcher) { w.usePolling(20 * time.Millisecond) }},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			write(t, filepath.Join(baseDir, "old.go"), "package old")
+
+			w := New(baseDir, nil)
+			backend.start(w)
+			defer w.Close()
+			events, unsubscribe := w.Subscribe()
+			defer unsubscribe()
+
+			write(t, filepath.Join(baseDir, "new.go"), "package new")
+			expect(t, events, files.ChangeEvent{Type: Created, Path: "new.go"})
+
+			write(t, filepath.Join(baseDir, ".hidden"), "secret")
+			if err := os.Rename(filepath.Join(baseDir, "old.go"), filepath.Join(baseDir, "renamed.go")); err != nil {
+				t.Fatalf("rename: %v", err)
+			}
+			expect(t, events, files.ChangeEvent{Type: Renamed, Path: "renamed.go", OldPath: "old.go"})
+
+			if err := os.Mkdir(filepath.Join(baseDir, "pkg"), 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			expect(t, events, files.ChangeEvent{Type: Created, Path: "pkg", Dir: true})
+			write(t, filepath.Join(baseDir, "pkg", "a.go"), "package pkg")
+			expect(t, events, files.ChangeEvent{Type: Created, Path: "pkg/a.go"})
+
+			if err := os.Remove(filepath.Join(baseDir, "new.go")); err != nil {
+				t.Fatalf("remove: %v", err)
+			}
+			expect(t, events, files.ChangeEvent{Type: Deleted, Path: "new.go"})
+		})
+	}
+}
+
+func TestWatcherMetadata(t *testing.T) {
+	store := metadata.NewStore("")
+	w := New(t.TempDir(), store)
+	w.usePolling(time.Hour)
+	go w.watchMetadata(store.Version())
+	defer w.Close()
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	if err := store.SetTestMetadata("pkg/a.go", nil); err != nil {
+		t.Fatalf("SetTestMetadata() error = %v", err)
+	}
+	expect(t, events, files.ChangeEvent{Type: Metadata, Path: "pkg/a.go"})
+}
+
+// write creates or replaces a file
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// expect waits for the next event and compares it with want
+func expect(t *testing.T, events <-chan files.ChangeEvent, want files.ChangeEvent) {
+	t.Helper()
+	select {
+	case got, ok := <-events:
+		if !ok {
+			t.Fatalf("events closed, want %v", want)
+		}
+		if got != want {
+			t.Fatalf("event = %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event, want %v", want)
+	}
+}